@@ -0,0 +1,207 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zoomoid/assignments/v1/cmd/options"
+	"github.com/zoomoid/assignments/v1/internal/adopt"
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+)
+
+var (
+	adoptLongDescription = dedent.Dedent(`
+		The command brings an existing course directory under assignmentctl,
+		e.g., one with directories like Blatt1/ and sheet_02/ and loose PDFs.
+
+		It looks at all top-level directories whose name contains a single
+		number, optionally followed by a single letter, e.g., Uebung-5a/, or
+		that already follow the naming scheme. Such a directory is an
+		assignment if it contains a main source file, i.e., one with
+		\documentclass and \begin{document}. Directories without numbers,
+		e.g., code/, are left alone. The same goes for top-level PDFs and for
+		the PDFs built next to the main source files.
+
+		The command then proposes to
+
+		  - rename the directories according to the naming scheme,
+		  - rename their main source files to assignment.tex, and
+		  - move the PDFs to ./dist/,
+
+		and asks for confirmation, unless --yes is passed. Afterwards, it writes
+		.assignments.yaml, or updates the existing one, with the highest
+		numbered assignment as .status.assignment, such that "assignmentctl
+		generate" continues after it. The course, group, and members are taken
+		from \course{}, \group{}, and \member[ID]{Name}, or \author{A \and B},
+		in the preambles of the newest assignments, unless configured already.
+
+		If .assignments.yaml exists, its naming scheme is used. Otherwise, the
+		directories are named assignment-01, assignment-02, ... Configure
+		.spec.naming in a fresh .assignments.yaml first to use another scheme.
+	`)
+)
+
+type adoptData struct {
+	yes bool
+}
+
+func newAdoptData() *adoptData {
+	return &adoptData{
+		yes: false,
+	}
+}
+
+func NewAdoptCommand(ctx *context.AppContext, data *adoptData) *cobra.Command {
+	if data == nil {
+		data = newAdoptData()
+	}
+
+	cmd := &cobra.Command{
+		Use:   "adopt",
+		Short: "Rename an existing course directory's assignments to the naming scheme and configure it",
+		Long:  adoptLongDescription,
+		Args:  cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if _, err := config.Find(ctx.Cwd); err != nil {
+				// adopting a directory without configuration creates a fresh one
+				ctx.Configuration = config.Minimal()
+				return
+			}
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plan, err := adopt.Scan(ctx.Root, ctx.Naming())
+			if err != nil {
+				return err
+			}
+			for _, s := range plan.Skipped {
+				log.Warn().Msgf("Skipping %s, %s", s.Path, s.Reason)
+			}
+			if len(plan.Assignments) == 0 {
+				log.Info().Msg("Found no assignments to adopt")
+				return nil
+			}
+			if err := printAdoptPlan(cmd.OutOrStdout(), ctx, plan); err != nil {
+				return err
+			}
+			question := "Write .assignments.yaml?"
+			if n := len(plan.Renames()); n > 0 {
+				question = fmt.Sprintf("Rename %d files and directories and write .assignments.yaml?", n)
+			}
+			if !data.yes && !promptConfirmation(question) {
+				log.Info().Msg("Nothing was changed")
+				return nil
+			}
+
+			renames, err := plan.Apply(ctx.Root)
+			for _, r := range renames {
+				log.Info().Msgf("Renamed %s to %s", r.From, r.To)
+			}
+			if err != nil {
+				return err
+			}
+			plan.Configure(ctx.Configuration)
+			if err := ctx.Write(); err != nil {
+				return err
+			}
+			if current := ctx.Configuration.Status.Assignment; !current.IsZero() {
+				log.Info().Msgf("Assignment %s is the current assignment now", ctx.Naming().ID(current))
+			}
+			log.Info().Msgf("Adopted %d assignments", len(plan.Assignments))
+			return nil
+		},
+	}
+
+	addAdoptFlags(cmd.PersistentFlags(), data)
+	addAdoptFlagsCompletion(cmd)
+
+	return cmd
+}
+
+func addAdoptFlags(flags *pflag.FlagSet, data *adoptData) {
+	flags.BoolVarP(&data.yes, options.Yes, options.YesShort, false, "Rename without asking for confirmation")
+}
+
+func addAdoptFlagsCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc(options.Yes, cobra.NoFileCompletions)
+}
+
+// printAdoptPlan prints the proposed renames of each assignment and the course's
+// details found in the preambles
+func printAdoptPlan(out io.Writer, ctx *context.AppContext, plan *adopt.Plan) error {
+	w := tabwriter.NewWriter(out, 0, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "ASSIGNMENT\tFROM\tTO")
+	for _, a := range plan.Assignments {
+		id := ctx.Naming().ID(a.ID)
+		if len(a.Renames) == 0 {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", id, a.Directory, "(unchanged)")
+		}
+		for _, r := range a.Renames {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", id, r.From, r.To)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	members := []string{}
+	for _, m := range plan.Members {
+		if m.ID != "" {
+			members = append(members, fmt.Sprintf("%s (%s)", m.Name, m.ID))
+		} else {
+			members = append(members, m.Name)
+		}
+	}
+	fmt.Fprintln(out)
+	for _, field := range [][2]string{
+		{"Course", plan.Course},
+		{"Group", plan.Group},
+		{"Members", strings.Join(members, ", ")},
+	} {
+		if field[1] != "" {
+			fmt.Fprintf(out, "%s: %s\n", field[0], field[1])
+		}
+	}
+	return nil
+}
+
+// promptConfirmation asks a yes/no question, defaulting to no
+func promptConfirmation(question string) bool {
+	fmt.Printf("❓ %s [y/N]: ", question)
+	reader := bufio.NewReader(os.Stdin)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+	input = strings.ToLower(strings.TrimSpace(input))
+	return input == "y" || input == "yes"
+}
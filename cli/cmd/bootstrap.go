@@ -324,7 +324,7 @@ func augmentDefaults(cfg *config.Configuration) *config.Configuration {
 			},
 		},
 		Status: &config.ConfigurationStatus{
-			Assignment: 1,
+			Assignment: config.NumberedID(1),
 		},
 	}
 }
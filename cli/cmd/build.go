@@ -21,8 +21,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
-	"strings"
+	"sort"
 	"time"
 
 	"github.com/lithammer/dedent"
@@ -30,9 +29,9 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/zoomoid/assignments/v1/cmd/options"
+	"github.com/zoomoid/assignments/v1/internal/config"
 	"github.com/zoomoid/assignments/v1/internal/context"
 	"github.com/zoomoid/assignments/v1/internal/runner"
-	"github.com/zoomoid/assignments/v1/internal/util"
 )
 
 var (
@@ -64,7 +63,7 @@ var (
 
 		Note that .spec.build.cleanup.command and .spec.build.cleanup.glob
 		are mutually exclusive. Presence of both will cause the CLI to throw
-		an error.
+		an error, which "assignmentctl config validate" reports as well.
 		
 		If you use the build command in a setup different to one-off runs, 
 		for which you might want to keep the files for later runs again to save 
@@ -73,6 +72,13 @@ var (
 		You can suppress the output of spawned shell commands by passing 
 		--quiet, or -q.
 
+		An assignment.yaml in the assignment's directory can override any of
+		the above for this assignment only, see the README.
+
+		If the assignment's due date recorded in .status.assignments is
+		within .spec.due.warn (default 48h) or has already passed, the command
+		warns about the deadline, see "assignmentctl due --help".
+
 		To adjust the build recipe for compilation, add a recipe to your
 		configuration file at .spec.build.recipe. Recipes are order-preservent
 		lists of commands with arguments in YAML format. A recipe consists
@@ -99,23 +105,38 @@ func newBuildData() *buildData {
 	}
 }
 
-func getAssignmentsFromRoot(toComplete string, root string) []string {
-	globPattern := filepath.Join(root, "assignment-*")
-	matches, err := filepath.Glob(globPattern)
+// getAssignmentsFromRoot completes the numbers of all assignment directories in
+// the repository's root. Completion runs without PreRun, so the configuration is
+// read here if possible to determine the naming scheme
+func getAssignmentsFromRoot(ctx *context.AppContext, toComplete string) []string {
+	if ctx.Configuration == nil {
+		if err := ctx.Read(); err != nil {
+			log.Debug().Err(err).Msg("Using default naming scheme for completion")
+		}
+	}
+	n := ctx.Naming()
+	matches, err := filepath.Glob(filepath.Join(ctx.Root, n.Glob()))
 
 	if err != nil {
 		return nil
 	}
 
-	ret := make([]string, len(matches))
+	ids := make([]config.AssignmentID, 0, len(matches))
 	for _, m := range matches {
-		num := strings.ReplaceAll(filepath.Base(m), "assignment-", "")
-
-		n, err := strconv.Atoi(num)
+		id, err := n.Parse(m)
 		if err != nil {
 			continue
 		}
-		ret = append(ret, fmt.Sprintf("%d\tAssignment %s", n, num))
+		ids = append(ids, id)
+	}
+	// the glob's matches are ordered lexically, i.e., 10 before 9 and bonus before 10
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].Less(ids[j])
+	})
+
+	ret := make([]string, 0, len(ids))
+	for _, id := range ids {
+		ret = append(ret, fmt.Sprintf("%s\tAssignment %s", id, n.ID(id)))
 	}
 
 	return ret
@@ -141,9 +162,9 @@ func NewBuildCommand(ctx *context.AppContext, data *buildData) *cobra.Command {
 			if len(args) != 0 {
 				return nil, cobra.ShellCompDirectiveNoFileComp
 			}
-			comps := getAssignmentsFromRoot(toComplete, ctx.Root)
+			comps := getAssignmentsFromRoot(ctx, toComplete)
 			if len(comps) == 0 {
-				return []string{"assignments-*"}, cobra.ShellCompDirectiveFilterDirs
+				return []string{ctx.Naming().Glob()}, cobra.ShellCompDirectiveFilterDirs
 			}
 			return comps, cobra.ShellCompDirectiveNoFileComp
 		},
@@ -155,13 +176,11 @@ func NewBuildCommand(ctx *context.AppContext, data *buildData) *cobra.Command {
 			assignmentNo := ctx.Configuration.Status.Assignment
 
 			if len(args) != 0 {
-				assignmentArg := args[0]
-				// attempt to remove prefix from arguments. This is relevant when using the autocompletion
-				assignmentArg = strings.TrimPrefix(assignmentArg, "assignment-")
-				// attempt parsing numerically
-				i, err := strconv.Atoi(assignmentArg)
+				// the argument is either the assignment's number, or its directory's
+				// name. The latter is relevant when using the autocompletion
+				i, err := ctx.Naming().Argument(args[0])
 				if err == nil {
-					assignmentNo = uint32(i)
+					assignmentNo = i
 				}
 			}
 
@@ -173,18 +192,21 @@ func NewBuildCommand(ctx *context.AppContext, data *buildData) *cobra.Command {
 				return errors.New("cannot use -f flag with specific assignment")
 			}
 
-			if ctx.Configuration.Spec.BuildOptions.Cleanup != nil &&
-				ctx.Configuration.Spec.BuildOptions.Cleanup.Command != nil &&
-				ctx.Configuration.Spec.BuildOptions.Cleanup.Glob != nil {
-				return errors.New("found ambiguous cleanup mode, only use either glob or command")
+			if buildOptions := ctx.Configuration.Spec.BuildOptions; buildOptions != nil {
+				if err := buildOptions.Cleanup.Validate(); err != nil {
+					return err
+				}
 			}
 
 			if data.all {
-				directories, err := filepath.Glob(filepath.Join(ctx.Root, "assignment-*"))
+				directories, err := filepath.Glob(filepath.Join(ctx.Root, ctx.Naming().Glob()))
 				if err != nil {
 					return fmt.Errorf("failed to glob directories in %s, %v", ctx.Root, err)
 				}
 				for _, dir := range directories {
+					if !ctx.Naming().Match(dir) {
+						continue
+					}
 					filename := "assignment.tex"
 					runs = append(runs, runner.RunnerOptions{
 						TargetDirectory:   filepath.Base(dir),
@@ -194,7 +216,7 @@ func NewBuildCommand(ctx *context.AppContext, data *buildData) *cobra.Command {
 					})
 				}
 			} else {
-				targetDirectory := fmt.Sprintf("assignment-%s", util.AddLeadingZero(assignmentNo))
+				targetDirectory := ctx.Naming().Directory(assignmentNo)
 				filename := "assignment.tex"
 				var err error
 				if data.file != "" {
@@ -212,28 +234,13 @@ func NewBuildCommand(ctx *context.AppContext, data *buildData) *cobra.Command {
 				}}
 			}
 
+			warnOnClassVersionDrift(ctx)
+
 			startTime := time.Now()
 			for _, run := range runs {
-				runner, err := runner.New(ctx, &run)
-				if err != nil {
-					log.Error().Msgf("failed to initialize runner for %s", run.Filename)
+				if err := buildAssignment(ctx, &run, data.keep); err != nil {
 					return err
 				}
-				err = runner.Build().Run()
-				if err != nil {
-					log.Error().Err(err).Msgf("run failed for %s", run.Filename)
-					log.Warn().Msgf("Leaving working directory %s dirty, might require manual cleanup", run.TargetDirectory)
-					return err
-				}
-
-				if !data.keep {
-					err = runner.Clean().Run()
-					if err != nil {
-						log.Error().Err(err).Msgf("failed to clean up for %s", run.Filename)
-						log.Warn().Msgf("Leaving working directory %s dirty, might require manual cleanup", run.TargetDirectory)
-						return err
-					}
-				}
 			}
 			log.Debug().
 				Dur("duration", time.Since(startTime)).
@@ -249,6 +256,43 @@ func NewBuildCommand(ctx *context.AppContext, data *buildData) *cobra.Command {
 	return buildCmd
 }
 
+// buildAssignment compiles a single assignment, exports its PDF to ./dist/, and
+// records the built state. Warns if the assignment's deadline is close. Unless
+// keep is set, intermediate files are cleaned up afterwards
+func buildAssignment(ctx *context.AppContext, run *runner.RunnerOptions, keep bool) error {
+	id, idErr := ctx.Naming().ParseID(run.TargetDirectory)
+	if idErr == nil {
+		warnOnDeadline(ctx, id)
+	}
+
+	r, err := runner.New(ctx, run)
+	if err != nil {
+		log.Error().Msgf("failed to initialize runner for %s", run.Filename)
+		return err
+	}
+	builder := r.Build()
+	err = builder.Run()
+	if err != nil {
+		log.Error().Err(err).Msgf("run failed for %s", run.Filename)
+		log.Warn().Msgf("Leaving working directory %s dirty, might require manual cleanup", run.TargetDirectory)
+		return err
+	}
+
+	if idErr == nil {
+		recordTransition(ctx, id, config.StateBuilt, builder.Artifact())
+	}
+
+	if !keep {
+		err = r.Clean().Run()
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to clean up for %s", run.Filename)
+			log.Warn().Msgf("Leaving working directory %s dirty, might require manual cleanup", run.TargetDirectory)
+			return err
+		}
+	}
+	return nil
+}
+
 // targetDirectoryFromFlag uses the --file flag to determine the targetDirectory and filename to build.
 // It returns a triplet containing (targetDirectory, filename, error), where error is not nil iff and only if
 // an error occured during file system interaction, i.e. expanding the path to absolute, and probing the path.
@@ -282,7 +326,7 @@ func targetDirectoryFromFlag(file string) (string, string, error) {
 
 func addBuildFlags(flags *pflag.FlagSet, data *buildData) {
 	flags.BoolVar(&data.force, options.Force, false, "Override any existing assignments with the same name")
-	flags.BoolVarP(&data.all, options.All, options.AllShort, false, "Build all assignments in assignment-*/")
+	flags.BoolVarP(&data.all, options.All, options.AllShort, false, "Build all assignment directories")
 	flags.BoolVar(&data.keep, options.Keep, false, "Skip latexmk -C cleaning up all files in the source directory")
 	flags.BoolVar(&data.quiet, options.Quiet, false, "Suppress output from subprocesses")
 	flags.StringVarP(&data.file, options.File, options.FileShort, "", "Specify a file to build, will override any derived behaviour from the repository's configmap")
@@ -20,7 +20,6 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
-	"strconv"
 	"strings"
 
 	"github.com/lithammer/dedent"
@@ -29,8 +28,9 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/zoomoid/assignments/v1/cmd/options"
 	"github.com/zoomoid/assignments/v1/internal/bundle"
+	"github.com/zoomoid/assignments/v1/internal/config"
 	"github.com/zoomoid/assignments/v1/internal/context"
-	"github.com/zoomoid/assignments/v1/internal/util"
+	"github.com/zoomoid/assignments/v1/internal/deadline"
 )
 
 var (
@@ -55,13 +55,29 @@ var (
 		don't get too crazy. The map in .spec.bundle.data is passed down to
 		the template's execution for data binding. 
 
-		The default archive template is "assignment-{{._id}}.{{._format}}". Note 
+		The default archive template is "{{._name}}.{{._format}}". Note 
 		the _id field: this is internally augmented from the command's arguments
 		or the configuration's status field (or, in case of usage of --all, all
-		available assignments in the repository). "format" is derived from the 
+		available assignments in the repository). _name is the assignment's name
+		from .spec.naming, e.g., assignment-03. "format" is derived from the 
 		selected backend's common file extension, but respects overrides from
 		the map at .spec.bundle.data, so you can also pick your own file extension
 		without overriding the entire template.
+
+		The template can also access the assignment's group members as
+		_members, limited to those taking part in the assignment, see the
+		README, e.g., 
+		"{{ range $m := ._members }}{{ $m.ID }}-{{ end }}{{._id}}.{{._format}}".
+
+		An assignment.yaml in the assignment's directory can override the
+		bundle options for this assignment only, e.g., to include additional
+		files, see the README.
+
+		Bundling warns if the assignment's deadline recorded in
+		.status.assignments is within .spec.due.warn (default 48h). After the
+		deadline, bundling is refused unless you pass --late, which marks the
+		bundle as late in the assignment's history. With --all, assignments
+		past their deadline are skipped unless --late is given.
 	`)
 )
 
@@ -70,6 +86,7 @@ type bundleData struct {
 	force bool
 	tar   bool
 	gzip  bool
+	late  bool
 }
 
 func newBundleData() *bundleData {
@@ -78,6 +95,7 @@ func newBundleData() *bundleData {
 		force: false,
 		tar:   false,
 		gzip:  false,
+		late:  false,
 	}
 }
 
@@ -103,18 +121,16 @@ func NewBundleCommand(ctx *context.AppContext, data *bundleData) *cobra.Command
 			if len(args) != 0 {
 				return nil, cobra.ShellCompDirectiveNoFileComp
 			}
-			return getAssignmentsFromRoot(toComplete, ctx.Root), cobra.ShellCompDirectiveNoFileComp
+			return getAssignmentsFromRoot(ctx, toComplete), cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			assignmentNo := ctx.Configuration.Status.Assignment
 			if len(args) != 0 {
-				assignmentArg := args[0]
-				// attempt to remove prefix from arguments. This is relevant when using the autocompletion
-				assignmentArg = strings.TrimPrefix(assignmentArg, "assignment-")
-				// attempt parsing numerically
-				i, err := strconv.Atoi(assignmentArg)
+				// the argument is either the assignment's number, or its directory's
+				// name. The latter is relevant when using the autocompletion
+				i, err := ctx.Naming().Argument(args[0])
 				if err == nil {
-					assignmentNo = uint32(i)
+					assignmentNo = i
 				}
 			}
 
@@ -122,75 +138,49 @@ func NewBundleCommand(ctx *context.AppContext, data *bundleData) *cobra.Command
 				return errors.New("cannot use --all flag with specific assignment")
 			}
 
-			if data.gzip && !data.tar {
-				return errors.New("cannot use --gzip without --tar")
-			}
-
-			backend := bundle.BundlerBackendZip
-			if data.tar {
-				if data.gzip {
-					backend = bundle.BundlerBackendTarGzip
-				} else {
-					backend = bundle.BundlerBackendTar
-				}
-			}
-
-			var template string
-			if ctx.Configuration.Spec.BundleOptions != nil && ctx.Configuration.Spec.BundleOptions.Template != "" {
-				template = ctx.Configuration.Spec.BundleOptions.Template
-			}
-
-			templateBindings := make(map[string]interface{})
-			if ctx.Configuration.Spec.BundleOptions != nil && ctx.Configuration.Spec.BundleOptions.Data != nil {
-				templateBindings = ctx.Configuration.Spec.BundleOptions.Data
+			backend, err := bundleBackend(data.tar, data.gzip)
+			if err != nil {
+				return err
 			}
-			templateBindings["_id"] = util.AddLeadingZero(assignmentNo)
 
 			bundleRuns := []string{}
 
 			if !data.all {
-				assignment := fmt.Sprintf("assignment-%s.pdf", util.AddLeadingZero(assignmentNo))
+				assignment := ctx.Naming().PDF(ctx.Naming().ID(assignmentNo))
 				bundleRuns = append(bundleRuns, assignment)
 			} else {
-				assignments, err := filepath.Glob(filepath.Join(ctx.Root, "dist", "assignment-*.pdf"))
+				assignments, err := filepath.Glob(filepath.Join(ctx.Root, "dist", ctx.Naming().Glob()+".pdf"))
 				if err != nil {
 					return err
 				}
 				for _, assignment := range assignments {
+					if !ctx.Naming().Match(assignment) {
+						continue
+					}
 					bundleRuns = append(bundleRuns, filepath.Base(assignment))
 				}
 			}
 
-			includes := []string{}
-			if ctx.Configuration.Spec.BundleOptions != nil && ctx.Configuration.Spec.BundleOptions.Include != nil {
-				includes = ctx.Configuration.Spec.BundleOptions.Include
-			}
-
 			for _, file := range bundleRuns {
-				opts := &bundle.BundlerOptions{
-					Backend:  backend,
-					Template: template,
-					Data:     templateBindings,
-					Target:   filepath.Base(file),
-					Includes: includes,
-					Force:    data.force,
+				opts, err := bundlerOptions(ctx, filepath.Base(file), backend, data.force)
+				if err != nil {
+					return err
 				}
-				bundler, err := bundle.New(ctx, opts)
+				archiveName, err := bundleAssignment(ctx, opts, data.late)
 				if err != nil {
+					if data.all && errors.Is(err, deadline.ErrDeadlinePassed) {
+						// only skip the current bundling, continue with other runs
+						log.Warn().Msgf("%v, skipping...", err)
+						continue
+					}
 					if errors.Is(err, bundle.ErrArchiveExists) {
 						// only skip the current bundling, continue with other runs
-						log.Warn().Msgf("Archive %s already exists and --force is not specified, skipping...", bundler.ArchiveName())
+						log.Warn().Msgf("Archive %s already exists and --force is not specified, skipping...", archiveName)
 						break
 					}
 					return err
 				}
 
-				if err := bundler.Bundle(); err != nil {
-					return err
-				}
-
-				archiveName := bundler.ArchiveName()
-
 				log.Info().Msgf("Finished bundling assignment to %s in ./dist/", archiveName)
 			}
 			return nil
@@ -203,11 +193,107 @@ func NewBundleCommand(ctx *context.AppContext, data *bundleData) *cobra.Command
 	return bundleCommand
 }
 
+// bundleAssignment creates the archive of a single assignment in ./dist/ and
+// records the bundled state. It returns the archive's name, which is also set
+// if the archive already exists. Bundling after the assignment's deadline fails
+// with deadline.ErrDeadlinePassed, unless late is set, in which case the bundled
+// transition is marked as late
+func bundleAssignment(ctx *context.AppContext, opts *bundle.BundlerOptions, late bool) (string, error) {
+	id, idErr := ctx.Naming().ParseID(opts.Target)
+	passed := false
+	if idErr == nil {
+		if err := deadlinePassed(ctx, id); err != nil {
+			if !late {
+				return "", fmt.Errorf("%w, use --late to bundle it anyway", err)
+			}
+			log.Warn().Msgf("%v, bundling late", err)
+			passed = true
+		} else {
+			warnOnDeadline(ctx, id)
+		}
+	}
+
+	bundler, err := bundle.New(ctx, opts)
+	if err != nil {
+		if errors.Is(err, bundle.ErrArchiveExists) {
+			return bundler.ArchiveName(), err
+		}
+		return "", err
+	}
+
+	if err := bundler.Bundle(); err != nil {
+		return "", err
+	}
+
+	archiveName := bundler.ArchiveName()
+
+	if idErr == nil {
+		recordTransition(ctx, id, config.StateBundled, filepath.Join(ctx.Root, "dist", archiveName))
+		if passed {
+			ctx.Configuration.Status.Lookup(id).Last(config.StateBundled).Late = true
+		}
+	}
+	return archiveName, nil
+}
+
+// bundlerOptions creates the options for bundling the assignment's PDF in ./dist/
+// from the bundle options in the configuration, with the assignment's configuration
+// file applied
+func bundlerOptions(ctx *context.AppContext, target string, backend bundle.BundlerBackend, force bool) (*bundle.BundlerOptions, error) {
+	assignmentCtx, err := ctx.ForAssignment(strings.TrimSuffix(target, ".pdf"))
+	if err != nil {
+		return nil, err
+	}
+	template, templateBindings := archiveNameTemplate(assignmentCtx)
+	includes := []string{}
+	if opts := assignmentCtx.Configuration.Spec.BundleOptions; opts != nil && opts.Include != nil {
+		includes = opts.Include
+	}
+	return &bundle.BundlerOptions{
+		Backend:  backend,
+		Template: template,
+		Data:     templateBindings,
+		Target:   target,
+		Includes: includes,
+		Force:    force,
+	}, nil
+}
+
+// bundleBackend picks the bundler backend from the --tar and --gzip flags
+func bundleBackend(tar bool, gzip bool) (bundle.BundlerBackend, error) {
+	if gzip && !tar {
+		return "", errors.New("cannot use --gzip without --tar")
+	}
+	if tar {
+		if gzip {
+			return bundle.BundlerBackendTarGzip, nil
+		}
+		return bundle.BundlerBackendTar, nil
+	}
+	return bundle.BundlerBackendZip, nil
+}
+
+// archiveNameTemplate returns the archive name template and a copy of its data
+// bindings from the configuration. The template is empty if not configured, in
+// which case the bundler uses its default
+func archiveNameTemplate(ctx *context.AppContext) (string, map[string]interface{}) {
+	var template string
+	templateBindings := make(map[string]interface{})
+	if opts := ctx.Configuration.Spec.BundleOptions; opts != nil {
+		template = opts.Template
+		for k, v := range opts.Data {
+			templateBindings[k] = v
+		}
+	}
+	return template, templateBindings
+}
+
 func addBundleFlags(flags *pflag.FlagSet, data *bundleData) {
 	flags.BoolVarP(&data.all, options.All, options.AllShort, false, "Bundle all assignments")
 	flags.BoolVarP(&data.force, options.Force, options.ForceShort, false, "Override any existing archives with the same name")
 	flags.BoolVar(&data.tar, options.Tar, false, "Use tar as a backend for archive bundling")
 	flags.BoolVar(&data.gzip, options.Gzip, false, "Use gzip to encode the archive. Requires --tar to be specified as well")
+	flags.BoolVar(&data.late, options.Late, false, "Bundle assignments whose deadline has passed, marking the bundle as late")
 }
 
 func addBundleFlagsCompletion(cmd *cobra.Command) {
@@ -215,4 +301,5 @@ func addBundleFlagsCompletion(cmd *cobra.Command) {
 	cmd.RegisterFlagCompletionFunc(options.Force, cobra.NoFileCompletions)
 	cmd.RegisterFlagCompletionFunc(options.Tar, cobra.NoFileCompletions)
 	cmd.RegisterFlagCompletionFunc(options.Gzip, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Late, cobra.NoFileCompletions)
 }
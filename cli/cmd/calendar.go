@@ -0,0 +1,292 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zoomoid/assignments/v1/cmd/options"
+	"github.com/zoomoid/assignments/v1/internal/calendar"
+	"github.com/zoomoid/assignments/v1/internal/ci"
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/inventory"
+)
+
+var (
+	calendarExportLongDescription = dedent.Dedent(`
+		The command exports the due dates recorded in .status.assignments as
+		an iCalendar file (RFC 5545), which you can import into or subscribe
+		to from any calendar application. Each assignment with a due date
+		becomes one event named after the course, with the group in its
+		description. A due date in an assignment's assignment.yaml takes
+		precedence, like for "assignmentctl due".
+
+		Events have a stable UID derived from the course, the group, and the
+		assignment's number. Importing the file again after changing a due
+		date therefore updates the existing event instead of duplicating it.
+
+		Every event reminds you ahead of the deadline. The time before the
+		deadline is set in .spec.calendar.alarm, e.g., "24h", "90m", or "2d",
+		and defaults to 24h. Override it with --alarm, and use "none" to
+		disable reminders.
+
+		Without --file, the calendar is written to stdout.
+	`)
+
+	calendarImportLongDescription = dedent.Dedent(`
+		The command reads the deadlines of all assignments from an iCalendar
+		file, e.g., as published by your course's portal, and records them in
+		.status.assignments. Afterwards, "assignmentctl generate" uses the
+		recorded due date without prompting, and "assignmentctl list" shows
+		the upcoming deadlines. The file is read locally, no network access is
+		required.
+
+		Events are mapped to assignments by matching their summary against a
+		regular expression. The assignment's ID is captured by its first
+		group, or by a group named "number". The default pattern
+		
+		  (?i)(?:assignment|sheet|exercise|homework|blatt|übung)\D{0,3}(\d+[a-z]?)
+
+		matches summaries such as "Sheet 3", "Assignment #03 due",
+		"Übungsblatt 3", or "Sheet 5b". Set your own pattern in .spec.calendar.pattern or
+		override it with --pattern. Events not matching the pattern are
+		ignored. If several events match the same assignment, the latest one
+		is used.
+
+		Times without timezone are interpreted in .spec.due.timezone, and
+		events on whole days are due at .spec.due.time (default 23:59).
+		Recorded due dates are overwritten by imported ones.
+	`)
+)
+
+type calendarExportData struct {
+	file  string
+	alarm string
+}
+
+func newCalendarExportData() *calendarExportData {
+	return &calendarExportData{
+		file:  "",
+		alarm: "",
+	}
+}
+
+type calendarImportData struct {
+	pattern string
+}
+
+func newCalendarImportData() *calendarImportData {
+	return &calendarImportData{
+		pattern: "",
+	}
+}
+
+func NewCalendarCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "calendar",
+		Short: "Exchange assignment deadlines with calendar applications",
+		Long:  "The command is not meant to be run on its own",
+	}
+
+	cmd.AddCommand(NewCalendarExportCommand(ctx, nil))
+	cmd.AddCommand(NewCalendarImportCommand(ctx, nil))
+
+	return cmd
+}
+
+func NewCalendarExportCommand(ctx *context.AppContext, data *calendarExportData) *cobra.Command {
+	if data == nil {
+		data = newCalendarExportData()
+	}
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export all due dates to an iCalendar file",
+		Long:  calendarExportLongDescription,
+		Args:  cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			alarm := data.alarm
+			if alarm == "" && ctx.Configuration.Spec.CalendarOptions != nil {
+				alarm = ctx.Configuration.Spec.CalendarOptions.Alarm
+			}
+			d, err := calendar.ParseAlarm(alarm)
+			if err != nil {
+				return err
+			}
+
+			status, err := dueDates(ctx)
+			if err != nil {
+				return err
+			}
+			cal := calendar.Deadlines(ctx.Configuration.Spec, status, d, time.Now())
+
+			out, isStdout := ci.OpenOrFallbackToStdout(data.file)
+			if !isStdout {
+				defer out.Close()
+			}
+			if err := calendar.Encode(out, cal); err != nil {
+				return err
+			}
+			if !isStdout {
+				log.Info().Msgf("Exported %d deadlines to %s", len(cal.Events), data.file)
+			}
+			return nil
+		},
+	}
+
+	addCalendarExportFlags(cmd.PersistentFlags(), data)
+	addCalendarExportFlagsCompletion(cmd)
+
+	return cmd
+}
+
+func NewCalendarImportCommand(ctx *context.AppContext, data *calendarImportData) *cobra.Command {
+	if data == nil {
+		data = newCalendarImportData()
+	}
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import due dates of assignments from an iCalendar file",
+		Long:  calendarImportLongDescription,
+		Args:  cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		PostRun: func(cmd *cobra.Command, args []string) {
+			defer ctx.Write()
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return []string{"ics"}, cobra.ShellCompDirectiveFilterFileExt
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pattern := data.pattern
+			if pattern == "" && ctx.Configuration.Spec.CalendarOptions != nil {
+				pattern = ctx.Configuration.Spec.CalendarOptions.Pattern
+			}
+			if pattern == "" {
+				pattern = calendar.DefaultPattern
+			}
+			r, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid pattern %q, %w", pattern, err)
+			}
+
+			parser, err := newDueParser(ctx)
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			cal, err := calendar.Decode(f, parser.Location)
+			if err != nil {
+				return fmt.Errorf("failed to read %s, %w", args[0], err)
+			}
+
+			deadlines, err := calendar.Import(cal, r, parser.TimeOfDay)
+			if err != nil {
+				return err
+			}
+			if len(deadlines) == 0 {
+				log.Warn().Msgf("None of the %d events in %s matches the pattern %q", len(cal.Events), args[0], pattern)
+				return nil
+			}
+
+			for _, d := range deadlines {
+				id := ctx.Naming().ID(d.ID)
+				due := d.Due
+				status := ctx.Configuration.Status.Upsert(id)
+				if status.Due != nil && status.Due.Equal(due) {
+					log.Debug().Msgf("Due date of assignment %s is unchanged", id)
+					continue
+				}
+				status.Due = &due
+				log.Info().Msgf("Assignment %s is due %s (%q)", id, due.In(parser.Location).Format("Mon 2006-01-02 15:04 MST"), d.Summary)
+			}
+			return nil
+		},
+	}
+
+	addCalendarImportFlags(cmd.PersistentFlags(), data)
+	addCalendarImportFlagsCompletion(cmd)
+
+	return cmd
+}
+
+// dueDates returns a copy of the configuration's status with the due dates of all
+// assignments, including those set in their assignment.yaml, which take precedence
+// over the ones recorded in .status.assignments, as for "assignmentctl due"
+func dueDates(ctx *context.AppContext) (*config.ConfigurationStatus, error) {
+	assignments, err := inventory.Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := ctx.Configuration.Status.Clone()
+	if status == nil {
+		status = &config.ConfigurationStatus{}
+	}
+	for _, a := range assignments {
+		if a.Due != nil {
+			due := *a.Due
+			status.Upsert(a.ID).Due = &due
+		}
+	}
+	return status, nil
+}
+
+func addCalendarExportFlags(flags *pflag.FlagSet, data *calendarExportData) {
+	flags.StringVarP(&data.file, options.File, options.FileShort, "", "Write the calendar to a file instead of stdout, e.g., deadlines.ics")
+	flags.StringVar(&data.alarm, options.Alarm, "", "Time before each deadline to remind at, e.g., 24h or 2d, or \"none\". Overrides .spec.calendar.alarm")
+}
+
+func addCalendarExportFlagsCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc(options.File, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"ics"}, cobra.ShellCompDirectiveFilterFileExt
+	})
+	cmd.RegisterFlagCompletionFunc(options.Alarm, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"1h", "24h", "2d", calendar.NoAlarm}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+func addCalendarImportFlags(flags *pflag.FlagSet, data *calendarImportData) {
+	flags.StringVar(&data.pattern, options.Pattern, "", "Regular expression capturing the assignment's number in event summaries. Overrides .spec.calendar.pattern")
+}
+
+func addCalendarImportFlagsCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc(options.Pattern, cobra.NoFileCompletions)
+}
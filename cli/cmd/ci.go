@@ -19,7 +19,6 @@ package cmd
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
 
 	"github.com/lithammer/dedent"
 	"github.com/rs/zerolog/log"
@@ -45,6 +44,9 @@ var (
 		Run this command to quickly template CI files for the supported SCM providers,
 		namely Gitlab and Github. Afterwards, you can customize them to your liking.
 
+		The templates only release tags named after assignments, e.g.,
+		assignment-03, following the naming scheme in .spec.naming.
+
 		To learn more about the CI integration, see the documentation at 
 		https://github.com/zoomoid/assignments/blob/main/ci/README.md
 	`)
@@ -91,6 +93,12 @@ func NewCiReleaseCommand(ctx *context.AppContext) *cobra.Command {
 			fmt.Sprintf("%s\t%s", string(GithubSCM), "Creates a .env file to use in a release job in Github Action"),
 			fmt.Sprintf("%s\t%s", string(GitlabSCM), "Creates a .env file to use in a release job in Gitlab CI"),
 		},
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			out, isStdout := ci.OpenOrFallbackToStdout(file)
 			if !isStdout {
@@ -98,19 +106,11 @@ func NewCiReleaseCommand(ctx *context.AppContext) *cobra.Command {
 			}
 
 			artifactsDirectory := filepath.Join(ctx.Root, "dist")
-			archiveTemplate := ""
-			data := make(map[string]interface{})
-			if b := ctx.Configuration.Spec.BundleOptions; b != nil {
-				archiveTemplate = b.Template
-			}
-			if d := ctx.Configuration.Spec.BundleOptions.Data; d != nil {
-				data = d
-			}
 
 			// with validators configured this can be assumed to be the only argument
 			t := SCMProvider(args[0])
 			if t == GithubSCM {
-				o, err := ci.TemplateGithubActionsEnvFile(artifactsDirectory, archiveTemplate, data)
+				o, err := ci.TemplateGithubActionsEnvFile(ctx, artifactsDirectory)
 				if err != nil {
 					return err
 				}
@@ -119,7 +119,7 @@ func NewCiReleaseCommand(ctx *context.AppContext) *cobra.Command {
 			}
 
 			if t == GitlabSCM {
-				o, err := ci.TemplateGitlabCIEnvFile(artifactsDirectory, archiveTemplate, data)
+				o, err := ci.TemplateGitlabCIEnvFile(ctx, artifactsDirectory)
 				if err != nil {
 					return err
 				}
@@ -149,6 +149,12 @@ func NewCiBootstrapCommand(ctx *context.AppContext) *cobra.Command {
 			fmt.Sprintf("%s\t%s", string(GithubSCM), "Creates a Github Actions YAML file"),
 			fmt.Sprintf("%s\t%s", string(GitlabSCM), "Creates a Gitlab CI .gitlab-ci.yml file"),
 		},
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			out, isStdout := ci.OpenOrFallbackToStdout(file)
 			if !isStdout {
@@ -158,12 +164,20 @@ func NewCiBootstrapCommand(ctx *context.AppContext) *cobra.Command {
 			// with validators configured this can be assumed to be the only argument
 			t := SCMProvider(args[0])
 			if t == GithubSCM {
-				out.WriteString(strings.TrimSpace(ci.GithubActionTemplate))
+				o, err := ci.GithubAction(ctx.Naming())
+				if err != nil {
+					return err
+				}
+				out.WriteString(o)
 				return nil
 			}
 
 			if t == GitlabSCM {
-				out.WriteString(strings.TrimSpace(ci.GitlabCITemplate))
+				o, err := ci.GitlabCI(ctx.Naming())
+				if err != nil {
+					return err
+				}
+				out.WriteString(o)
 				return nil
 			}
 			return fmt.Errorf("%s is not a supported SCM provider", args[0])
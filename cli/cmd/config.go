@@ -0,0 +1,631 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zoomoid/assignments/v1/cmd/options"
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/trash"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	configMigrateLongDescription = dedent.Dedent(`
+		.assignments.yaml starts with its schema's version, e.g.,
+
+		  apiVersion: assignments.zoomoid.dev/v1
+		  kind: Configuration
+
+		Configurations without apiVersion were written before versioning.
+		Every command upgrades older configurations to the current version in
+		memory when reading them, but only this command writes the current
+		version into the file.
+
+		The command rewrites .assignments.yaml in the current version right
+		away and lists the changes made by each migration. Comments and the
+		order of fields are kept. The previous file is kept in a snapshot,
+		see "assignmentctl restore --help". Pass --dry-run to print the
+		upgraded configuration instead.
+
+		Fields unknown to assignmentctl, e.g., typos, would be lost when
+		rewriting the file, so the command refuses to migrate them. Fix or
+		remove them, or pass --force to drop them. Other commands ignore
+		unknown fields, unless --strict is passed.
+
+		Configurations of newer versions than the current one are rejected by
+		all commands. Upgrade assignmentctl to use them.
+	`)
+
+	configSchemaLongDescription = dedent.Dedent(`
+		The command prints the JSON Schema of .assignments.yaml, generated
+		from the configuration of this version of assignmentctl. Pass --file
+		(or -f) to write it to a file instead.
+
+		Editors with a YAML language server use the schema for completion
+		and to highlight mistakes while editing, e.g., by adding
+
+		  # yaml-language-server: $schema=<path to the schema>
+
+		to the top of .assignments.yaml.
+	`)
+
+	configValidateLongDescription = dedent.Dedent(`
+		The command checks .assignments.yaml, or the file passed as argument,
+		against the configuration's schema, see "assignmentctl config schema",
+		and reports every problem with its path and line, e.g., unknown fields
+		such as typos, values of the wrong type, or missing required fields
+		such as a recipe's command.
+
+		It also reports mistakes that the schema cannot express:
+
+		  - .spec.build.cleanup.glob and .spec.build.cleanup.command are
+		    mutually exclusive
+		  - members must not leave the group before they joined it
+
+		Configurations without apiVersion are reported with a warning, upgrade
+		them with "assignmentctl config migrate". The command fails if there is
+		any problem other than warnings.
+	`)
+
+	configGetLongDescription = dedent.Dedent(`
+		The command prints the value at a path in .assignments.yaml. Paths
+		consist of the configuration's field names, separated by dots, and
+		indices of lists in brackets, e.g.,
+
+		  spec.bundle.template
+		  spec.members[0].name
+		  status.assignments.05.state
+
+		Scalars are printed as they are, objects and lists as YAML. The
+		command fails if there is no value at the path.
+	`)
+
+	configSetLongDescription = dedent.Dedent(`
+		The command sets the value at a path in .assignments.yaml, see
+		"assignmentctl config get --help" for paths. Missing objects along
+		the path are created.
+
+		Values of text fields, e.g., .spec.group or a member's id, are taken
+		as they are, such that 0123456 keeps its leading zero. Other values
+		are read as YAML, e.g., 7 is a number, and [a, b] is a list. Pass an
+		empty string to clear a text field, and null to clear others.
+
+		The configuration is validated before it is written, and the command
+		fails without changing the file if the change introduces any problem,
+		see "assignmentctl config validate --help".
+	`)
+
+	configAddLongDescription = dedent.Dedent(`
+		The command appends values to the list at a path in .assignments.yaml,
+		see "assignmentctl config get --help" for paths. Objects are given as
+		comma-separated key=value pairs, e.g., for a group member
+
+		  assignmentctl config add spec.members name="Erika Mustermann",id=123456
+
+		Values are read by their fields' types like in "assignmentctl config
+		set", e.g., id=0123456 keeps its leading zero. Items that are no
+		key=value pairs are read the same way, including objects in flow
+		style, e.g., '{name: Erika, id: "1,2"}' for values containing commas.
+
+		Like set, the command fails without changing the file if the change
+		introduces any problem.
+	`)
+
+	configEditLongDescription = dedent.Dedent(`
+		The command opens .assignments.yaml in your editor, which is taken
+		from $VISUAL or $EDITOR, and defaults to vi.
+
+		After the editor exits, the changes are validated like by
+		"assignmentctl config validate". If there are problems, they are
+		listed, and you can edit the file again, or discard your changes.
+		.assignments.yaml is only replaced by a valid configuration.
+	`)
+)
+
+func NewConfigCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the configuration file .assignments.yaml",
+		Long:  "The command is not meant to be run on its own",
+	}
+
+	cmd.AddCommand(NewConfigMigrateCommand(ctx, nil))
+	cmd.AddCommand(NewConfigSchemaCommand(ctx, nil))
+	cmd.AddCommand(NewConfigValidateCommand(ctx))
+	cmd.AddCommand(NewConfigGetCommand(ctx))
+	cmd.AddCommand(NewConfigSetCommand(ctx))
+	cmd.AddCommand(NewConfigAddCommand(ctx))
+	cmd.AddCommand(NewConfigEditCommand(ctx))
+
+	return cmd
+}
+
+type configMigrateData struct {
+	force  bool
+	dryRun bool
+}
+
+func newConfigMigrateData() *configMigrateData {
+	return &configMigrateData{
+		force:  false,
+		dryRun: false,
+	}
+}
+
+func NewConfigMigrateCommand(ctx *context.AppContext, data *configMigrateData) *cobra.Command {
+	if data == nil {
+		data = newConfigMigrateData()
+	}
+
+	cmd := &cobra.Command{
+		Use:               "migrate",
+		Short:             "Upgrade .assignments.yaml to the current configuration schema",
+		Long:              configMigrateLongDescription,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, err := config.Find(ctx.Cwd)
+			if err != nil {
+				return err
+			}
+			path := filepath.Join(root, config.ConfigurationFileName)
+			in, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			cfg, applied, err := config.Decode(in, !data.force)
+			if err != nil {
+				return fmt.Errorf("failed to read %s, %w. Pass --force to drop unknown fields", path, err)
+			}
+			// other commands keep the file's version, only migrating writes the current one
+			cfg.APIVersion, cfg.Kind = config.APIVersion, config.Kind
+
+			out, err := config.Patch(in, cfg)
+			if err != nil {
+				return err
+			}
+			if data.dryRun {
+				_, err = cmd.OutOrStdout().Write(out)
+				return err
+			}
+			if len(applied) == 0 && !data.force {
+				log.Info().Msgf("%s is at the current version %s already", config.ConfigurationFileName, config.APIVersion)
+				return nil
+			}
+
+			var trashOptions *config.TrashOptions
+			if cfg.Spec != nil {
+				trashOptions = cfg.Spec.TrashOptions
+			}
+			store, err := trash.Open(root, trashOptions)
+			if err != nil {
+				return err
+			}
+			snapshot, err := store.Save(&trash.Snapshot{Operation: "migrate"}, config.ConfigurationFileName)
+			if err != nil {
+				return err
+			}
+			logSnapshot(snapshot)
+
+			if err := os.WriteFile(path, out, 0644); err != nil {
+				return err
+			}
+			for _, m := range applied {
+				log.Info().Msgf("Migrated from %s to %s, which %s", config.VersionName(m.From), m.To, m.Description)
+			}
+			log.Info().Msgf("Wrote %s in version %s", config.ConfigurationFileName, config.APIVersion)
+			return nil
+		},
+	}
+
+	addConfigMigrateFlags(cmd.PersistentFlags(), data)
+	addConfigMigrateFlagsCompletion(cmd)
+
+	return cmd
+}
+
+func addConfigMigrateFlags(flags *pflag.FlagSet, data *configMigrateData) {
+	flags.BoolVarP(&data.force, options.Force, options.ForceShort, false, "Drop fields unknown to assignmentctl instead of refusing to migrate")
+	flags.BoolVar(&data.dryRun, options.DryRun, false, "Print the upgraded configuration instead of writing it")
+}
+
+func addConfigMigrateFlagsCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc(options.Force, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.DryRun, cobra.NoFileCompletions)
+}
+
+type configSchemaData struct {
+	file string
+}
+
+func newConfigSchemaData() *configSchemaData {
+	return &configSchemaData{
+		file: "",
+	}
+}
+
+func NewConfigSchemaCommand(ctx *context.AppContext, data *configSchemaData) *cobra.Command {
+	if data == nil {
+		data = newConfigSchemaData()
+	}
+
+	cmd := &cobra.Command{
+		Use:               "schema",
+		Short:             "Print the JSON Schema of .assignments.yaml",
+		Long:              configSchemaLongDescription,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, err := json.MarshalIndent(config.JSONSchema(), "", "  ")
+			if err != nil {
+				return err
+			}
+			out = append(out, '\n')
+
+			if data.file == "" {
+				_, err = cmd.OutOrStdout().Write(out)
+				return err
+			}
+			path := data.file
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(ctx.Cwd, path)
+			}
+			if err := os.WriteFile(path, out, 0644); err != nil {
+				return err
+			}
+			log.Info().Msgf("Wrote JSON Schema to %s", path)
+			return nil
+		},
+	}
+
+	addConfigSchemaFlags(cmd.PersistentFlags(), data)
+	addConfigSchemaFlagsCompletion(cmd)
+
+	return cmd
+}
+
+func addConfigSchemaFlags(flags *pflag.FlagSet, data *configSchemaData) {
+	flags.StringVarP(&data.file, options.File, options.FileShort, "", "File to write the schema to instead of stdout")
+}
+
+func addConfigSchemaFlagsCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc(options.File, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json"}, cobra.ShellCompDirectiveFilterFileExt
+	})
+}
+
+func NewConfigValidateCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Report mistakes in .assignments.yaml",
+		Long:  configValidateLongDescription,
+		Args:  cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return []string{"yaml", "yml"}, cobra.ShellCompDirectiveFilterFileExt
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var path string
+			if len(args) != 0 {
+				path = args[0]
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(ctx.Cwd, path)
+				}
+			} else {
+				root, err := config.Find(ctx.Cwd)
+				if err != nil {
+					return err
+				}
+				path = filepath.Join(root, config.ConfigurationFileName)
+			}
+			in, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			problems, err := config.ValidateDocument(in)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s, %w", path, err)
+			}
+
+			name := path
+			if rel, err := filepath.Rel(ctx.Cwd, path); err == nil {
+				name = rel
+			}
+			for _, p := range problems {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s:%s\n", name, p)
+			}
+			if found := config.Errors(problems); len(found) > 0 {
+				return fmt.Errorf("found %d problems in %s", len(found), name)
+			}
+			log.Info().Msgf("%s is valid", name)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func NewConfigGetCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "get <path>",
+		Short:             "Print a value of .assignments.yaml",
+		Long:              configGetLongDescription,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfigPath,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, err := config.ToDocument(ctx.Configuration)
+			if err != nil {
+				return err
+			}
+			value, err := config.GetPath(doc, args[0])
+			if err != nil {
+				return err
+			}
+			switch value.(type) {
+			case nil:
+				_, err = fmt.Fprintln(cmd.OutOrStdout())
+			case map[interface{}]interface{}, []interface{}:
+				out, err := yaml.Marshal(value)
+				if err != nil {
+					return err
+				}
+				_, err = cmd.OutOrStdout().Write(out)
+				return err
+			default:
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), value)
+			}
+			return err
+		},
+	}
+	return cmd
+}
+
+func NewConfigSetCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <path> <value>",
+		Short: "Set a value of .assignments.yaml",
+		Long:  configSetLongDescription,
+		Args:  cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeConfigPath(cmd, args, toComplete)
+		},
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		PostRun: func(cmd *cobra.Command, args []string) {
+			defer ctx.Write()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			value := config.ParseValue(args[0], args[1])
+			err := changeConfiguration(ctx, cmd, func(doc map[interface{}]interface{}) error {
+				return config.SetPath(doc, args[0], value)
+			})
+			if err != nil {
+				return err
+			}
+			log.Info().Msgf("Set %s to %s", args[0], args[1])
+			return nil
+		},
+	}
+	return cmd
+}
+
+func NewConfigAddCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <path> <value>...",
+		Short: "Append values to a list of .assignments.yaml",
+		Long:  configAddLongDescription,
+		Args:  cobra.MinimumNArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeConfigPath(cmd, args, toComplete)
+		},
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		PostRun: func(cmd *cobra.Command, args []string) {
+			defer ctx.Write()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := changeConfiguration(ctx, cmd, func(doc map[interface{}]interface{}) error {
+				for _, arg := range args[1:] {
+					if err := config.AppendPath(doc, args[0], config.ParseItem(args[0], arg)); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			log.Info().Msgf("Added %d values to %s", len(args)-1, args[0])
+			return nil
+		},
+	}
+	return cmd
+}
+
+func NewConfigEditCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "edit",
+		Short:             "Edit .assignments.yaml in your editor and validate the changes",
+		Long:              configEditLongDescription,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, err := config.Find(ctx.Cwd)
+			if err != nil {
+				return err
+			}
+			path := filepath.Join(root, config.ConfigurationFileName)
+			original, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			// edit a copy, such that the configuration is only replaced when valid
+			tmp, err := os.CreateTemp("", "assignments-*.yaml")
+			if err != nil {
+				return err
+			}
+			defer os.Remove(tmp.Name())
+			_, err = tmp.Write(original)
+			if cerr := tmp.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+
+			for {
+				if err := runEditor(tmp.Name()); err != nil {
+					return err
+				}
+				edited, err := os.ReadFile(tmp.Name())
+				if err != nil {
+					return err
+				}
+				if bytes.Equal(edited, original) {
+					log.Info().Msgf("%s is unchanged", config.ConfigurationFileName)
+					return nil
+				}
+				problems, err := config.ValidateDocument(edited)
+				if err != nil {
+					problems = []config.Problem{{Path: ".", Message: err.Error()}}
+				}
+				if len(config.Errors(problems)) == 0 {
+					if err := os.WriteFile(path, edited, 0644); err != nil {
+						return err
+					}
+					log.Info().Msgf("Wrote %s", path)
+					return nil
+				}
+				for _, p := range problems {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s:%s\n", config.ConfigurationFileName, p)
+				}
+				problems = config.Errors(problems)
+				if !promptConfirmation(fmt.Sprintf("Found %d problems, edit again?", len(problems))) {
+					return fmt.Errorf("discarded changes to %s with %d problems", config.ConfigurationFileName, len(problems))
+				}
+			}
+		},
+	}
+	return cmd
+}
+
+// changeConfiguration applies the change to the context's configuration by path,
+// see config.SetPath, and fails without changing it if the result has problems
+func changeConfiguration(ctx *context.AppContext, cmd *cobra.Command, change func(doc map[interface{}]interface{}) error) error {
+	doc, err := config.ToDocument(ctx.Configuration)
+	if err != nil {
+		return err
+	}
+	if err := change(doc); err != nil {
+		return err
+	}
+	cfg, problems, err := config.FromDocument(doc)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", p.Path, p.Message)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("refusing to change %s, found %d problems", config.ConfigurationFileName, len(problems))
+	}
+	ctx.Configuration = cfg
+	return nil
+}
+
+// runEditor opens the file in the user's editor and waits for it to exit
+func runEditor(file string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// editors are commonly configured with arguments, e.g., "code --wait"
+	fields := strings.Fields(editor)
+	c := exec.Command(fields[0], append(fields[1:], file)...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("failed to run editor %s, %w", editor, err)
+	}
+	return nil
+}
+
+// completeConfigPath completes the field names of the configuration's schema
+func completeConfigPath(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	parent := ""
+	if i := strings.LastIndex(toComplete, "."); i >= 0 {
+		parent = toComplete[:i]
+	}
+	s := config.JSONSchema().Lookup(parent)
+	if s == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	prefix := ""
+	if parent != "" {
+		prefix = parent + "."
+	}
+	completions := []string{}
+	for name := range s.Properties {
+		completions = append(completions, prefix+name)
+	}
+	sort.Strings(completions)
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
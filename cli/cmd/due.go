@@ -0,0 +1,248 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zoomoid/assignments/v1/cmd/options"
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/deadline"
+	"github.com/zoomoid/assignments/v1/internal/inventory"
+)
+
+var (
+	dueLongDescription = dedent.Dedent(`
+		The command lists the upcoming deadlines of all assignments recorded
+		in .status.assignments, sorted by their due date, together with the
+		time left and the assignment's lifecycle state. Pass --all to include
+		deadlines that have already passed.
+
+		Due dates are recorded when generating an assignment with --due, or
+		when importing them with "assignmentctl calendar import". A due date
+		in an assignment's assignment.yaml takes precedence.
+
+		Building and bundling an assignment warns if its deadline is within
+		.spec.due.warn (default 48h, "none" disables the warning) or has
+		passed. Bundling an assignment after its deadline is refused unless
+		you pass --late, in which case the bundled transition is marked as
+		late in the assignment's history.
+
+		Pass -o json for machine-readable output.
+	`)
+)
+
+type dueData struct {
+	all    bool
+	output string
+}
+
+func newDueData() *dueData {
+	return &dueData{
+		all:    false,
+		output: outputFormatTable,
+	}
+}
+
+// dueEntry is a single deadline listed by the due command
+type dueEntry struct {
+	ID    string       `json:"id"`
+	Due   time.Time    `json:"due"`
+	State config.State `json:"state,omitempty"`
+}
+
+func NewDueCommand(ctx *context.AppContext, data *dueData) *cobra.Command {
+	if data == nil {
+		data = newDueData()
+	}
+
+	cmd := &cobra.Command{
+		Use:   "due",
+		Short: "List upcoming deadlines sorted by due date",
+		Long:  dueLongDescription,
+		Args:  cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			assignments, err := inventory.Scan(ctx)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			entries := []dueEntry{}
+			for _, a := range assignments {
+				if a.Due == nil {
+					continue
+				}
+				if !data.all && a.Due.Before(now) {
+					continue
+				}
+				entries = append(entries, dueEntry{ID: a.ID, Due: *a.Due, State: a.State})
+			}
+			sort.Slice(entries, func(i, j int) bool {
+				if entries[i].Due.Equal(entries[j].Due) {
+					return entries[i].ID < entries[j].ID
+				}
+				return entries[i].Due.Before(entries[j].Due)
+			})
+
+			switch data.output {
+			case outputFormatJSON:
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(entries)
+			case outputFormatTable:
+				if len(entries) == 0 {
+					log.Info().Msg("No upcoming deadlines")
+					return nil
+				}
+				return printDueTable(cmd.OutOrStdout(), ctx, entries, now)
+			default:
+				return fmt.Errorf("unknown output format %q, use one of %s, %s", data.output, outputFormatTable, outputFormatJSON)
+			}
+		},
+	}
+
+	addDueFlags(cmd.PersistentFlags(), data)
+	addDueFlagsCompletion(cmd)
+
+	return cmd
+}
+
+func printDueTable(out io.Writer, ctx *context.AppContext, entries []dueEntry, now time.Time) error {
+	loc := courseLocation(ctx)
+	w := tabwriter.NewWriter(out, 0, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "ASSIGNMENT\tDUE\tLEFT\tSTATE")
+	for _, e := range entries {
+		state := "-"
+		if e.State != "" {
+			state = string(e.State)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.ID, e.Due.In(loc).Format("Mon 2006-01-02 15:04"), deadline.Humanize(e.Due, now), state)
+	}
+	return w.Flush()
+}
+
+// courseLocation returns the course's timezone, or the local timezone if it is not
+// configured or invalid
+func courseLocation(ctx *context.AppContext) *time.Location {
+	if opts := ctx.Configuration.Spec.DueOptions; opts != nil {
+		if loc, err := deadline.Location(opts.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// warningWindow returns the time before a due date in which build and bundle warn,
+// or nil if warnings are disabled. Invalid configuration falls back to the default
+func warningWindow(ctx *context.AppContext) *time.Duration {
+	s := ""
+	if opts := ctx.Configuration.Spec.DueOptions; opts != nil {
+		s = opts.Warn
+	}
+	if s == "none" {
+		return nil
+	}
+	if s == "" {
+		s = deadline.DefaultWarningWindow
+	}
+	d, err := deadline.ParseDuration(s)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Invalid .spec.due.warn, using %s", deadline.DefaultWarningWindow)
+		d, _ = deadline.ParseDuration(deadline.DefaultWarningWindow)
+	}
+	return &d
+}
+
+// dueDate returns the assignment's due date from its configuration file, or the
+// one recorded in the status, together with its lifecycle state
+func dueDate(ctx *context.AppContext, id string) (*time.Time, config.State) {
+	assignmentCtx, err := ctx.ForAssignment(ctx.Naming().Name(id))
+	if err != nil {
+		log.Warn().Err(err).Msgf("Ignoring configuration file of assignment %s", id)
+		assignmentCtx = ctx
+	}
+	status := assignmentCtx.Configuration.Status.Lookup(id)
+	if status == nil {
+		return nil, ""
+	}
+	return status.Due, status.State
+}
+
+// warnOnDeadline warns if the assignment's deadline is within the warning window,
+// or has passed while the assignment is not submitted or graded yet
+func warnOnDeadline(ctx *context.AppContext, id string) {
+	due, state := dueDate(ctx, id)
+	if due == nil || state.Manual() {
+		return
+	}
+	window := warningWindow(ctx)
+	if window == nil {
+		return
+	}
+	now := time.Now()
+	formatted := due.In(courseLocation(ctx)).Format("Mon 2006-01-02 15:04")
+	if due.Before(now) {
+		log.Warn().Msgf("Assignment %s was due %s, %s", id, formatted, deadline.Humanize(*due, now))
+		return
+	}
+	if due.Sub(now) <= *window {
+		log.Warn().Msgf("Assignment %s is due %s, %s", id, formatted, deadline.Humanize(*due, now))
+	}
+}
+
+// deadlinePassed returns an error wrapping deadline.ErrDeadlinePassed if the
+// assignment's due date is in the past
+func deadlinePassed(ctx *context.AppContext, id string) error {
+	due, _ := dueDate(ctx, id)
+	if due == nil {
+		return nil
+	}
+	now := time.Now()
+	if due.Before(now) {
+		formatted := due.In(courseLocation(ctx)).Format("Mon 2006-01-02 15:04")
+		return fmt.Errorf("%w, assignment %s was due %s, %s", deadline.ErrDeadlinePassed, id, formatted, deadline.Humanize(*due, now))
+	}
+	return nil
+}
+
+func addDueFlags(flags *pflag.FlagSet, data *dueData) {
+	flags.BoolVarP(&data.all, options.All, options.AllShort, false, "Include deadlines that have passed")
+	flags.StringVarP(&data.output, options.Output, options.OutputShort, outputFormatTable, "Output format, either table or json")
+}
+
+func addDueFlagsCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc(options.All, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Output, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{outputFormatTable, outputFormatJSON}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
@@ -18,10 +18,11 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
@@ -29,8 +30,11 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/zoomoid/assignments/v1/cmd/options"
+	"github.com/zoomoid/assignments/v1/internal/config"
 	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/deadline"
 	"github.com/zoomoid/assignments/v1/internal/template"
+	"github.com/zoomoid/assignments/v1/internal/trash"
 	"github.com/zoomoid/assignments/v1/internal/util"
 
 	"github.com/lithammer/dedent"
@@ -38,16 +42,19 @@ import (
 
 var (
 	generateLongDescription = dedent.Dedent(`
-		The command generates a new assignment, either given by number
+		The command generates a new assignment, either given by its ID
 		as an argument to the command, or otherwise from the local 
 		configuration file, which keeps track of the upstream assignment.
+		IDs are numbers, optionally followed by a letter for sheets in
+		several parts, e.g., 5a, or names, e.g., bonus.
 
 		Generating (or templating) a new assignment requires a due date.
 		As this is usually given, you can either use the --due flag, or
 		wait for the CLI to prompt you. If however the due date is *not*
 		provided by the assignment, just pressing ENTER during the prompt
 		will leave it empty and thus not printed in the assignment's
-		header.
+		header. Due dates from the assignment's assignment.yaml or recorded
+		in .status.assignments are used without prompting.
 		
 		You can make the command skip incrementing the status counter in
 		the local configuration file by passing the --no-increment flag.
@@ -57,32 +64,11 @@ var (
 		--force flag, any files in the target directory will be overriden.
 		Be careful!
 		
-		The default template for new assignments looks like this:
-		
-		\documentclass{csassignments}
-		{{- range $_, $input := .Includes -}}
-		\input{ {{- $input -}} }
-		{{ end }}
-		\course{ {{- .Course -}} }
-		\group{ {{- .Group | default "" -}} }
-		\sheet{ {{- .Sheet | default "" -}} }
-		\due{ {{- .Due | default "" -}} }
-		{{- range $_, $member := .Members }}
-		{{- $firstname := ($member.Name | splitList " " | initial | join " ") | default "" -}}
-		{{- $lastname := ($member.Name | splitList " " | last) | default "" -}} 
-		\member{ {{- $firstname -}} }{ {{- $lastname -}} }{ {{- $member.ID -}} }
-		{{ end }}
-		\begin{document}
-		\maketitle
-		\gradingtable
-		
-		% Start the assignment here
-		
-		\end{document}
-		
 		You can provide your own template from the configuration file, by 
 		setting .spec.template to a Golang template. You can use any Sprig 
-		template function in your custom template.
+		template function in your custom template. Template packs, exercise
+		scaffolding, and the data and functions available to templates are
+		described in the README.
 		
 		The command creates a new directory from the current assignment number,
 		as well as all directories defined in the .spec.generate.create list.
@@ -90,16 +76,26 @@ var (
 )
 
 type generateData struct {
-	noIncrement bool
-	force       bool
-	due         string
+	noIncrement    bool
+	force          bool
+	due            string
+	exercises      int
+	points         []float64
+	splitExercises bool
+	template       string
+	data           map[string]string
 }
 
 func newGenerateData() *generateData {
 	return &generateData{
-		noIncrement: false,
-		force:       false,
-		due:         "",
+		noIncrement:    false,
+		force:          false,
+		due:            "",
+		exercises:      0,
+		points:         []float64{},
+		splitExercises: false,
+		template:       "",
+		data:           map[string]string{},
 	}
 }
 
@@ -125,77 +121,52 @@ func NewGenerateCommand(ctx *context.AppContext, data *generateData) *cobra.Comm
 		ValidArgsFunction: cobra.NoFileCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
 
-			// take the *next* assignment number
-			assignmentNo := ctx.Configuration.Status.Assignment + 1
-
+			var assignmentNo config.AssignmentID
 			fromArgs := false
 			if len(args) != 0 {
 				// update configuration status
-				i, err := strconv.Atoi(args[0])
+				i, err := ctx.Naming().Argument(args[0])
 				if err == nil && !data.noIncrement {
 					fromArgs = true
-					assignmentNo = uint32(i)
+					assignmentNo = i
 				}
 			}
-
-			due := data.due
-			if data.due == "" {
-				due = promptDueDate()
-			}
-
-			spec := ctx.Configuration.Spec
-
-			var classPath string
-
-			var tpl string
-
-			if spec.Template != "" {
-				tpl = dedent.Dedent(spec.Template)
-			}
-
-			bindings := &template.TemplateBinding{
-				ClassPath: classPath,
-				Course:    spec.Course,
-				Group:     spec.Group,
-				Sheet:     util.AddLeadingZero(assignmentNo),
-				Due:       due,
-				Members:   spec.Members,
-				Includes:  spec.Includes,
-			}
-
-			sheetSource, err := template.GenerateAssignmentTemplate(&tpl, bindings)
-
-			if err != nil {
-				return err
+			if !fromArgs {
+				// take the *next* assignment number
+				next, err := ctx.Configuration.Status.Assignment.Next()
+				if err != nil {
+					return err
+				}
+				assignmentNo = next
 			}
 
-			// create the assignment's main directory
-			assignmentDirectory := fmt.Sprintf("assignment-%s", bindings.Sheet)
-			if data.force {
-				// when using --force to override any existing assignments, clean up before creating
-				// assignment directory from scratch
-				_ = os.RemoveAll(assignmentDirectory)
-			}
-			err = os.Mkdir(assignmentDirectory, 0777)
+			parser, err := newDueParser(ctx)
 			if err != nil {
 				return err
 			}
-
-			// create the additional directories defined in the spec
-			additionalDirectories := []string{}
-			if spec.GenerateOptions != nil {
-				additionalDirectories = spec.GenerateOptions.Create
-			}
-			for _, dir := range additionalDirectories {
-				err = os.Mkdir(filepath.Join(assignmentDirectory, dir), 0777)
+			var due *time.Time
+			if data.due != "" {
+				t, err := parser.Parse(data.due)
+				if err != nil {
+					return err
+				}
+				due = &t
+			} else {
+				// due dates from assignment.yaml, imported from a calendar, or recorded
+				// previously skip the prompt
+				recorded, err := ctx.DueDate(assignmentNo)
 				if err != nil {
 					return err
 				}
+				if recorded != nil {
+					due = recorded
+					log.Info().Msgf("Using recorded due date %s", due.In(parser.Location).Format("Monday, January 2, 2006 15:04 MST"))
+				} else {
+					due = promptDueDate(parser)
+				}
 			}
 
-			file := filepath.Join(assignmentDirectory, "assignment.tex")
-
-			err = os.WriteFile(file, sheetSource.Bytes(), 0644)
+			file, err := generateAssignment(ctx, assignmentNo, due, data)
 			if err != nil {
 				return err
 			}
@@ -217,28 +188,320 @@ func NewGenerateCommand(ctx *context.AppContext, data *generateData) *cobra.Comm
 	return generateCmd
 }
 
+// generateAssignment renders the sources of an assignment into its directory and
+// records its due date and the generated state. It returns the path of the main
+// source file
+func generateAssignment(ctx *context.AppContext, assignmentNo config.AssignmentID, due *time.Time, data *generateData) (string, error) {
+	assignmentDirectory := ctx.Naming().Directory(assignmentNo)
+	if data.force {
+		// keep everything --force is about to replace, before the status changes below
+		snapshot, err := saveSnapshot(ctx, &trash.Snapshot{
+			Operation:  "generate",
+			Assignment: ctx.Naming().ID(assignmentNo),
+			Status:     statusOf(ctx, ctx.Naming().ID(assignmentNo)),
+		}, assignmentDirectory)
+		if err != nil {
+			return "", err
+		}
+		logSnapshot(snapshot)
+	}
+
+	if due != nil {
+		ctx.Configuration.Status.Upsert(ctx.Naming().ID(assignmentNo)).Due = due
+	}
+	setAssignmentData(ctx, assignmentNo, data.data)
+
+	// the assignment's own configuration file, if it already exists, is applied to
+	// everything read from the spec, while the status is still recorded in ctx
+	assignmentCtx, err := ctx.ForAssignment(assignmentDirectory)
+	if err != nil {
+		return "", err
+	}
+	spec := assignmentCtx.Configuration.Spec
+
+	warnOnClassVersionDrift(ctx)
+
+	exerciseDefaults := []config.Exercise{}
+	splitExercises := data.splitExercises
+	if spec.GenerateOptions != nil {
+		exerciseDefaults = spec.GenerateOptions.Exercises
+		splitExercises = splitExercises || spec.GenerateOptions.SplitExercises
+	}
+	exercises, err := template.MakeExercises(exerciseDefaults, data.exercises, data.points, splitExercises)
+	if err != nil {
+		return "", err
+	}
+
+	bindings := makeTemplateBindings(assignmentCtx, assignmentNo, due, exercises)
+	tpl := sheetTemplate(assignmentCtx)
+
+	sheetSource, err := template.GenerateAssignmentTemplate(&tpl, bindings)
+
+	if err != nil {
+		return "", err
+	}
+
+	pack, err := selectTemplatePack(ctx, data.template)
+	if err != nil {
+		return "", err
+	}
+
+	// create the assignment's main directory
+	assignmentConfiguration := filepath.Join(assignmentDirectory, config.AssignmentConfigurationFileName)
+	if data.force {
+		// when using --force to override any existing assignments, clean up before creating
+		// assignment directory from scratch, but keep the assignment's configuration file
+		keep, err := os.ReadFile(assignmentConfiguration)
+		_ = os.RemoveAll(assignmentDirectory)
+		if err == nil {
+			if err := os.Mkdir(assignmentDirectory, 0777); err != nil {
+				return "", err
+			}
+			if err := os.WriteFile(assignmentConfiguration, keep, 0644); err != nil {
+				return "", err
+			}
+		}
+	}
+	err = os.Mkdir(assignmentDirectory, 0777)
+	if err != nil && !(errors.Is(err, fs.ErrExist) && onlyAssignmentConfiguration(assignmentDirectory)) {
+		return "", err
+	}
+
+	// create the additional directories defined in the spec
+	additionalDirectories := []string{}
+	if spec.GenerateOptions != nil {
+		additionalDirectories = spec.GenerateOptions.Create
+	}
+	for _, dir := range additionalDirectories {
+		err = os.Mkdir(filepath.Join(assignmentDirectory, dir), 0777)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	rendered := util.NewSet()
+	if pack != nil {
+		files, err := pack.Render(assignmentDirectory, bindings)
+		if err != nil {
+			return "", fmt.Errorf("failed to render template pack %s, %w", pack.Name, err)
+		}
+		rendered.Insert(files...)
+		log.Debug().Strs("files", files).Msgf("Rendered template pack %s", pack.Name)
+	}
+
+	file := filepath.Join(assignmentDirectory, "assignment.tex")
+
+	if !rendered.Has("assignment.tex") {
+		err = os.WriteFile(file, sheetSource.Bytes(), 0644)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	for _, exercise := range exercises {
+		if exercise.File == "" || rendered.Has(exercise.File) {
+			continue
+		}
+		exerciseSource, err := template.GenerateExerciseTemplate(&exercise)
+		if err != nil {
+			return "", err
+		}
+		err = os.WriteFile(filepath.Join(assignmentDirectory, exercise.File), exerciseSource.Bytes(), 0644)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	recordTransition(ctx, ctx.Naming().ID(assignmentNo), config.StateGenerated, "")
+	return file, nil
+}
+
 func addGenerateFlags(flags *pflag.FlagSet, data *generateData) {
 	flags.BoolVar(&data.noIncrement, options.NoIncrement, false, "Skip incrementing assignment number in configuration")
 	flags.BoolVarP(&data.force, options.Force, options.ForceShort, false, "Overrides any existing assignment source files")
-	flags.StringVar(&data.due, options.Due, "", "Due date of the assignment to generate. If not provided, you'll be prompted for a due date")
+	flags.StringVar(&data.due, options.Due, "", "Due date of the assignment to generate, e.g., \"next friday 23:59\" or \"+7d\". If not provided, you'll be prompted for a due date")
+	flags.IntVar(&data.exercises, options.Exercises, 0, "Number of exercise skeletons to add to the assignment")
+	flags.Float64SliceVar(&data.points, options.Points, []float64{}, "Comma-separated points per exercise, e.g. 10,10,15,5")
+	flags.BoolVar(&data.splitExercises, options.SplitExercises, false, "Render each exercise into its own exercise-N.tex file")
+	flags.StringVarP(&data.template, options.Template, options.TemplateShort, "", "Name of the template pack in .assignments/templates/ to generate the assignment from")
+	flags.StringToStringVar(&data.data, options.Data, map[string]string{}, "Custom template data for this assignment as key=value pairs, overriding .spec.data")
 }
 
 func addGenerateFlagsCommand(cmd *cobra.Command) {
 	cmd.RegisterFlagCompletionFunc(options.NoIncrement, cobra.NoFileCompletions)
 	cmd.RegisterFlagCompletionFunc(options.Force, cobra.NoFileCompletions)
 	cmd.RegisterFlagCompletionFunc(options.Due, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Exercises, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Points, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.SplitExercises, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Data, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Template, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		pwd, err := os.Getwd()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		root, err := config.Find(pwd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		packs, err := template.Packs(root)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return packs, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// onlyAssignmentConfiguration returns true if the directory contains nothing but an
+// assignment configuration file, which may be created before generating the
+// assignment
+func onlyAssignmentConfiguration(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	return len(entries) == 1 && entries[0].Name() == config.AssignmentConfigurationFileName
 }
 
-func promptDueDate() string {
-	ts := time.Now()
-	exampleDate := fmt.Sprintf("%s %d, %d", ts.Month().String(), ts.Day(), ts.Year())
+// makeTemplateBindings collects the data available to the assignment's sheet
+// template and to template packs from the configuration, which should have the
+// assignment's configuration file applied, see context.AppContext.ForAssignment.
+// If due is nil, the due date recorded for the assignment is used
+func makeTemplateBindings(ctx *context.AppContext, assignmentNo config.AssignmentID, due *time.Time, exercises []template.Exercise) *template.TemplateBinding {
+	spec := ctx.Configuration.Spec
+	sheet := ctx.Naming().ID(assignmentNo)
+	assignmentDirectory := ctx.Naming().Name(sheet)
+
+	title := ""
+	if a, err := config.ReadAssignment(filepath.Join(ctx.Root, assignmentDirectory)); err == nil && a != nil {
+		title = a.Title
+	}
 
-	fmt.Printf("⏱️  When is the assignment due? (e.g.,'%s'): ", exampleDate)
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
+	var overrides map[string]interface{}
+	if status := ctx.Configuration.Status.Lookup(sheet); status != nil {
+		overrides = status.Data
+		if due == nil {
+			due = status.Due
+		}
+	}
+
+	return &template.TemplateBinding{
+		Course:    spec.Course,
+		Group:     spec.Group,
+		Sheet:     sheet,
+		Due:       formatDueDate(ctx, due),
+		DueDate:   due,
+		Members:   spec.Roster(assignmentNo),
+		Includes:  spec.Includes,
+		Exercises: exercises,
+		Data:      config.MergeData(spec.Data, overrides),
+		Spec:      spec,
+		Assignment: template.Assignment{
+			Number:    assignmentNo.Number,
+			Suffix:    assignmentNo.Suffix,
+			Name:      assignmentNo.Name,
+			ID:        sheet,
+			Directory: assignmentDirectory,
+			Title:     title,
+		},
+	}
+}
+
+// setAssignmentData records data overrides given as flags in the assignment's
+// metadata, such that they persist for subsequent renderings
+func setAssignmentData(ctx *context.AppContext, assignmentNo config.AssignmentID, data map[string]string) {
+	if len(data) == 0 {
+		return
+	}
+	status := ctx.Configuration.Status.Upsert(ctx.Naming().ID(assignmentNo))
+	if status.Data == nil {
+		status.Data = make(map[string]interface{})
+	}
+	for k, v := range data {
+		status.Data[k] = v
+	}
+}
+
+// sheetTemplate returns the custom sheet template from the configuration, or the
+// empty string, in which case the default template is used
+func sheetTemplate(ctx *context.AppContext) string {
+	if ctx.Configuration.Spec.Template == "" {
+		return ""
+	}
+	return dedent.Dedent(ctx.Configuration.Spec.Template)
+}
+
+// selectTemplatePack picks the template pack from the flag, the configuration, or
+// the default pack, in that order. Returns nil if no pack is selected and there is
+// no default pack, in which case only the main source file is generated
+func selectTemplatePack(ctx *context.AppContext, name string) (*template.Pack, error) {
+	spec := ctx.Configuration.Spec
+	if name == "" && spec.GenerateOptions != nil {
+		name = spec.GenerateOptions.Template
+	}
+	if name != "" {
+		return template.LoadPack(ctx.Root, name)
+	}
+	pack, err := template.LoadPack(ctx.Root, template.DefaultPackName)
 	if err != nil {
-		log.Fatal().Err(err)
+		if errors.Is(err, template.ErrPackNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return pack, nil
+}
+
+// promptDueDate asks for a due date until the input parses. Returns nil if the
+// input is left empty
+func promptDueDate(parser *deadline.Parser) *time.Time {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print("⏱️  When is the assignment due? (e.g., 'next friday 23:59', '+7d', or '2006-01-02'): ")
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			log.Fatal().Err(err)
+		}
+		input = strings.TrimSpace(input)
+		if input == "" {
+			return nil
+		}
+		t, err := parser.Parse(input)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Printf("Due %s\n", t.Format("Monday, January 2, 2006 15:04 MST"))
+		return &t
+	}
+}
+
+// newDueParser creates a parser for due dates in the course's timezone
+func newDueParser(ctx *context.AppContext) (*deadline.Parser, error) {
+	opts := ctx.Configuration.Spec.DueOptions
+	if opts == nil {
+		return deadline.NewParser("", "")
+	}
+	return deadline.NewParser(opts.Timezone, opts.Time)
+}
+
+// formatDueDate formats a due date in the course's timezone with the layout from
+// the configuration, or the locale's date layout. Returns the empty string for nil
+func formatDueDate(ctx *context.AppContext, due *time.Time) string {
+	if due == nil {
+		return ""
+	}
+	spec := ctx.Configuration.Spec
+	locale := template.LookupLocale(spec.Locale)
+	layout := locale.DateLayout
+	t := *due
+	if opts := spec.DueOptions; opts != nil {
+		if opts.Layout != "" {
+			layout = opts.Layout
+		}
+		if loc, err := deadline.Location(opts.Timezone); err == nil {
+			t = t.In(loc)
+		}
 	}
-	input = strings.TrimSuffix(input, "\n")
-	return input
+	return locale.Format(t, layout)
 }
@@ -0,0 +1,152 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zoomoid/assignments/v1/cmd/options"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/deadline"
+	"github.com/zoomoid/assignments/v1/internal/inventory"
+)
+
+var (
+	listLongDescription = dedent.Dedent(`
+		The command shows all assignments of the repository at a glance. It
+		scans the assignment directories, the PDFs and archives in ./dist/,
+		and the git tags of the repository, and prints for each assignment
+
+		  STATE     the lifecycle state, see "assignmentctl mark --help"
+		  DUE       the due date recorded when generating the assignment
+		  BUILD     whether the PDF in ./dist/ is missing, stale (i.e., any
+		            source file was modified after the build), or fresh
+		  BUNDLE    the archives in ./dist/, named after .spec.bundle.template
+		  RELEASE   the git tag named after the assignment, e.g., assignment-03,
+		            if the assignment is tagged
+
+		Pass -o json for machine-readable output.
+	`)
+)
+
+const (
+	outputFormatTable string = "table"
+	outputFormatJSON  string = "json"
+)
+
+type listData struct {
+	output string
+}
+
+func newListData() *listData {
+	return &listData{
+		output: outputFormatTable,
+	}
+}
+
+func NewListCommand(ctx *context.AppContext, data *listData) *cobra.Command {
+	if data == nil {
+		data = newListData()
+	}
+
+	listCmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"status", "ls"},
+		Short:   "Show all assignments with their due date, build, bundle, and release status",
+		Long:    listLongDescription,
+		Args:    cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			assignments, err := inventory.Scan(ctx)
+			if err != nil {
+				return err
+			}
+
+			switch data.output {
+			case outputFormatJSON:
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(assignments)
+			case outputFormatTable:
+				return printAssignmentTable(cmd.OutOrStdout(), ctx, assignments)
+			default:
+				return fmt.Errorf("unknown output format %q, use one of %s, %s", data.output, outputFormatTable, outputFormatJSON)
+			}
+		},
+	}
+
+	addListFlags(listCmd.PersistentFlags(), data)
+	addListFlagsCompletion(listCmd)
+
+	return listCmd
+}
+
+func printAssignmentTable(out io.Writer, ctx *context.AppContext, assignments []*inventory.Assignment) error {
+	loc := courseLocation(ctx)
+	now := time.Now()
+
+	w := tabwriter.NewWriter(out, 0, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "ASSIGNMENT\tSTATE\tDUE\tBUILD\tBUNDLE\tRELEASE")
+	for _, a := range assignments {
+		due := "-"
+		if a.Due != nil {
+			due = fmt.Sprintf("%s (%s)", a.Due.In(loc).Format("Mon 2006-01-02 15:04"), deadline.Humanize(*a.Due, now))
+		}
+		bundled := "-"
+		if a.Bundled() {
+			names := []string{}
+			for _, archive := range a.Archives {
+				names = append(names, strings.TrimPrefix(archive, "dist/"))
+			}
+			bundled = strings.Join(names, ", ")
+		}
+		release := "-"
+		if a.Released() {
+			release = a.Tag
+		}
+		state := "-"
+		if a.State != "" {
+			state = string(a.State)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", a.ID, state, due, a.Build, bundled, release)
+	}
+	return w.Flush()
+}
+
+func addListFlags(flags *pflag.FlagSet, data *listData) {
+	flags.StringVarP(&data.output, options.Output, options.OutputShort, outputFormatTable, "Output format, either table or json")
+}
+
+func addListFlagsCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc(options.Output, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{outputFormatTable, outputFormatJSON}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/inventory"
+	"github.com/zoomoid/assignments/v1/internal/util"
+)
+
+var (
+	markLongDescription = dedent.Dedent(`
+		Every assignment moves through the lifecycle
+
+		  generated → in-progress → built → bundled → submitted → graded
+
+		generate, build, and bundle record their states automatically in
+		.status.assignments, including a timestamp and, for built and bundled,
+		the SHA-256 checksum of the PDF or archive. As the configuration file
+		is committed, this gives your group a shared record of progress.
+
+		Use this command to set the remaining states manually, e.g., after
+		handing in the assignment. Without an assignment number, the current
+		assignment is marked. Only existing assignments can be marked.
+
+		Once an assignment is submitted or graded, rebuilding or rebundling
+		it is still recorded in its history, but does not change its state.
+		Rebuilding an assignment into the same PDF as its last recorded build
+		is not recorded again.
+	`)
+)
+
+func NewMarkCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mark [assignment] <state>",
+		Short: "Set an assignment's lifecycle state, e.g., submitted or graded",
+		Long:  markLongDescription,
+		Args:  cobra.RangeArgs(1, 2),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		PostRun: func(cmd *cobra.Command, args []string) {
+			defer ctx.Write()
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 1 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			states := []string{}
+			for _, s := range config.States {
+				states = append(states, string(s))
+			}
+			return states, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if ctx.Configuration.Status == nil {
+				ctx.Configuration.Status = &config.ConfigurationStatus{}
+			}
+			assignmentNo := ctx.Configuration.Status.Assignment
+			stateArg := args[0]
+			if len(args) == 2 {
+				i, err := ctx.Naming().Argument(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid assignment number %q", args[0])
+				}
+				assignmentNo = i
+				stateArg = args[1]
+			}
+
+			state, err := config.ParseState(stateArg)
+			if err != nil {
+				return err
+			}
+
+			id := ctx.Naming().ID(assignmentNo)
+			a, err := inventory.Find(ctx, assignmentNo)
+			if err != nil {
+				return err
+			}
+			if a == nil {
+				if assignmentNo.IsZero() {
+					return errors.New("there is no current assignment, pass the assignment to mark")
+				}
+				return fmt.Errorf("assignment %s does not exist", id)
+			}
+			ctx.Configuration.Status.Upsert(id).Mark(state, time.Now())
+			log.Info().Msgf("Marked assignment %s as %s", id, state)
+			return nil
+		},
+	}
+	return cmd
+}
+
+// recordTransition records an automatic lifecycle transition of an assignment,
+// including the checksum of the artifact if given. Failing to compute the checksum
+// only logs a warning, as the artifact itself was already produced successfully
+func recordTransition(ctx *context.AppContext, id string, state config.State, artifact string) {
+	checksum := ""
+	if artifact != "" {
+		c, err := util.Checksum(artifact)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to compute checksum of %s", artifact)
+		}
+		checksum = c
+	}
+	ctx.Configuration.Status.Upsert(id).Transition(state, time.Now(), checksum)
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zoomoid/assignments/v1/cmd/options"
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/legacy"
+	"github.com/zoomoid/assignments/v1/internal/trash"
+)
+
+var (
+	migratePyassignmentctlLongDescription = dedent.Dedent(`
+		The command converts the .assignments.rc of the archived pyassignmentctl
+		into an .assignments.yaml next to it. The path is either the .ini file,
+		or the repository containing it, and defaults to the working directory.
+
+		It converts
+
+		  [general] course, group   .spec.course, .spec.group
+		  [members]                 .spec.members, keyed by student ID
+		  [assignments] number      .status.assignment, the current sheet
+
+		pyassignmentctl's fixed behaviour is configured explicitly: archives
+		are named sheet_<ID>_<student IDs>.zip and include code/*, and new
+		assignments get a source/ and a code/ directory. Its latexmk command is
+		the default build recipe already.
+
+		Any setting that cannot be converted is reported, and the command
+		fails afterwards, such that you can check it before deleting the
+		.ini file. The .assignments.yaml is written nonetheless.
+
+		An existing .assignments.yaml is only replaced with --force, after
+		keeping it in a snapshot, see "assignmentctl restore --help".
+
+		Note that new assignments are generated from the csassignments class
+		instead of pyassignmentctl's assignments.cls. Existing assignments
+		still build with the class they reference, or adjust them to use
+		csassignments, see "assignmentctl tex --help".
+	`)
+)
+
+type migrateData struct {
+	force bool
+}
+
+func newMigrateData() *migrateData {
+	return &migrateData{
+		force: false,
+	}
+}
+
+func NewMigrateCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Convert configurations of other tools to .assignments.yaml",
+		Long:  "The command is not meant to be run on its own",
+	}
+
+	cmd.AddCommand(NewMigratePyassignmentctlCommand(ctx, nil))
+
+	return cmd
+}
+
+func NewMigratePyassignmentctlCommand(ctx *context.AppContext, data *migrateData) *cobra.Command {
+	if data == nil {
+		data = newMigrateData()
+	}
+
+	cmd := &cobra.Command{
+		Use:   "pyassignmentctl [path]",
+		Short: "Convert the .assignments.rc of pyassignmentctl to .assignments.yaml",
+		Long:  migratePyassignmentctlLongDescription,
+		Args:  cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveDefault
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := ctx.Cwd
+			if len(args) != 0 {
+				path = args[0]
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(ctx.Cwd, path)
+				}
+			}
+			if info, err := os.Stat(path); err == nil && info.IsDir() {
+				path = filepath.Join(path, legacy.PyassignmentctlFileName)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			ini, err := legacy.ParseINI(f)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s, %w", path, err)
+			}
+			migration := legacy.FromPyassignmentctl(ini)
+
+			root := filepath.Dir(path)
+			target := filepath.Join(root, config.ConfigurationFileName)
+			if _, err := os.Stat(target); err == nil {
+				if !data.force {
+					return fmt.Errorf("%s already exists, pass --force to replace it", target)
+				}
+				store, err := trash.Open(root, nil)
+				if err != nil {
+					return err
+				}
+				snapshot, err := store.Save(&trash.Snapshot{Operation: "migrate"}, config.ConfigurationFileName)
+				if err != nil {
+					return err
+				}
+				logSnapshot(snapshot)
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+
+			ctx.Root = root
+			ctx.Configuration = migration.Configuration
+			if err := ctx.Write(); err != nil {
+				return err
+			}
+			log.Info().Msgf("Converted %s to %s", path, target)
+
+			for _, u := range migration.Unmapped {
+				setting := fmt.Sprintf("[%s]", u.Section)
+				if u.Key != "" {
+					setting = fmt.Sprintf("%s %s = %s (line %d)", setting, u.Key, u.Value, u.Line)
+				}
+				log.Warn().Msgf("Could not convert %s, %s", setting, u.Reason)
+			}
+			if len(migration.Unmapped) > 0 {
+				return fmt.Errorf("%d settings of %s could not be converted", len(migration.Unmapped), path)
+			}
+			return nil
+		},
+	}
+
+	addMigrateFlags(cmd.PersistentFlags(), data)
+	addMigrateFlagsCompletion(cmd)
+
+	return cmd
+}
+
+func addMigrateFlags(flags *pflag.FlagSet, data *migrateData) {
+	flags.BoolVarP(&data.force, options.Force, options.ForceShort, false, "Replace an existing .assignments.yaml")
+}
+
+func addMigrateFlagsCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc(options.Force, cobra.NoFileCompletions)
+}
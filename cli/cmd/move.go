@@ -0,0 +1,172 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zoomoid/assignments/v1/cmd/options"
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/inventory"
+)
+
+var (
+	moveLongDescription = dedent.Dedent(`
+		The command renumbers an assignment, e.g., after generating a sheet
+		with the wrong number. It renames, consistently with the naming scheme,
+
+		  - the assignment's source directory, including its assignment.yaml,
+		  - its PDF and all of its archives in ./dist/,
+		  - its git tag, if it was released, and
+		  - its entry in .status.assignments.
+
+		If the sheet was generated from the default template, \sheet{} in its
+		assignment.tex is updated to the new ID. Custom templates may use the
+		ID anywhere, so check the sources yourself. Either way, the PDF and the
+		archives still show the old ID until you rebuild and rebundle them, and
+		the renamed archives contain the PDF under its old name.
+
+		If the assignment was the current one in .status.assignment, the last
+		numbered assignment remaining afterwards becomes the current one, such
+		that "assignmentctl generate" continues after it.
+
+		Nothing is changed if any of the targets already exists, including an
+		assignment's recorded history under the new ID. Pass --force to
+		overwrite them. The overwritten targets are kept in a snapshot first,
+		such that "assignmentctl undo" restores them.
+
+		Git tags are only renamed locally. Push the new tag and delete the old
+		one from your remote yourself, e.g., with
+
+		  git push origin <new tag> :<old tag>
+	`)
+)
+
+type moveData struct {
+	force bool
+}
+
+func newMoveData() *moveData {
+	return &moveData{
+		force: false,
+	}
+}
+
+func NewMoveCommand(ctx *context.AppContext, data *moveData) *cobra.Command {
+	if data == nil {
+		data = newMoveData()
+	}
+
+	cmd := &cobra.Command{
+		Use:     "mv <assignment> <new assignment>",
+		Aliases: []string{"move"},
+		Short:   "Renumber an assignment including its artifacts, tag, and status",
+		Long:    moveLongDescription,
+		Args:    cobra.ExactArgs(2),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		PostRun: func(cmd *cobra.Command, args []string) {
+			defer ctx.Write()
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return getAssignmentsFromRoot(ctx, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := ctx.Naming().Argument(args[0])
+			if err != nil {
+				return err
+			}
+			to, err := ctx.Naming().Argument(args[1])
+			if err != nil {
+				return err
+			}
+
+			current := ctx.Configuration.Status.Assignment
+			result, err := inventory.Move(ctx, from, to, data.force)
+			if result != nil {
+				logSnapshot(result.Snapshot)
+			}
+			logChanges(result)
+			if err != nil {
+				return err
+			}
+			if result.Patched != "" {
+				log.Info().Msgf("Updated \\sheet{} in %s", result.Patched)
+			}
+			logCurrentAssignment(ctx, current)
+			log.Info().Msgf("Moved assignment %s to %s", ctx.Naming().ID(from), ctx.Naming().ID(to))
+			return nil
+		},
+	}
+
+	addMoveFlags(cmd.PersistentFlags(), data)
+	addMoveFlagsCompletion(cmd)
+
+	return cmd
+}
+
+func addMoveFlags(flags *pflag.FlagSet, data *moveData) {
+	flags.BoolVarP(&data.force, options.Force, options.ForceShort, false, "Overwrite existing files, tags, and status of the new assignment")
+}
+
+func addMoveFlagsCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc(options.Force, cobra.NoFileCompletions)
+}
+
+// logChanges logs the files and tags moved or removed, also if the operation failed
+// halfway, such that the repository's state can be reconstructed
+func logChanges(result *inventory.Result) {
+	if result == nil {
+		return
+	}
+	for _, c := range result.Changes {
+		switch {
+		case c.Tag && c.To != "":
+			log.Info().Msgf("Renamed local tag %s to %s", c.From, c.To)
+		case c.Tag:
+			log.Info().Msgf("Deleted local tag %s", c.From)
+		case c.To != "":
+			log.Info().Msgf("Renamed %s to %s", c.From, c.To)
+		default:
+			log.Info().Msgf("Removed %s", c.From)
+		}
+	}
+}
+
+// logCurrentAssignment logs if the current assignment in .status.assignment is no
+// longer the one before
+func logCurrentAssignment(ctx *context.AppContext, before config.AssignmentID) {
+	after := ctx.Configuration.Status.Assignment
+	if after == before {
+		return
+	}
+	if after.IsZero() {
+		log.Info().Msg("There is no current assignment anymore")
+		return
+	}
+	log.Info().Msgf("Assignment %s is the current assignment now", ctx.Naming().ID(after))
+}
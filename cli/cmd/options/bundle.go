@@ -19,4 +19,5 @@ package options
 const (
 	Tar  string = "tar"
 	Gzip string = "gzip"
+	Late string = "late"
 )
@@ -17,6 +17,12 @@ limitations under the License.
 package options
 
 const (
-	NoIncrement string = "no-increment"
-	Due         string = "due"
+	NoIncrement    string = "no-increment"
+	Due            string = "due"
+	Exercises      string = "exercises"
+	Points         string = "points"
+	SplitExercises string = "split-exercises"
+	Template       string = "template"
+	TemplateShort  string = "t"
+	Data           string = "data"
 )
@@ -25,4 +25,5 @@ const (
 	QuietShort   string = "q"
 	Verbose      string = "verbose"
 	VerboseShort string = "v"
+	Strict       string = "strict"
 )
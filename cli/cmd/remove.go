@@ -0,0 +1,120 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zoomoid/assignments/v1/cmd/options"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/inventory"
+)
+
+var (
+	removeLongDescription = dedent.Dedent(`
+		The command removes an assignment, i.e., its source directory, its PDF
+		and all of its archives in ./dist/, and its entry in
+		.status.assignments.
+
+		Assignments that were already submitted or graded, or that were
+		released with a git tag, are only removed with --force, which then also
+		deletes the local git tag. Delete it from your remote yourself, e.g.,
+		with
+
+		  git push origin :<tag>
+
+		If the assignment was the current one in .status.assignment, the last
+		numbered assignment remaining afterwards becomes the current one, such
+		that "assignmentctl generate" creates the removed assignment anew.
+
+		Everything removed is kept in a snapshot first, see "assignmentctl
+		restore --help". Undo the removal with "assignmentctl undo".
+	`)
+)
+
+type removeData struct {
+	force bool
+}
+
+func newRemoveData() *removeData {
+	return &removeData{
+		force: false,
+	}
+}
+
+func NewRemoveCommand(ctx *context.AppContext, data *removeData) *cobra.Command {
+	if data == nil {
+		data = newRemoveData()
+	}
+
+	cmd := &cobra.Command{
+		Use:     "rm <assignment>",
+		Aliases: []string{"remove"},
+		Short:   "Remove an assignment including its artifacts and status",
+		Long:    removeLongDescription,
+		Args:    cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		PostRun: func(cmd *cobra.Command, args []string) {
+			defer ctx.Write()
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return getAssignmentsFromRoot(ctx, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := ctx.Naming().Argument(args[0])
+			if err != nil {
+				return err
+			}
+
+			current := ctx.Configuration.Status.Assignment
+			result, err := inventory.Remove(ctx, id, data.force)
+			if result != nil {
+				logSnapshot(result.Snapshot)
+			}
+			logChanges(result)
+			if err != nil {
+				return err
+			}
+			logCurrentAssignment(ctx, current)
+			log.Info().Msgf("Removed assignment %s", ctx.Naming().ID(id))
+			return nil
+		},
+	}
+
+	addRemoveFlags(cmd.PersistentFlags(), data)
+	addRemoveFlagsCompletion(cmd)
+
+	return cmd
+}
+
+func addRemoveFlags(flags *pflag.FlagSet, data *removeData) {
+	flags.BoolVarP(&data.force, options.Force, options.ForceShort, false, "Remove submitted, graded, or released assignments, including their local git tag")
+}
+
+func addRemoveFlagsCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc(options.Force, cobra.NoFileCompletions)
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/deadline"
+	"github.com/zoomoid/assignments/v1/internal/inventory"
+	"github.com/zoomoid/assignments/v1/internal/trash"
+)
+
+var (
+	restoreLongDescription = dedent.Dedent(`
+		Before files are overwritten or deleted, a copy of them is kept in a
+		snapshot in .assignments/trash/, which ignores itself in git. Snapshots
+		are taken by
+
+		  generate --force   the assignment's directory and status
+		  rm                 everything removed, including status and git tag
+		  mv --force         the overwritten targets
+		  bundle --force     the overwritten archive
+		  build              files deleted by custom cleanup glob patterns,
+		                     except for LaTeX's byproducts, e.g., *.aux
+
+		Without arguments, the command lists all snapshots, newest first. Given
+		a snapshot's name, it copies the snapshot's files back to where they
+		were and restores the assignment's entry in .status.assignments and
+		any deleted git tags. Files that exist in the meantime are replaced,
+		after keeping them in a new snapshot themselves, so restoring can be
+		undone as well. The restored snapshot is removed from the trash.
+
+		"assignmentctl undo" restores the newest snapshot. Running it twice
+		thus redoes the operation.
+
+		Snapshots are deleted after .spec.trash.retention (default 30d, "none"
+		keeps them forever). Set .spec.trash.keep to limit the number of
+		snapshots, deleting the oldest ones first:
+
+		  trash:
+		    retention: 14d
+		    keep: 20
+	`)
+)
+
+func NewRestoreCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore [snapshot]",
+		Short: "List snapshots taken before destructive operations, or restore one",
+		Long:  restoreLongDescription,
+		Args:  cobra.MaximumNArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		PostRun: func(cmd *cobra.Command, args []string) {
+			defer ctx.Write()
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return getSnapshotsFromRoot(ctx), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := trash.Open(ctx.Root, ctx.Configuration.Spec.TrashOptions)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				snapshots, err := store.List()
+				if err != nil {
+					return err
+				}
+				if len(snapshots) == 0 {
+					log.Info().Msg("The trash is empty")
+					return nil
+				}
+				return printSnapshotTable(cmd.OutOrStdout(), snapshots, time.Now())
+			}
+			snapshot, err := store.Get(args[0])
+			if err != nil {
+				return err
+			}
+			return restoreSnapshot(ctx, store, snapshot)
+		},
+	}
+	return cmd
+}
+
+func NewUndoCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Restore the files overwritten or deleted by the last destructive operation",
+		Long:  restoreLongDescription,
+		Args:  cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		PostRun: func(cmd *cobra.Command, args []string) {
+			defer ctx.Write()
+		},
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := trash.Open(ctx.Root, ctx.Configuration.Spec.TrashOptions)
+			if err != nil {
+				return err
+			}
+			snapshot, err := store.Latest()
+			if err != nil {
+				return err
+			}
+			return restoreSnapshot(ctx, store, snapshot)
+		},
+	}
+	return cmd
+}
+
+// restoreSnapshot restores the snapshot's files, status, and tags, and logs what
+// was replaced in turn
+func restoreSnapshot(ctx *context.AppContext, store *trash.Store, snapshot *trash.Snapshot) error {
+	backup, err := store.Restore(snapshot, ctx.Configuration.Status)
+	logSnapshot(backup)
+	if err != nil {
+		return err
+	}
+	for _, p := range snapshot.Paths {
+		if p == config.ConfigurationFileName {
+			// the restored configuration replaces the one read before, which is
+			// written back afterwards
+			if err := ctx.Read(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := inventory.RestoreTags(ctx.Root, snapshot); err != nil {
+		return err
+	}
+	log.Info().Msgf("Restored %s from snapshot %s", describeSnapshot(snapshot), snapshot.Name)
+	return nil
+}
+
+// saveSnapshot keeps the paths, relative to the repository's root, and the
+// snapshot's status in the trash, see trash.Store.Save
+func saveSnapshot(ctx *context.AppContext, snapshot *trash.Snapshot, paths ...string) (*trash.Snapshot, error) {
+	store, err := trash.Open(ctx.Root, ctx.Configuration.Spec.TrashOptions)
+	if err != nil {
+		return nil, err
+	}
+	return store.Save(snapshot, paths...)
+}
+
+// statusOf returns a copy of the assignment's status for a snapshot, or nil if
+// there is none
+func statusOf(ctx *context.AppContext, id string) map[string]*config.AssignmentStatus {
+	status := ctx.Configuration.Status.Lookup(id)
+	if status == nil {
+		return nil
+	}
+	return map[string]*config.AssignmentStatus{id: status.Clone()}
+}
+
+// logSnapshot logs the snapshot taken before a destructive operation, if any
+func logSnapshot(snapshot *trash.Snapshot) {
+	if snapshot == nil {
+		return
+	}
+	log.Info().Msgf("Kept %s in snapshot %s, restore with \"assignmentctl undo\"", describeSnapshot(snapshot), snapshot.Name)
+}
+
+// describeSnapshot lists the paths, status entries, and tags in a snapshot
+func describeSnapshot(snapshot *trash.Snapshot) string {
+	contents := append([]string{}, snapshot.Paths...)
+	for id := range snapshot.Status {
+		contents = append(contents, fmt.Sprintf("status of assignment %s", id))
+	}
+	for tag := range snapshot.Tags {
+		contents = append(contents, "tag "+tag)
+	}
+	sort.Strings(contents[len(snapshot.Paths):])
+	if len(contents) == 0 {
+		return "nothing"
+	}
+	return strings.Join(contents, ", ")
+}
+
+func printSnapshotTable(out io.Writer, snapshots []*trash.Snapshot, now time.Time) error {
+	w := tabwriter.NewWriter(out, 0, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "SNAPSHOT\tOPERATION\tASSIGNMENT\tTAKEN\tCONTENTS")
+	for _, s := range snapshots {
+		assignment := "-"
+		if s.Assignment != "" {
+			assignment = s.Assignment
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Name, s.Operation, assignment, deadline.Humanize(s.Created, now), describeSnapshot(s))
+	}
+	return w.Flush()
+}
+
+// getSnapshotsFromRoot lists the names of all snapshots for completion
+func getSnapshotsFromRoot(ctx *context.AppContext) []string {
+	if ctx.Configuration == nil {
+		if err := ctx.Read(); err != nil {
+			return nil
+		}
+	}
+	store, err := trash.Open(ctx.Root, nil)
+	if err != nil {
+		return nil
+	}
+	snapshots, err := store.List()
+	if err != nil {
+		return nil
+	}
+	ret := make([]string, 0, len(snapshots))
+	for _, s := range snapshots {
+		ret = append(ret, fmt.Sprintf("%s\t%s", s.Name, describeSnapshot(s)))
+	}
+	return ret
+}
@@ -41,6 +41,25 @@ var (
 			--member "Erika Mustermann;$ID2" \
 			--includes "code,feedback"
 
+		# Rename the sheets of an existing course directory to the naming scheme
+		assignmentctl adopt
+
+		# Convert the configuration of the archived pyassignmentctl
+		assignmentctl migrate pyassignmentctl .assignments.rc
+
+		# Upgrade .assignments.yaml to the current configuration schema
+		assignmentctl config migrate
+
+		# Report typos and other mistakes in .assignments.yaml
+		assignmentctl config validate
+
+		# Change the configuration from scripts
+		assignmentctl config set status.assignment 7
+		assignmentctl config add spec.members name="Erika Mustermann",id=123456
+
+		# Edit .assignments.yaml and validate it on save
+		assignmentctl config edit
+
 		# Generate a fresh assignment for the next number
 		assignmentctl generate --due "$DUE_DATE"
 
@@ -67,6 +86,45 @@ var (
 
 		# Create a template Github Action file
 		assignmentctl ci bootstrap github -f workflow.yml
+
+		# Install the csassignments class shipped with the CLI into the repository
+		assignmentctl tex install --local
+
+		# Preview the sheet of assignment 5 without generating it
+		assignmentctl template render sheet --assignment 5
+
+		# Check all templates for typos and undefined fields
+		assignmentctl template validate
+
+		# Show all assignments and whether they are built, bundled, or released
+		assignmentctl list
+
+		# Record that assignment 5 was handed in
+		assignmentctl mark 5 submitted
+
+		# Renumber assignment 4, which should have been assignment 5
+		assignmentctl mv 4 5
+
+		# Remove assignment 6 including its PDF and archives
+		assignmentctl rm 6
+
+		# Restore what the last rm, generate --force, or bundle --force replaced
+		assignmentctl undo
+
+		# List all snapshots in the trash
+		assignmentctl restore
+
+		# Open the interactive dashboard
+		assignmentctl ui
+
+		# Export all deadlines to a calendar file
+		assignmentctl calendar export -f deadlines.ics
+
+		# Import all deadlines from the course's calendar
+		assignmentctl calendar import course.ics
+
+		# Show the upcoming deadlines
+		assignmentctl due
 	`)
 )
 
@@ -86,6 +144,7 @@ type rootData struct {
 	root    string
 	cwd     string
 	verbose bool
+	strict  bool
 }
 
 func NewRootCommand() *cobra.Command {
@@ -122,6 +181,7 @@ func NewRootCommand() *cobra.Command {
 				zerolog.SetGlobalLevel(zerolog.InfoLevel)
 			}
 			ctx.Verbose = data.verbose
+			ctx.Strict = data.strict
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("assignments requires a subcommand to run")
@@ -129,12 +189,27 @@ func NewRootCommand() *cobra.Command {
 	}
 
 	rootCmd.PersistentFlags().BoolVarP(&data.verbose, options.Verbose, options.VerboseShort, false, "Sets logging verbosity level to high")
+	rootCmd.PersistentFlags().BoolVar(&data.strict, options.Strict, false, "Reject unknown fields in .assignments.yaml instead of ignoring them")
 
 	rootCmd.AddCommand(NewBootstrapCommand(ctx, nil))
+	rootCmd.AddCommand(NewAdoptCommand(ctx, nil))
+	rootCmd.AddCommand(NewMigrateCommand(ctx))
+	rootCmd.AddCommand(NewConfigCommand(ctx))
 	rootCmd.AddCommand(NewGenerateCommand(ctx, nil))
 	rootCmd.AddCommand(NewBuildCommand(ctx, nil))
 	rootCmd.AddCommand(NewBundleCommand(ctx, nil))
 	rootCmd.AddCommand(NewCiCommand(ctx))
+	rootCmd.AddCommand(NewTexCommand(ctx))
+	rootCmd.AddCommand(NewTemplateCommand(ctx))
+	rootCmd.AddCommand(NewListCommand(ctx, nil))
+	rootCmd.AddCommand(NewMarkCommand(ctx))
+	rootCmd.AddCommand(NewMoveCommand(ctx, nil))
+	rootCmd.AddCommand(NewRemoveCommand(ctx, nil))
+	rootCmd.AddCommand(NewUndoCommand(ctx))
+	rootCmd.AddCommand(NewRestoreCommand(ctx))
+	rootCmd.AddCommand(NewUiCommand(ctx))
+	rootCmd.AddCommand(NewCalendarCommand(ctx))
+	rootCmd.AddCommand(NewDueCommand(ctx, nil))
 	addShellCompletionSubcommand(rootCmd)
 
 	return rootCmd
@@ -0,0 +1,308 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zoomoid/assignments/v1/cmd/options"
+	"github.com/zoomoid/assignments/v1/internal/bundle"
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/template"
+)
+
+var (
+	templateRenderLongDescription = dedent.Dedent(`
+		The command renders one of the configured templates with the same data
+		that generate and bundle use and prints the result to stdout, without
+		writing any files or changing the configuration.
+
+		"sheet" (the default) renders the assignment's main source file from
+		.spec.template, or from the default template if none is configured.
+		Unless --assignment is given, the sheet of the assignment that generate
+		would create next is rendered. Data overrides passed with --data are
+		only used for the preview and not recorded.
+
+		"bundle" renders the archive name from .spec.bundle.template for the
+		current assignment, or the one given by --assignment. Pass --tar and
+		--gzip to see the name for the respective backend.
+	`)
+
+	templateValidateLongDescription = dedent.Dedent(`
+		The command parses all configured templates, namely .spec.template,
+		.spec.bundle.template, and all files in the template packs in
+		.assignments/templates/, and reports syntax errors as well as references
+		to fields that are not defined for the respective template, each with
+		its line and column.
+
+		The check is static, so it cannot catch everything that fails during
+		execution, e.g., fields of values returned by template functions.
+	`)
+)
+
+const (
+	templateKindSheet  string = "sheet"
+	templateKindBundle string = "bundle"
+)
+
+type templateRenderData struct {
+	assignment string
+	due        string
+	tar        bool
+	gzip       bool
+	data       map[string]string
+}
+
+func newTemplateRenderData() *templateRenderData {
+	return &templateRenderData{
+		assignment: "",
+		due:        "",
+		tar:        false,
+		gzip:       false,
+		data:       map[string]string{},
+	}
+}
+
+func NewTemplateCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "Render and validate the sheet and bundle templates",
+		Long:  "The command is not meant to be run on its own",
+	}
+
+	cmd.AddCommand(NewTemplateRenderCommand(ctx, nil))
+	cmd.AddCommand(NewTemplateValidateCommand(ctx))
+
+	return cmd
+}
+
+func NewTemplateRenderCommand(ctx *context.AppContext, data *templateRenderData) *cobra.Command {
+	if data == nil {
+		data = newTemplateRenderData()
+	}
+
+	cmd := &cobra.Command{
+		Use:       "render [sheet|bundle]",
+		Short:     "Print the rendered sheet template or archive name to stdout",
+		Long:      templateRenderLongDescription,
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{templateKindSheet, templateKindBundle},
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind := templateKindSheet
+			if len(args) != 0 {
+				kind = args[0]
+			}
+
+			switch kind {
+			case templateKindBundle:
+				backend, err := bundleBackend(data.tar, data.gzip)
+				if err != nil {
+					return err
+				}
+				assignmentNo := ctx.Configuration.Status.Assignment
+				if data.assignment != "" {
+					assignmentNo, err = ctx.Naming().Argument(data.assignment)
+					if err != nil {
+						return err
+					}
+				}
+				assignmentCtx, err := ctx.ForAssignment(ctx.Naming().Directory(assignmentNo))
+				if err != nil {
+					return err
+				}
+				tpl, bindings := archiveNameTemplate(assignmentCtx)
+				archiveName, err := bundle.MakeArchiveName(tpl, bundle.ArchiveNameData(bindings, ctx.Naming(), ctx.Naming().ID(assignmentNo), assignmentCtx.Configuration.Spec.Roster(assignmentNo), backend))
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), archiveName)
+			default:
+				var assignmentNo config.AssignmentID
+				var err error
+				if data.assignment != "" {
+					assignmentNo, err = ctx.Naming().Argument(data.assignment)
+				} else {
+					assignmentNo, err = ctx.Configuration.Status.Assignment.Next()
+				}
+				if err != nil {
+					return err
+				}
+				// overrides are only previewed, the configuration is not written back
+				setAssignmentData(ctx, assignmentNo, data.data)
+				assignmentCtx, err := ctx.ForAssignment(ctx.Naming().Directory(assignmentNo))
+				if err != nil {
+					return err
+				}
+				exerciseDefaults := assignmentCtx.Configuration.Spec.GenerateOptions
+				exercises := []template.Exercise{}
+				if exerciseDefaults != nil {
+					e, err := template.MakeExercises(exerciseDefaults.Exercises, 0, nil, exerciseDefaults.SplitExercises)
+					if err != nil {
+						return err
+					}
+					exercises = e
+				}
+				tpl := sheetTemplate(assignmentCtx)
+				var due *time.Time
+				if data.due != "" {
+					parser, err := newDueParser(ctx)
+					if err != nil {
+						return err
+					}
+					t, err := parser.Parse(data.due)
+					if err != nil {
+						return err
+					}
+					due = &t
+				}
+				out, err := template.GenerateAssignmentTemplate(&tpl, makeTemplateBindings(assignmentCtx, assignmentNo, due, exercises))
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(cmd.OutOrStdout(), out.String())
+			}
+			return nil
+		},
+	}
+
+	addTemplateRenderFlags(cmd.PersistentFlags(), data)
+	addTemplateRenderFlagsCompletion(cmd)
+
+	return cmd
+}
+
+func NewTemplateValidateCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check all configured templates for syntax errors and undefined fields",
+		Long:  templateValidateLongDescription,
+		Args:  cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			errs, err := validateTemplates(ctx)
+			if err != nil {
+				return err
+			}
+			for _, e := range errs {
+				fmt.Fprintln(cmd.ErrOrStderr(), e)
+			}
+			if len(errs) > 0 {
+				return fmt.Errorf("found %d problem(s) in templates", len(errs))
+			}
+			log.Info().Msg("All templates are valid")
+			return nil
+		},
+	}
+	return cmd
+}
+
+// validateTemplates statically checks the sheet template, the archive name template,
+// and all files of all template packs. It returns the problems found, and an error
+// only if the templates could not be read
+func validateTemplates(ctx *context.AppContext) ([]error, error) {
+	errs := []error{}
+
+	sheet := sheetTemplate(ctx)
+	if sheet == "" {
+		sheet = template.DefaultSheetTemplate
+	}
+	errs = append(errs, template.Validate(template.SheetTemplateName, sheet, &template.TemplateBinding{})...)
+
+	tpl, bindings := archiveNameTemplate(ctx)
+	if tpl == "" {
+		tpl = bundle.DefaultArchiveNameTemplate
+	}
+	errs = append(errs, template.Validate(bundle.ArchiveNameTemplateName, tpl, bundle.ArchiveNameData(bindings, ctx.Naming(), "", ctx.Configuration.Spec.Members, bundle.BundlerBackendZip))...)
+
+	packs, err := template.Packs(ctx.Root)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range packs {
+		root := filepath.Join(ctx.Root, template.PacksDirectory, name)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path == root {
+				return nil
+			}
+			rel, err := filepath.Rel(filepath.Join(ctx.Root, template.PacksDirectory), path)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			// every path segment is a template on its own, validate each one once
+			errs = append(errs, template.Validate(rel, d.Name(), &template.TemplateBinding{})...)
+			if d.IsDir() {
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if !utf8.Valid(content) {
+				// binary files are copied verbatim
+				return nil
+			}
+			errs = append(errs, template.Validate(rel, string(content), &template.TemplateBinding{})...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return errs, nil
+}
+
+func addTemplateRenderFlags(flags *pflag.FlagSet, data *templateRenderData) {
+	flags.StringVar(&data.assignment, options.Assignment, "", "Assignment to render the template for, e.g., 5, 05b, or bonus")
+	flags.StringVar(&data.due, options.Due, "", "Due date to render into the sheet, defaults to the due date recorded for the assignment")
+	flags.StringToStringVar(&data.data, options.Data, map[string]string{}, "Custom template data as key=value pairs, overriding .spec.data")
+	flags.BoolVar(&data.tar, options.Tar, false, "Render the archive name for the tar backend")
+	flags.BoolVar(&data.gzip, options.Gzip, false, "Render the archive name for the gzip backend. Requires --tar to be specified as well")
+}
+
+func addTemplateRenderFlagsCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc(options.Assignment, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Due, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Data, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Tar, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Gzip, cobra.NoFileCompletions)
+}
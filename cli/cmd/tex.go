@@ -0,0 +1,179 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zoomoid/assignments/v1/cmd/options"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/tex"
+)
+
+var (
+	texInstallLongDescription = dedent.Dedent(`
+		The CLI ships with the version of the csassignments LaTeX class it was
+		built against. The command unpacks the class into either the repository's
+		root (--local, the default) or your personal TEXMFHOME tree (--texmfhome),
+		so that the class used to compile your assignments never drifts from the
+		templates generated by the CLI.
+
+		Next to the generated csassignments.cls, the documented source (.dtx) and
+		the installer (.ins) are written to the same directory. Existing files
+		are replaced.
+
+		When installed locally, "assignmentctl build" adds the repository's root
+		to TEXINPUTS, so no further setup of your LaTeX distribution is required.
+	`)
+
+	texCheckLongDescription = dedent.Dedent(`
+		The command looks for an installed csassignments class, first in the
+		repository's root, then using kpsewhich, and lastly in TEXMFHOME, and
+		compares its version with the version embedded in the CLI.
+
+		The same check runs before generating and building assignments, but only
+		prints a warning there.
+	`)
+)
+
+type texInstallData struct {
+	local     bool
+	texmfHome bool
+}
+
+func newTexInstallData() *texInstallData {
+	return &texInstallData{
+		local:     false,
+		texmfHome: false,
+	}
+}
+
+func NewTexCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tex",
+		Short: "Manage the csassignments LaTeX class shipped with assignmentctl",
+		Long:  "The command is not meant to be run on its own",
+	}
+
+	cmd.AddCommand(NewTexInstallCommand(ctx, nil))
+	cmd.AddCommand(NewTexCheckCommand(ctx))
+
+	return cmd
+}
+
+func NewTexInstallCommand(ctx *context.AppContext, data *texInstallData) *cobra.Command {
+	if data == nil {
+		data = newTexInstallData()
+	}
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the embedded csassignments class into the repository or TEXMFHOME",
+		Long:  texInstallLongDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if data.local && data.texmfHome {
+				return errors.New("cannot use --local together with --texmfhome")
+			}
+
+			var dir string
+			if data.texmfHome {
+				d, err := tex.TexmfHomeDirectory()
+				if err != nil {
+					return fmt.Errorf("failed to determine TEXMFHOME, %w", err)
+				}
+				dir = d
+			} else {
+				if err := ctx.Read(); err != nil {
+					return fmt.Errorf("failed to read config file, %w", err)
+				}
+				dir = ctx.Root
+			}
+
+			files, err := tex.Install(dir)
+			if err != nil {
+				return err
+			}
+			for _, f := range files {
+				log.Debug().Msgf("Wrote %s", f)
+			}
+			log.Info().Msgf("Installed csassignments class %s to %s", tex.Version(), dir)
+			return nil
+		},
+	}
+
+	addTexInstallFlags(cmd.PersistentFlags(), data)
+	addTexInstallFlagsCompletion(cmd)
+
+	return cmd
+}
+
+func NewTexCheckCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Compare the installed csassignments class with the embedded one",
+		Long:  texCheckLongDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// reading the config is optional here, it only narrows down the repository's root
+			_ = ctx.Read()
+
+			p, v, err := tex.InstalledVersion(ctx.Root)
+			if err != nil {
+				if errors.Is(err, tex.ErrNotInstalled) {
+					return fmt.Errorf("%w, run assignmentctl tex install", err)
+				}
+				return err
+			}
+			if v != tex.Version() {
+				return fmt.Errorf("installed class at %s has version %s, but assignmentctl ships %s. Run assignmentctl tex install to update", p, v, tex.Version())
+			}
+			log.Info().Msgf("Installed class at %s matches embedded version %s", p, v)
+			return nil
+		},
+	}
+	return cmd
+}
+
+// warnOnClassVersionDrift logs a warning if the installed class differs from the
+// version embedded in the CLI. A missing class is only logged in verbose mode, as
+// users may very well use a custom template with a different class
+func warnOnClassVersionDrift(ctx *context.AppContext) {
+	p, v, err := tex.InstalledVersion(ctx.Root)
+	if err != nil {
+		log.Debug().Err(err).Msg("Skipping class version check")
+		return
+	}
+	if v != tex.Version() {
+		log.Warn().Msgf("Installed csassignments class at %s has version %s, but assignmentctl ships %s. Run assignmentctl tex install to update", p, v, tex.Version())
+	}
+}
+
+func addTexInstallFlags(flags *pflag.FlagSet, data *texInstallData) {
+	flags.BoolVar(&data.local, options.Local, false, "Install the class into the repository's root (default)")
+	flags.BoolVar(&data.texmfHome, options.TexmfHome, false, "Install the class into TEXMFHOME/tex/latex/csassignments")
+}
+
+func addTexInstallFlagsCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc(options.Local, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.TexmfHome, cobra.NoFileCompletions)
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/zoomoid/assignments/v1/internal/bundle"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/inventory"
+	"github.com/zoomoid/assignments/v1/internal/runner"
+	"github.com/zoomoid/assignments/v1/internal/ui"
+)
+
+var (
+	uiLongDescription = dedent.Dedent(`
+		The command opens a full-screen dashboard of the repository. It shows
+		the same overview as "assignmentctl list", with due dates counting
+		down, and lets you work on the selected assignment with single keys:
+
+		  ↑/↓, k/j   select an assignment
+		  n          generate the next assignment, prompting for its due date
+		  b          build the assignment, overwriting its PDF in ./dist/
+		  z          bundle the assignment to a zip archive in ./dist/,
+		             overwriting an existing archive. Assignments past
+		             their deadline are only bundled by "bundle --late"
+		  l          show the tail of the assignment's last build log
+		  r          rescan the repository
+		  q          quit
+
+		Generating, building, and bundling behave like the respective
+		commands with their default flags, including the configuration in
+		.spec and the recorded status. The configuration file is written
+		after every action.
+
+		Due dates past or within the next two days are highlighted, unless
+		the assignment is already submitted or graded.
+	`)
+)
+
+func NewUiCommand(ctx *context.AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ui",
+		Aliases: []string{"dashboard"},
+		Short:   "Open an interactive dashboard to generate, build, and bundle assignments",
+		Long:    uiLongDescription,
+		Args:    cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			err := ctx.Read()
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to read config file")
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			parser, err := newDueParser(ctx)
+			if err != nil {
+				return err
+			}
+			return ui.Run(ui.New(ctx, parser, uiActions()))
+		},
+	}
+	return cmd
+}
+
+// uiActions implements the dashboard's actions with the same functions as the
+// generate, build, and bundle commands
+func uiActions() ui.Actions {
+	return ui.Actions{
+		Generate: func(ctx *context.AppContext, due *time.Time) (string, error) {
+			assignmentNo, err := ctx.Configuration.Status.Assignment.Next()
+			if err != nil {
+				return "", err
+			}
+			file, err := generateAssignment(ctx, assignmentNo, due, newGenerateData())
+			if err != nil {
+				return "", err
+			}
+			ctx.Configuration.Status.Assignment = assignmentNo
+			return file, nil
+		},
+		Build: func(ctx *context.AppContext, a *inventory.Assignment, out io.Writer) error {
+			return buildAssignment(ctx, &runner.RunnerOptions{
+				TargetDirectory:   a.Directory,
+				Filename:          "assignment.tex",
+				OverrideArtifacts: true,
+				Output:            out,
+			}, false)
+		},
+		Bundle: func(ctx *context.AppContext, a *inventory.Assignment) (string, error) {
+			opts, err := bundlerOptions(ctx, filepath.Base(a.PDF), bundle.BundlerBackendZip, true)
+			if err != nil {
+				return "", err
+			}
+			return bundleAssignment(ctx, opts, false)
+		},
+	}
+}
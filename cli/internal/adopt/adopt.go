@@ -0,0 +1,410 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adopt
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/naming"
+)
+
+const (
+	// MainFile is the name of the main source file that build expects in an
+	// assignment's directory
+	MainFile string = "assignment.tex"
+	// DistDirectory is the directory of built PDFs relative to the repository's root
+	DistDirectory string = "dist"
+)
+
+var (
+	// numberPattern matches numbers in names that do not follow the naming scheme,
+	// together with the letters directly following them, e.g., "5a" in "Blatt5a"
+	numberPattern = regexp.MustCompile(`([0-9]+)([A-Za-z]*)`)
+	// commentPattern matches TeX comments up to the end of the line
+	commentPattern = regexp.MustCompile(`(^|[^\\])%.*`)
+
+	// argument matches a macro's mandatory argument with at most one level of
+	// nested braces, e.g., {M\"{u}ller}
+	argument      = `\s*\{((?:[^{}]|\{[^{}]*\})*)\}`
+	coursePattern = regexp.MustCompile(`\\course` + argument)
+	groupPattern  = regexp.MustCompile(`\\group` + argument)
+	memberPattern = regexp.MustCompile(`\\member\s*(?:\[([^\]]*)\])?` + argument)
+	authorPattern = regexp.MustCompile(`\\author` + argument)
+	andPattern    = regexp.MustCompile(`\\and\b|\\\\`)
+
+	// texUnescaper reverses the escaping of template.TexEscape
+	texUnescaper = strings.NewReplacer(
+		`\textbackslash{}`, `\`,
+		`\textasciitilde{}`, `~`,
+		`\textasciicircum{}`, `^`,
+		`\&`, `&`,
+		`\%`, `%`,
+		`\$`, `$`,
+		`\#`, `#`,
+		`\_`, `_`,
+		`\{`, `{`,
+		`\}`, `}`,
+		`~`, ` `,
+	)
+)
+
+// Rename moves a file or directory, with paths relative to the repository's root
+type Rename struct {
+	From string
+	To   string
+}
+
+// Assignment is an existing directory or PDF recognized as an assignment
+type Assignment struct {
+	ID config.AssignmentID
+	// Directory is the existing source directory relative to the repository's
+	// root, or empty if there only is a PDF
+	Directory string
+	// Main is the name of the main source file in the directory, i.e., the one
+	// with \documentclass and \begin{document}
+	Main string
+	// PDF is the existing PDF relative to the repository's root, if any
+	PDF string
+	// Renames move the directory, the main source file, and the PDF to their
+	// names in the naming scheme, in the order in which they are applied
+	Renames []Rename
+}
+
+// Skipped is a directory or PDF that looks like an assignment, but is not adopted
+type Skipped struct {
+	Path   string
+	Reason string
+}
+
+// Plan is the proposed mapping of an existing course directory to the naming
+// scheme, together with the course's details found in the preambles
+type Plan struct {
+	// Assignments are the recognized assignments, ordered by ID
+	Assignments []*Assignment
+	Skipped     []Skipped
+	// Course, Group, and Members are taken from the newest assignment's preamble
+	// declaring them
+	Course  string
+	Group   string
+	Members []config.GroupMember
+}
+
+// Scan looks for assignments in the top-level directories and PDFs of root and
+// proposes names for them in the naming scheme. A directory is an assignment if
+// its name contains a single number, or matches the naming scheme, and it
+// contains a main source file. Directories without a number are ignored, as they
+// are likely includes, e.g., code/ or feedback/
+func Scan(root string, scheme *naming.Scheme) (*Plan, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{}
+	assignments := map[config.AssignmentID]*Assignment{}
+	skip := func(path string, format string, args ...interface{}) {
+		plan.Skipped = append(plan.Skipped, Skipped{Path: path, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	// directories first, such that PDFs built from their sources take precedence
+	// over loose ones
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || strings.HasPrefix(name, ".") || name == DistDirectory {
+			continue
+		}
+		id, err := inferID(scheme, name)
+		if err != nil {
+			if !errors.Is(err, errNoNumber) {
+				skip(name, "%v", err)
+			}
+			continue
+		}
+		main, err := findMain(filepath.Join(root, name))
+		if err != nil {
+			skip(name, "%v", err)
+			continue
+		}
+		if other, ok := assignments[id]; ok {
+			skip(name, "%s is assignment %s as well", other.Directory, id)
+			continue
+		}
+		target := scheme.Directory(id)
+		if target != name && exists(filepath.Join(root, target)) {
+			skip(name, "%s already exists", target)
+			continue
+		}
+		a := &Assignment{ID: id, Directory: name, Main: main}
+		pdf := filepath.Join(name, strings.TrimSuffix(main, ".tex")+".pdf")
+		if exists(filepath.Join(root, pdf)) {
+			a.PDF = pdf
+		}
+		assignments[id] = a
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(name), ".pdf") {
+			continue
+		}
+		id, err := inferID(scheme, name[:len(name)-len(".pdf")])
+		if err != nil {
+			if !errors.Is(err, errNoNumber) {
+				skip(name, "%v", err)
+			}
+			continue
+		}
+		a, ok := assignments[id]
+		if !ok {
+			a = &Assignment{ID: id}
+			assignments[id] = a
+		}
+		if a.PDF != "" {
+			skip(name, "%s is the PDF of assignment %s already", a.PDF, id)
+			continue
+		}
+		a.PDF = name
+	}
+
+	for id, a := range assignments {
+		if a.PDF != "" {
+			pdf := filepath.Join(DistDirectory, scheme.PDF(scheme.ID(id)))
+			if exists(filepath.Join(root, pdf)) {
+				skip(a.PDF, "%s already exists", pdf)
+				a.PDF = ""
+			} else {
+				// PDFs move first, as they might be in the directory
+				a.Renames = append(a.Renames, Rename{From: a.PDF, To: pdf})
+			}
+		}
+		if a.Directory == "" {
+			if a.PDF == "" {
+				delete(assignments, id)
+			}
+			continue
+		}
+		if a.Main != MainFile {
+			a.Renames = append(a.Renames, Rename{From: filepath.Join(a.Directory, a.Main), To: filepath.Join(a.Directory, MainFile)})
+		}
+		if target := scheme.Directory(id); target != a.Directory {
+			a.Renames = append(a.Renames, Rename{From: a.Directory, To: target})
+		}
+	}
+
+	for _, a := range assignments {
+		plan.Assignments = append(plan.Assignments, a)
+	}
+	sort.Slice(plan.Assignments, func(i, j int) bool {
+		return plan.Assignments[i].ID.Less(plan.Assignments[j].ID)
+	})
+	sort.SliceStable(plan.Skipped, func(i, j int) bool {
+		return plan.Skipped[i].Path < plan.Skipped[j].Path
+	})
+
+	for _, a := range plan.Assignments {
+		if a.Directory == "" {
+			continue
+		}
+		source, err := os.ReadFile(filepath.Join(root, a.Directory, a.Main))
+		if err != nil {
+			return nil, err
+		}
+		plan.readPreamble(string(source))
+	}
+	return plan, nil
+}
+
+// Renames returns the renames of all assignments
+func (p *Plan) Renames() []Rename {
+	renames := []Rename{}
+	for _, a := range p.Assignments {
+		renames = append(renames, a.Renames...)
+	}
+	return renames
+}
+
+// Latest returns the highest numbered assignment's ID, or the zero ID if there
+// are only named assignments
+func (p *Plan) Latest() config.AssignmentID {
+	latest := config.AssignmentID{}
+	for _, a := range p.Assignments {
+		if !a.ID.Named() {
+			latest = a.ID
+		}
+	}
+	return latest
+}
+
+// Apply renames all files and directories of the plan. Returns the renames done,
+// also if it failed halfway
+func (p *Plan) Apply(root string) ([]Rename, error) {
+	done := []Rename{}
+	for _, r := range p.Renames() {
+		to := filepath.Join(root, r.To)
+		if exists(to) {
+			return done, fmt.Errorf("failed to rename %s, %s already exists", r.From, r.To)
+		}
+		if err := os.MkdirAll(filepath.Dir(to), 0777); err != nil {
+			return done, err
+		}
+		if err := os.Rename(filepath.Join(root, r.From), to); err != nil {
+			return done, err
+		}
+		done = append(done, r)
+	}
+	return done, nil
+}
+
+// Configure sets the course, group, and members found in the preambles, unless the
+// configuration has them already, and makes the highest numbered assignment the
+// current one in .status.assignment, unless the current one is higher
+func (p *Plan) Configure(cfg *config.Configuration) {
+	if cfg.Spec == nil {
+		cfg.Spec = &config.ConfigurationSpec{}
+	}
+	if cfg.Spec.Course == "" {
+		cfg.Spec.Course = p.Course
+	}
+	if cfg.Spec.Group == "" {
+		cfg.Spec.Group = p.Group
+	}
+	if len(cfg.Spec.Members) == 0 && len(p.Members) > 0 {
+		cfg.Spec.Members = p.Members
+	}
+	if cfg.Status == nil {
+		cfg.Status = &config.ConfigurationStatus{}
+	}
+	if latest := p.Latest(); !latest.IsZero() && cfg.Status.Assignment.Less(latest) {
+		cfg.Status.Assignment = latest
+	}
+}
+
+// readPreamble takes the course, group, and members from the source's preamble,
+// replacing those of earlier assignments. Members are read from \member[ID]{Name}
+// of the csassignments class, or else from \author{A \and B}
+func (p *Plan) readPreamble(source string) {
+	preamble := source
+	if i := strings.Index(source, `\begin{document}`); i >= 0 {
+		preamble = source[:i]
+	}
+	preamble = commentPattern.ReplaceAllString(preamble, "$1")
+
+	if m := coursePattern.FindStringSubmatch(preamble); m != nil && untex(m[1]) != "" {
+		p.Course = untex(m[1])
+	}
+	if m := groupPattern.FindStringSubmatch(preamble); m != nil && untex(m[1]) != "" {
+		p.Group = untex(m[1])
+	}
+	members := []config.GroupMember{}
+	for _, m := range memberPattern.FindAllStringSubmatch(preamble, -1) {
+		if name := untex(m[2]); name != "" {
+			members = append(members, config.GroupMember{Name: name, ID: untex(m[1])})
+		}
+	}
+	if len(members) == 0 {
+		if m := authorPattern.FindStringSubmatch(preamble); m != nil {
+			for _, author := range andPattern.Split(m[1], -1) {
+				if name := untex(author); name != "" {
+					members = append(members, config.GroupMember{Name: name})
+				}
+			}
+		}
+	}
+	if len(members) > 0 {
+		p.Members = members
+	}
+}
+
+// errNoNumber is returned for names without any number, which are no assignments
+var errNoNumber error = errors.New("name contains no number")
+
+// inferID returns the assignment ID of a directory's or PDF's name, which either
+// follows the naming scheme, or contains a single number with an optional single
+// letter as suffix, e.g., Blatt1, sheet_02, or Uebung-5a
+func inferID(scheme *naming.Scheme, name string) (config.AssignmentID, error) {
+	if id, err := scheme.Parse(name); err == nil {
+		return id, nil
+	}
+	m := numberPattern.FindAllStringSubmatch(name, -1)
+	if len(m) == 0 {
+		return config.AssignmentID{}, errNoNumber
+	}
+	if len(m) > 1 {
+		return config.AssignmentID{}, fmt.Errorf("name contains more than one number")
+	}
+	id := strings.TrimLeft(m[0][1], "0")
+	if id == "" {
+		id = "0"
+	}
+	if len(m[0][2]) == 1 {
+		id += strings.ToLower(m[0][2])
+	}
+	return config.ParseAssignmentID(id)
+}
+
+// findMain returns the name of the main source file in the directory, i.e., the
+// one containing \documentclass and \begin{document}. If there are several,
+// assignment.tex or the one named like the directory is the main source file
+func findMain(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	mains := []string{}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".tex" {
+			continue
+		}
+		source, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return "", err
+		}
+		s := commentPattern.ReplaceAllString(string(source), "$1")
+		if strings.Contains(s, `\documentclass`) && strings.Contains(s, `\begin{document}`) {
+			mains = append(mains, e.Name())
+		}
+	}
+	switch len(mains) {
+	case 0:
+		return "", errors.New("found no main source file with \\documentclass and \\begin{document}")
+	case 1:
+		return mains[0], nil
+	}
+	for _, m := range mains {
+		if m == MainFile || strings.EqualFold(strings.TrimSuffix(m, ".tex"), filepath.Base(dir)) {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("found several main source files, %s", strings.Join(mains, ", "))
+}
+
+// untex removes escaping and surrounding whitespace from a macro's argument
+func untex(s string) string {
+	return strings.Join(strings.Fields(texUnescaper.Replace(s)), " ")
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
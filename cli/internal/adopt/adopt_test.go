@@ -0,0 +1,131 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adopt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/naming"
+)
+
+func write(t *testing.T, path string, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScanThenApply(t *testing.T) {
+	root := t.TempDir()
+	write(t, filepath.Join(root, "Blatt1", "main.tex"), `\documentclass{csassignments}
+\course{Algorithmen \& Datenstrukturen}
+\group{Gruppe 7}
+\member[123]{Max Mustermann}
+% \member[999]{Old Member}
+\begin{document}
+\end{document}
+`)
+	write(t, filepath.Join(root, "Blatt1.pdf"), "pdf")
+	write(t, filepath.Join(root, "sheet_02", "sheet_02.tex"), `\documentclass{article}
+\author{Max Mustermann \and Erika M\"{u}ller}
+\begin{document}
+\input{exercise}
+\end{document}
+`)
+	write(t, filepath.Join(root, "sheet_02", "draft.tex"), `\documentclass{article}\begin{document}\end{document}`)
+	write(t, filepath.Join(root, "sheet_02", "exercise.tex"), `\section{Exercise}`)
+	write(t, filepath.Join(root, "Uebung-3a", "uebung.tex"), `\documentclass{article}\begin{document}\end{document}`)
+	write(t, filepath.Join(root, "Uebung-3a", "uebung.pdf"), "pdf")
+	write(t, filepath.Join(root, "Blatt4_v2", "main.tex"), `\documentclass{article}\begin{document}\end{document}`)
+	write(t, filepath.Join(root, "Blatt6", "notes.txt"), "")
+	write(t, filepath.Join(root, "code", "main.py"), "")
+	write(t, filepath.Join(root, "Skript.pdf"), "pdf")
+
+	plan, err := Scan(root, naming.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Rename{
+		{From: "Blatt1.pdf", To: filepath.Join("dist", "assignment-01.pdf")},
+		{From: filepath.Join("Blatt1", "main.tex"), To: filepath.Join("Blatt1", "assignment.tex")},
+		{From: "Blatt1", To: "assignment-01"},
+		{From: filepath.Join("sheet_02", "sheet_02.tex"), To: filepath.Join("sheet_02", "assignment.tex")},
+		{From: "sheet_02", To: "assignment-02"},
+		{From: filepath.Join("Uebung-3a", "uebung.pdf"), To: filepath.Join("dist", "assignment-03a.pdf")},
+		{From: filepath.Join("Uebung-3a", "uebung.tex"), To: filepath.Join("Uebung-3a", "assignment.tex")},
+		{From: "Uebung-3a", To: "assignment-03a"},
+	}
+	if !reflect.DeepEqual(plan.Renames(), expected) {
+		t.Errorf("expected renames %v, found %v", expected, plan.Renames())
+	}
+	skipped := []string{}
+	for _, s := range plan.Skipped {
+		skipped = append(skipped, s.Path)
+	}
+	if !reflect.DeepEqual(skipped, []string{"Blatt4_v2", "Blatt6"}) {
+		t.Errorf("expected Blatt4_v2 and Blatt6 to be skipped, found %v", plan.Skipped)
+	}
+
+	// the newest preamble declaring members is sheet_02's
+	if plan.Course != "Algorithmen & Datenstrukturen" || plan.Group != "Gruppe 7" {
+		t.Errorf("expected course and group from Blatt1, found %q and %q", plan.Course, plan.Group)
+	}
+	members := []config.GroupMember{{Name: "Max Mustermann"}, {Name: `Erika M\"{u}ller`}}
+	if !reflect.DeepEqual(plan.Members, members) {
+		t.Errorf("expected members %v, found %v", members, plan.Members)
+	}
+
+	if _, err := plan.Apply(root); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{
+		filepath.Join("assignment-01", "assignment.tex"),
+		filepath.Join("assignment-02", "assignment.tex"),
+		filepath.Join("assignment-02", "exercise.tex"),
+		filepath.Join("assignment-03a", "assignment.tex"),
+		filepath.Join("dist", "assignment-03a.pdf"),
+	} {
+		if _, err := os.Stat(filepath.Join(root, p)); err != nil {
+			t.Errorf("expected %s after adopting, %v", p, err)
+		}
+	}
+
+	cfg := config.Minimal()
+	cfg.Spec.Group = "Configured"
+	plan.Configure(cfg)
+	if cfg.Status.Assignment != (config.AssignmentID{Number: 3, Suffix: "a"}) {
+		t.Errorf("expected current assignment 3a, found %s", cfg.Status.Assignment)
+	}
+	if cfg.Spec.Course != plan.Course || cfg.Spec.Group != "Configured" {
+		t.Error("expected only missing fields to be configured")
+	}
+
+	// adopting again changes nothing
+	plan, err = Scan(root, naming.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Assignments) != 3 || len(plan.Renames()) != 0 {
+		t.Errorf("expected adopted assignments to stay, found %d renames", len(plan.Renames()))
+	}
+}
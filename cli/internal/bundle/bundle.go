@@ -28,8 +28,11 @@ import (
 
 	"github.com/Masterminds/sprig/v3"
 	"github.com/rs/zerolog/log"
+	"github.com/zoomoid/assignments/v1/internal/config"
 	"github.com/zoomoid/assignments/v1/internal/context"
-	"github.com/zoomoid/assignments/v1/internal/util"
+	"github.com/zoomoid/assignments/v1/internal/naming"
+	assignmenttemplate "github.com/zoomoid/assignments/v1/internal/template"
+	"github.com/zoomoid/assignments/v1/internal/trash"
 )
 
 // BundlerBackend is a specific string type for picking backends, and consequently file endings
@@ -45,13 +48,20 @@ const (
 )
 
 var (
+	// Backends lists all available bundler backends
+	Backends []BundlerBackend = []BundlerBackend{BundlerBackendZip, BundlerBackendTar, BundlerBackendTarGzip}
+
 	// ErrAchiveExists is a static error that indicates an archive already existing without explitly truncating it
 	ErrArchiveExists error = errors.New("archive already exists")
-	// default archive template. This contains the fields _id and format, which are automatically aliased
+	// default archive template. This contains the fields _name and _format, which are automatically aliased
 	// into the map that contains the data bindings, thus are ALWAYS available
-	DefaultArchiveNameTemplate string = "assignment-{{._id}}.{{._format}}"
+	DefaultArchiveNameTemplate string = "{{._name}}.{{._format}}"
 )
 
+// ArchiveNameTemplateName is the name of the archive name template, as shown in
+// errors. It matches the template's location in the configuration
+const ArchiveNameTemplateName string = "spec.bundle.template"
+
 // Bundler interface all backends should implement
 type Bundler interface {
 	// AddAssignment adds the main assignment PDF at the root of the archive
@@ -106,7 +116,7 @@ type BundlerContext struct {
 	sourceDirectory string
 	// artifactsDirectory is the ./dist/ directory from which the PDF originates
 	artifactsDirectory string
-	// base is a string of the form assignment-<no> required for structural assumptions
+	// base is the assignment's directory, named after the naming scheme, required for structural assumptions
 	// about the directory structure
 	base string
 	// archiveName is the name of the archive created when executing the template
@@ -124,31 +134,25 @@ func New(ctx *context.AppContext, options *BundlerOptions) (*BundlerContext, err
 		return nil, err
 	}
 
-	data := options.CloneDataBindings()
-	if data == nil {
-		data = make(map[string]interface{})
-	}
-	id, err := util.AssignmentNumberFromRegex(util.AssignmentPattern, filepath.Base(options.Target))
+	id, err := ctx.Naming().ParseID(options.Target)
 	if err != nil {
 		return nil, err
 	}
 
-	// only set values if not overriden by the user in spec.bundleOptions.data
-	if _, ok := data["_id"]; !ok {
-		data["_id"] = id
-	}
-	data["_id"] = id
-	if _, ok := data["_format"]; !ok {
-		data["_format"] = format(options.Backend)
+	// apply the assignment's own configuration file
+	assignmentCtx, err := ctx.ForAssignment(sourceDirectory)
+	if err != nil {
+		return nil, err
 	}
+	bundlerCtx := assignmentCtx.Clone()
+
+	data := ArchiveNameData(options.CloneDataBindings(), ctx.Naming(), id, roster(bundlerCtx.Configuration.Spec, id), options.Backend)
 
 	archiveName, err := MakeArchiveName(options.Template, data)
 	if err != nil {
 		return nil, err
 	}
 
-	bundlerCtx := ctx.Clone()
-
 	bundler := &BundlerContext{
 		BundlerOptions:     *options,
 		AppContext:         *bundlerCtx,
@@ -169,6 +173,10 @@ func New(ctx *context.AppContext, options *BundlerOptions) (*BundlerContext, err
 // Bundle runs the bundling action by picking a bundle implementor from the selected backend
 // Returns the archive's filename when successful, otherwise an error and the empty string
 func (b *BundlerContext) Bundle() error {
+	if err := b.keepArchive(); err != nil {
+		return err
+	}
+
 	bundler, err := b.makeBundler()
 	if err != nil {
 		return err
@@ -187,6 +195,27 @@ func (b *BundlerContext) Bundle() error {
 	return nil
 }
 
+// keepArchive saves an existing archive that is about to be overwritten to a
+// snapshot in the trash
+func (b *BundlerContext) keepArchive() error {
+	if !b.ArchiveExists() {
+		return nil
+	}
+	store, err := trash.Open(b.Root, b.Configuration.Spec.TrashOptions)
+	if err != nil {
+		return err
+	}
+	id, _ := b.Naming().ParseID(b.Target)
+	snapshot, err := store.Save(&trash.Snapshot{Operation: "bundle", Assignment: id}, filepath.Join(b.artifactsDirectory, b.archiveName))
+	if err != nil {
+		return fmt.Errorf("failed to keep %s before overwriting it, %w", b.archiveName, err)
+	}
+	if snapshot != nil {
+		log.Info().Msgf("Kept previous %s in snapshot %s, restore with \"assignmentctl undo\"", b.archiveName, snapshot.Name)
+	}
+	return nil
+}
+
 // ArchiveName returns the context's archive name field to other modules
 func (b *BundlerContext) ArchiveName() string {
 	return b.archiveName
@@ -288,21 +317,70 @@ func additionalFiles(sourceDirectory string, includes []string) ([]additionalFil
 	return additionalFiles, nil
 }
 
+// ArchiveNameData augments the data bindings from the config file with the
+// fields that are always available to archive name templates, namely _id,
+// _name, _members, and _format. _name is the assignment's name from the naming
+// scheme, e.g., assignment-03. _members are the group members of the assignment,
+// see config.ConfigurationSpec.Roster. _format is only derived from the backend
+// if not overridden by the user
+func ArchiveNameData(data map[string]interface{}, n *naming.Scheme, id string, members []config.GroupMember, backend BundlerBackend) map[string]interface{} {
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	if members == nil {
+		members = []config.GroupMember{}
+	}
+	data["_id"] = id
+	data["_name"] = n.Name(id)
+	data["_members"] = members
+	if _, ok := data["_format"]; !ok {
+		data["_format"] = format(backend)
+	}
+	return data
+}
+
+// ArchiveNameFor renders the archive name of the assignment with the given id for
+// a backend from the bundle options in the configuration spec
+func ArchiveNameFor(spec *config.ConfigurationSpec, n *naming.Scheme, id string, backend BundlerBackend) (string, error) {
+	var tpl string
+	var data map[string]interface{}
+	if spec != nil && spec.BundleOptions != nil {
+		tpl = spec.BundleOptions.Template
+		data = spec.BundleOptions.Clone().Data
+	}
+	return MakeArchiveName(tpl, ArchiveNameData(data, n, id, roster(spec, id), backend))
+}
+
+// roster returns the group members of the assignment with the given id
+func roster(spec *config.ConfigurationSpec, id string) []config.GroupMember {
+	if spec == nil {
+		return nil
+	}
+	a, err := config.ParseAssignmentID(id)
+	if err != nil {
+		return spec.Members
+	}
+	return spec.Roster(a)
+}
+
 // MakeArchiveName executes the template with the data given in the config file
 // Returns the archive's filename when successfully executed the template, otherwise
-// returns the occurred error and an empty string
+// returns the occurred error, including its position in the template, and an empty string
 func MakeArchiveName(tpl string, data map[string]interface{}) (string, error) {
 	if tpl == "" {
 		tpl = DefaultArchiveNameTemplate
 	}
 
-	tmpl := template.Must(template.New("bundleName").Funcs(sprig.TxtFuncMap()).Parse(tpl))
+	tmpl, err := template.New(ArchiveNameTemplateName).Funcs(sprig.TxtFuncMap()).Parse(tpl)
+	if err != nil {
+		return "", assignmenttemplate.NewError(ArchiveNameTemplateName, tpl, err)
+	}
 	var output bytes.Buffer
 
-	err := tmpl.Execute(&output, data)
+	err = tmpl.Execute(&output, data)
 
 	if err != nil {
-		return "", err
+		return "", assignmenttemplate.NewError(ArchiveNameTemplateName, tpl, err)
 	}
 
 	return output.String(), nil
@@ -20,12 +20,16 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/zoomoid/assignments/v1/internal/config"
 	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/naming"
+	"github.com/zoomoid/assignments/v1/internal/template"
 )
 
 func TestSyntheticTarBundling(t *testing.T) {
@@ -308,3 +312,44 @@ func TestMakeBundler(t *testing.T) {
 		}
 	})
 }
+
+func TestMakeArchiveName(t *testing.T) {
+	members := []config.GroupMember{{Name: "Max Mustermann", ID: "1"}, {Name: "Erika Musterfrau", ID: "2"}}
+	data := ArchiveNameData(map[string]interface{}{"group": "g1"}, naming.Default(), "03", members, BundlerBackendTarGzip)
+
+	name, err := MakeArchiveName("", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "assignment-03.tar.gz" {
+		t.Errorf("expected default archive name, found %s", name)
+	}
+
+	name, err = MakeArchiveName("{{ .group }}-{{ ._id }}.{{ ._format }}", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "g1-03.tar.gz" {
+		t.Errorf("expected g1-03.tar.gz, found %s", name)
+	}
+
+	name, err = MakeArchiveName(`{{ range $i, $m := ._members }}{{ if $i }}-{{ end }}{{ $m.ID }}{{ end }}_{{ ._id }}.{{ ._format }}`, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "1-2_03.tar.gz" {
+		t.Errorf("expected 1-2_03.tar.gz, found %s", name)
+	}
+
+	_, err = MakeArchiveName("{{ ._id }.zip", data)
+	if err == nil {
+		t.Fatal("expected error for malformed template")
+	}
+	var tplErr *template.Error
+	if !errors.As(err, &tplErr) {
+		t.Fatalf("expected template error, found %T", err)
+	}
+	if tplErr.Template != ArchiveNameTemplateName || tplErr.Line != 1 {
+		t.Errorf("expected error in %s at line 1, found %s:%d", ArchiveNameTemplateName, tplErr.Template, tplErr.Line)
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calendar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/deadline"
+)
+
+const (
+	// DefaultAlarm is the time before a deadline at which exported events remind
+	DefaultAlarm string = "24h"
+	// NoAlarm disables reminders of exported events
+	NoAlarm string = "none"
+	// DefaultPattern matches summaries of imported events such as "Sheet 3",
+	// "Assignment #03 due", "Übungsblatt 3", or "Sheet 5b"
+	DefaultPattern string = `(?i)(?:assignment|sheet|exercise|homework|blatt|übung)\D{0,3}(\d+[a-z]?)`
+)
+
+// Deadline is the due date of an assignment imported from a calendar
+type Deadline struct {
+	// ID is the assignment's ID
+	ID config.AssignmentID
+	// Due is the assignment's due date
+	Due time.Time
+	// Summary is the summary of the event the deadline was taken from
+	Summary string
+}
+
+// ParseAlarm parses the time before a deadline at which to remind, either as Go
+// duration, e.g., "90m" or "36h", or in days or weeks, e.g., "2d". An empty string
+// selects DefaultAlarm, NoAlarm returns nil
+func ParseAlarm(s string) (*time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		s = DefaultAlarm
+	}
+	if s == NoAlarm {
+		return nil, nil
+	}
+	d, err := deadline.ParseDuration(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alarm %q, use a duration such as 24h or 2d, or %q", s, NoAlarm)
+	}
+	return &d, nil
+}
+
+// UID returns the stable identifier of an assignment's deadline event. It only
+// depends on the course, the group, and the assignment's ID, such that exporting
+// again after changing the due date updates the existing event
+func UID(spec *config.ConfigurationSpec, id string) string {
+	h := sha256.Sum256([]byte(spec.Course + "\x00" + spec.Group))
+	return fmt.Sprintf("assignment-%s-%s@assignmentctl", id, hex.EncodeToString(h[:])[:16])
+}
+
+// Deadlines creates a calendar with one event for each assignment with a due date
+// recorded in the status, ordered by assignment
+func Deadlines(spec *config.ConfigurationSpec, status *config.ConfigurationStatus, alarm *time.Duration, now time.Time) *Calendar {
+	cal := &Calendar{
+		Name:   spec.Course,
+		Events: []Event{},
+	}
+	if status == nil {
+		return cal
+	}
+
+	ids := make([]string, 0, len(status.Assignments))
+	for id, a := range status.Assignments {
+		if a != nil && a.Due != nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return lessID(ids[i], ids[j])
+	})
+
+	for _, id := range ids {
+		summary := fmt.Sprintf("Assignment %s", id)
+		if spec.Course != "" {
+			summary = fmt.Sprintf("%s: %s", spec.Course, summary)
+		}
+		description := fmt.Sprintf("Deadline of assignment %s", id)
+		if spec.Course != "" {
+			description += " in " + spec.Course
+		}
+		if spec.Group != "" {
+			description += fmt.Sprintf(" for group %s", spec.Group)
+		}
+		cal.Events = append(cal.Events, Event{
+			UID:         UID(spec, id),
+			Summary:     summary,
+			Description: description,
+			Start:       *status.Assignments[id].Due,
+			Stamp:       now,
+			Alarm:       alarm,
+		})
+	}
+	return cal
+}
+
+// Import maps the events of a calendar to assignments by matching their summaries
+// against pattern. The assignment's ID, e.g., 5 or 5b, is captured by the group
+// named "number", or by the first group otherwise. Events on whole days are due at
+// timeOfDay, in 15:04 format. If several events match the same assignment, the
+// latest one is used. Deadlines are ordered by assignment
+func Import(cal *Calendar, pattern *regexp.Regexp, timeOfDay string) ([]Deadline, error) {
+	if pattern.NumSubexp() == 0 {
+		return nil, fmt.Errorf("pattern %q has no group capturing the assignment's ID", pattern.String())
+	}
+	group := 1
+	if i := pattern.SubexpIndex("number"); i > 0 {
+		group = i
+	}
+	clock, err := time.Parse("15:04", timeOfDay)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time of day %q, expected 15:04 format", timeOfDay)
+	}
+
+	deadlines := map[config.AssignmentID]Deadline{}
+	for _, e := range cal.Events {
+		m := pattern.FindStringSubmatch(e.Summary)
+		if m == nil {
+			continue
+		}
+		n, err := config.ParseAssignmentID(strings.ToLower(m[group]))
+		if err != nil {
+			continue
+		}
+		due := e.Start
+		if e.AllDay {
+			due = time.Date(due.Year(), due.Month(), due.Day(), clock.Hour(), clock.Minute(), 0, 0, due.Location())
+		}
+		if d, ok := deadlines[n]; ok && d.Due.After(due) {
+			continue
+		}
+		deadlines[n] = Deadline{ID: n, Due: due, Summary: e.Summary}
+	}
+
+	result := make([]Deadline, 0, len(deadlines))
+	for _, d := range deadlines {
+		result = append(result, d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID.Less(result[j].ID)
+	})
+	return result, nil
+}
+
+// lessID orders the keys of .status.assignments by their IDs, and keys that are
+// no valid IDs after all others
+func lessID(a, b string) bool {
+	i, errA := config.ParseAssignmentID(a)
+	j, errB := config.ParseAssignmentID(b)
+	if errA != nil || errB != nil {
+		if (errA == nil) != (errB == nil) {
+			return errA == nil
+		}
+		return a < b
+	}
+	if i == j {
+		return a < b
+	}
+	return i.Less(j)
+}
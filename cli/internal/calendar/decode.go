@@ -0,0 +1,208 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calendar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidCalendar is returned for input that is not an iCalendar object
+	ErrInvalidCalendar error = errors.New("invalid calendar")
+)
+
+// property is a single content line, e.g., "DTSTART;TZID=Europe/Berlin:20261023T235900"
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Decode reads the events of an iCalendar object from r. Besides VEVENTs, VTODOs are
+// decoded as events starting at their due date. Times without offset or timezone
+// are interpreted in loc, as are times with a TZID unknown to the system. Events
+// on whole days are marked as such and start at midnight in loc
+func Decode(r io.Reader, loc *time.Location) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &Calendar{Events: []Event{}}
+	var current *Event
+	found := false
+	// components are the names of all open components, innermost last, such that
+	// properties of components nested in events, e.g., a VALARM's DESCRIPTION, are
+	// not mistaken for the event's
+	components := []string{}
+	innermost := func() string {
+		if len(components) == 0 {
+			return ""
+		}
+		return components[len(components)-1]
+	}
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		p, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d, %v", ErrInvalidCalendar, i+1, err)
+		}
+		switch p.name {
+		case "BEGIN":
+			component := strings.ToUpper(p.value)
+			components = append(components, component)
+			switch component {
+			case "VCALENDAR":
+				found = true
+			case "VEVENT", "VTODO":
+				current = &Event{}
+			}
+			continue
+		case "END":
+			component := strings.ToUpper(p.value)
+			if innermost() != component {
+				return nil, fmt.Errorf("%w: line %d, END:%s does not match BEGIN:%s", ErrInvalidCalendar, i+1, component, innermost())
+			}
+			components = components[:len(components)-1]
+			switch component {
+			case "VEVENT", "VTODO":
+				if current != nil && !current.Start.IsZero() {
+					cal.Events = append(cal.Events, *current)
+				}
+				current = nil
+			}
+			continue
+		}
+
+		if innermost() == "VCALENDAR" && p.name == "X-WR-CALNAME" {
+			cal.Name = unescape(p.value)
+		}
+		if current == nil || (innermost() != "VEVENT" && innermost() != "VTODO") {
+			continue
+		}
+		switch p.name {
+		case "UID":
+			current.UID = p.value
+		case "SUMMARY":
+			current.Summary = unescape(p.value)
+		case "DESCRIPTION":
+			current.Description = unescape(p.value)
+		case "DTSTART", "DUE":
+			t, allDay, err := parseDateTime(p, loc)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d, %v", ErrInvalidCalendar, i+1, err)
+			}
+			// a VTODO's due date takes precedence over its start
+			if p.name == "DUE" || current.Start.IsZero() {
+				current.Start, current.AllDay = t, allDay
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: missing BEGIN:VCALENDAR", ErrInvalidCalendar)
+	}
+	return cal, nil
+}
+
+// unfold reads all content lines, joining lines continued with a leading space or tab
+func unfold(r io.Reader) ([]string, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseProperty splits a content line into name, parameters, and value. Parameter
+// values may be quoted and contain colons
+func parseProperty(line string) (*property, error) {
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return nil, fmt.Errorf("missing colon in %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	p := &property{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		p.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+	return p, nil
+}
+
+// parseDateTime parses DATE and DATE-TIME values, either in UTC, in the timezone
+// given by the TZID parameter, or floating in loc
+func parseDateTime(p *property, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(p.value)
+	if p.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(dateTimeLayout, value)
+		return t, false, err
+	}
+	if tzid, ok := p.params["TZID"]; ok {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// unescape reverts the escaping of TEXT values
+func unescape(s string) string {
+	return strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	).Replace(s)
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calendar
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
+)
+
+const course = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"X-WR-CALNAME:Linear Algebra\\, WS\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:1\r\n" +
+	"SUMMARY:Übungsblatt 4 Abgabe\r\n" +
+	"DTSTART;TZID=\"Europe/Berlin\":20261023T120000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:2\r\n" +
+	"SUMMARY:Sheet 5\r\n" +
+	"DTSTART;VALUE=DATE:20261030\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:3\r\n" +
+	"SUMMARY:Lecture\r\n" +
+	"DTSTART:20261030T100000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"SUMMARY:Assignment #06\r\n" +
+	"  due\r\n" +
+	"DTSTART:20261030T100000Z\r\n" +
+	"DUE:20261106T225900Z\r\n" +
+	"END:VTODO\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Sheet 5 (extended)\r\n" +
+	"DTSTART:20261031T120000\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestDecode(t *testing.T) {
+	cal, err := Decode(strings.NewReader(course), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cal.Name != "Linear Algebra, WS" {
+		t.Errorf("expected unescaped calendar name, found %q", cal.Name)
+	}
+	if len(cal.Events) != 5 {
+		t.Fatalf("expected 5 events, found %d", len(cal.Events))
+	}
+	if s := cal.Events[3].Summary; s != "Assignment #06 due" {
+		t.Errorf("expected unfolded summary, found %q", s)
+	}
+	if due := cal.Events[3].Start; !due.Equal(time.Date(2026, time.November, 6, 22, 59, 0, 0, time.UTC)) {
+		t.Errorf("expected VTODO to start at its due date, found %v", due)
+	}
+	if !cal.Events[1].AllDay || cal.Events[0].AllDay {
+		t.Error("expected only DATE values to be whole days")
+	}
+
+	if _, err := Decode(strings.NewReader("SUMMARY:Sheet 1\r\n"), time.UTC); err == nil {
+		t.Error("expected error for input without calendar")
+	}
+}
+
+func TestDecodeAlarm(t *testing.T) {
+	cal, err := Decode(strings.NewReader("BEGIN:VCALENDAR\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"SUMMARY:Sheet 5\r\n"+
+		"DTSTART:20261030T120000Z\r\n"+
+		"BEGIN:VALARM\r\n"+
+		"ACTION:EMAIL\r\n"+
+		"SUMMARY:Reminder\r\n"+
+		"DESCRIPTION:Sheet 5 is due tomorrow\r\n"+
+		"TRIGGER:-PT24H\r\n"+
+		"END:VALARM\r\n"+
+		"DESCRIPTION:Hand in via the portal\r\n"+
+		"END:VEVENT\r\n"+
+		"END:VCALENDAR\r\n"), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cal.Events) != 1 {
+		t.Fatalf("expected 1 event, found %d", len(cal.Events))
+	}
+	if e := cal.Events[0]; e.Summary != "Sheet 5" || e.Description != "Hand in via the portal" {
+		t.Errorf("expected the alarm's properties to be ignored, found %q, %q", e.Summary, e.Description)
+	}
+
+	if _, err := Decode(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n"), time.UTC); err == nil {
+		t.Error("expected error for unterminated event")
+	}
+}
+
+func TestImport(t *testing.T) {
+	cal, err := Decode(strings.NewReader(course), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadlines, err := Import(cal, regexp.MustCompile(DefaultPattern), "23:59")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+	expected := []Deadline{
+		{ID: config.NumberedID(4), Due: time.Date(2026, time.October, 23, 12, 0, 0, 0, berlin)},
+		{ID: config.NumberedID(5), Due: time.Date(2026, time.October, 31, 12, 0, 0, 0, time.UTC)},
+		{ID: config.NumberedID(6), Due: time.Date(2026, time.November, 6, 22, 59, 0, 0, time.UTC)},
+	}
+	if len(deadlines) != len(expected) {
+		t.Fatalf("expected %d deadlines, found %d", len(expected), len(deadlines))
+	}
+	for i, e := range expected {
+		if d := deadlines[i]; d.ID != e.ID || !d.Due.Equal(e.Due) {
+			t.Errorf("expected assignment %s due %v, found %s due %v", e.ID, e.Due, d.ID, d.Due)
+		}
+	}
+
+	deadlines, err = Import(cal, regexp.MustCompile(`^Sheet (?P<number>\d+)$`), "12:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deadlines) != 1 || !deadlines[0].Due.Equal(time.Date(2026, time.October, 30, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected whole-day deadline at 12:00, found %+v", deadlines)
+	}
+
+	if _, err := Import(cal, regexp.MustCompile(`Sheet`), "23:59"); err == nil {
+		t.Error("expected error for pattern without group")
+	}
+
+	parts := &Calendar{Events: []Event{
+		{Summary: "Sheet 10", Start: time.Date(2026, time.December, 4, 12, 0, 0, 0, time.UTC)},
+		{Summary: "Sheet 5B", Start: time.Date(2026, time.November, 6, 12, 0, 0, 0, time.UTC)},
+		{Summary: "Sheet 5a", Start: time.Date(2026, time.October, 30, 12, 0, 0, 0, time.UTC)},
+	}}
+	deadlines, err = Import(parts, regexp.MustCompile(DefaultPattern), "23:59")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{}
+	for _, d := range deadlines {
+		ids = append(ids, d.ID.String())
+	}
+	if strings.Join(ids, ",") != "5a,5b,10" {
+		t.Errorf("expected suffixed deadlines in order 5a,5b,10, found %v", ids)
+	}
+}
+
+func TestEncodeThenDecode(t *testing.T) {
+	start := time.Date(2026, time.October, 23, 21, 59, 0, 0, time.UTC)
+	out := &bytes.Buffer{}
+	err := Encode(out, &Calendar{Events: []Event{{
+		UID:     "assignment-01@assignmentctl",
+		Summary: strings.Repeat("Linear Algebra; ", 10) + "Assignment 01",
+		Start:   start,
+		Stamp:   start,
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cal, err := Decode(out, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cal.Events) != 1 {
+		t.Fatalf("expected 1 event, found %d", len(cal.Events))
+	}
+	if e := cal.Events[0]; e.Summary != strings.Repeat("Linear Algebra; ", 10)+"Assignment 01" || !e.Start.Equal(start) {
+		t.Errorf("expected event to survive encoding, found %+v", e)
+	}
+}
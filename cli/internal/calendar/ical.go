@@ -0,0 +1,165 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// ProductID identifies the CLI as the producer of exported calendars
+	ProductID string = "-//zoomoid//assignmentctl//EN"
+
+	// lineLength is the maximum length of a content line in octets, excluding the
+	// line break, after which lines are folded
+	lineLength int = 75
+	// dateTimeLayout is the UTC DATE-TIME format of RFC 5545, section 3.3.5
+	dateTimeLayout string = "20060102T150405Z"
+)
+
+// Calendar is an iCalendar object with a list of events
+type Calendar struct {
+	// Name is the calendar's display name
+	Name string
+	// Events are the calendar's events
+	Events []Event
+}
+
+// Event is a single VEVENT of a calendar
+type Event struct {
+	// UID uniquely identifies the event. Calendar applications update existing events
+	// with the same UID on import instead of adding a duplicate
+	UID string
+	// Summary is the event's title
+	Summary string
+	// Description is the event's longer description, may be empty
+	Description string
+	// Start is the event's point in time
+	Start time.Time
+	// AllDay is true for events decoded from a DATE value, which start at midnight
+	AllDay bool
+	// Stamp is the time the event was created or last modified
+	Stamp time.Time
+	// Alarm is the time before Start at which to display a reminder, or nil for none
+	Alarm *time.Duration
+}
+
+// Encode writes the calendar to w as RFC 5545 VCALENDAR, using CRLF line breaks
+// and folding long lines
+func Encode(w io.Writer, cal *Calendar) error {
+	bw := bufio.NewWriter(w)
+	line := func(name string, value string) {
+		bw.WriteString(fold(name + ":" + value))
+		bw.WriteString("\r\n")
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", ProductID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		line("X-WR-CALNAME", escape(cal.Name))
+	}
+	for _, e := range cal.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", e.Stamp.UTC().Format(dateTimeLayout))
+		line("DTSTART", e.Start.UTC().Format(dateTimeLayout))
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		line("TRANSP", "TRANSPARENT")
+		if e.Alarm != nil {
+			line("BEGIN", "VALARM")
+			line("ACTION", "DISPLAY")
+			line("DESCRIPTION", escape(e.Summary))
+			line("TRIGGER", "-"+FormatDuration(*e.Alarm))
+			line("END", "VALARM")
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// FormatDuration formats a positive duration as RFC 5545 DURATION, e.g., "P1D" or
+// "PT1H30M". Seconds are truncated
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+
+	b := &strings.Builder{}
+	b.WriteString("P")
+	if days > 0 {
+		fmt.Fprintf(b, "%dD", days)
+	}
+	if hours > 0 || minutes > 0 || days == 0 {
+		b.WriteString("T")
+		if hours > 0 {
+			fmt.Fprintf(b, "%dH", hours)
+		}
+		if minutes > 0 || hours == 0 {
+			fmt.Fprintf(b, "%dM", minutes)
+		}
+	}
+	return b.String()
+}
+
+// escape escapes TEXT values according to RFC 5545, section 3.3.11
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// fold splits content lines longer than 75 octets into multiple lines, each
+// continued with a leading space, without splitting UTF-8 sequences
+func fold(s string) string {
+	if len(s) <= lineLength {
+		return s
+	}
+	b := &strings.Builder{}
+	n := 0
+	for _, r := range s {
+		size := utf8.RuneLen(r)
+		if n+size > lineLength {
+			b.WriteString("\r\n ")
+			// the leading space counts towards the continued line's length
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
+)
+
+func TestEncode(t *testing.T) {
+	alarm := 24 * time.Hour
+	start := time.Date(2026, time.October, 23, 23, 59, 0, 0, time.FixedZone("CEST", 2*60*60))
+	cal := &Calendar{
+		Name: "Linear Algebra",
+		Events: []Event{{
+			UID:         "assignment-01@assignmentctl",
+			Summary:     "Linear Algebra: Assignment 01",
+			Description: "Deadline; for group A, B\nand " + strings.Repeat("x", 80),
+			Start:       start,
+			Stamp:       start,
+			Alarm:       &alarm,
+		}},
+	}
+	out := &bytes.Buffer{}
+	if err := Encode(out, cal); err != nil {
+		t.Fatal(err)
+	}
+	s := out.String()
+
+	for _, expected := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART:20261023T215900Z\r\n",
+		`DESCRIPTION:Deadline\; for group A\, B\nand `,
+		"TRIGGER:-P1D\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("expected output to contain %q, found\n%s", expected, s)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\r\n"), "\r\n") {
+		if len(line) > lineLength {
+			t.Errorf("expected lines to be folded at %d octets, found %q", lineLength, line)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                             "PT0M",
+		90 * time.Minute:              "PT1H30M",
+		24 * time.Hour:                "P1D",
+		26 * time.Hour:                "P1DT2H",
+		48*time.Hour + 15*time.Minute: "P2DT15M",
+		3*time.Hour + 30*time.Second:  "PT3H",
+	}
+	for d, expected := range tests {
+		if s := FormatDuration(d); s != expected {
+			t.Errorf("expected %s for %v, found %s", expected, d, s)
+		}
+	}
+}
+
+func TestParseAlarm(t *testing.T) {
+	d, err := ParseAlarm("")
+	if err != nil || d == nil || *d != 24*time.Hour {
+		t.Errorf("expected default alarm of 24h, found %v, %v", d, err)
+	}
+	d, err = ParseAlarm("2d")
+	if err != nil || d == nil || *d != 48*time.Hour {
+		t.Errorf("expected alarm of 48h, found %v, %v", d, err)
+	}
+	d, err = ParseAlarm(NoAlarm)
+	if err != nil || d != nil {
+		t.Errorf("expected no alarm, found %v, %v", d, err)
+	}
+	if _, err := ParseAlarm("soon"); err == nil {
+		t.Error("expected error for invalid alarm")
+	}
+}
+
+func TestDeadlines(t *testing.T) {
+	due := time.Date(2026, time.October, 23, 23, 59, 0, 0, time.UTC)
+	spec := &config.ConfigurationSpec{Course: "Linear Algebra", Group: "A"}
+	status := &config.ConfigurationStatus{}
+	status.Upsert("02").Due = &due
+	status.Upsert("01").Due = &due
+	status.Upsert("03")
+
+	cal := Deadlines(spec, status, nil, time.Now())
+	if len(cal.Events) != 2 {
+		t.Fatalf("expected 2 events, found %d", len(cal.Events))
+	}
+	if cal.Events[0].UID != UID(spec, "01") || cal.Events[1].UID != UID(spec, "02") {
+		t.Errorf("expected events ordered by assignment, found %s, %s", cal.Events[0].UID, cal.Events[1].UID)
+	}
+	if UID(spec, "01") == UID(&config.ConfigurationSpec{Course: "Linear Algebra", Group: "B"}, "01") {
+		t.Error("expected UIDs to differ between groups")
+	}
+}
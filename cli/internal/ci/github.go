@@ -25,6 +25,8 @@ import (
 	"text/template"
 
 	"github.com/lithammer/dedent"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/naming"
 )
 
 var (
@@ -42,7 +44,9 @@ var (
         branches:
           - "*"
         tags:
-          - assignment-[0-9][0-9]+
+        [[- range .TagFilters ]]
+          - [[ . ]]
+        [[- end ]]
     jobs:
       build:
         name: Build assignments
@@ -97,11 +101,16 @@ func (a *Artifact) ToString() string {
 	return fmt.Sprintf("%s#%s", a.Path, a.Name)
 }
 
-func TemplateGithubActionsEnvFile(artifactsDirectory string, archiveNameTemplate string, ad map[string]interface{}) (*bytes.Buffer, error) {
+// GithubAction renders the Github Actions workflow template with tag filters
+// matching the naming scheme
+func GithubAction(n *naming.Scheme) (string, error) {
+	return renderTemplate("github", GithubActionTemplate, n)
+}
+
+func TemplateGithubActionsEnvFile(ctx *context.AppContext, artifactsDirectory string) (*bytes.Buffer, error) {
 	tag := os.Getenv("GITHUB_REF_NAME")
-	assignment := strings.Replace(tag, "assignment-", "", 1)
 
-	artifacts, err := archiveAndPdfName(assignment, artifactsDirectory, archiveNameTemplate, ad)
+	artifacts, err := archiveAndPdfName(ctx, tag, artifactsDirectory)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +134,7 @@ func TemplateGithubActionsEnvFile(artifactsDirectory string, archiveNameTemplate
 		PdfAssets     string
 		ArchiveAssets string
 	}{
-		Assignment:    assignment,
+		Assignment:    artifacts.ID,
 		PdfAssets:     pdfArtifacts.ToString(),
 		ArchiveAssets: archiveArtifact.ToString(),
 	}
@@ -25,6 +25,8 @@ import (
 	"text/template"
 
 	"github.com/lithammer/dedent"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/naming"
 )
 
 var (
@@ -55,7 +57,7 @@ var (
     stage: release
     image: ghcr.io/zoomoid/assignments/ci/gitlab:latest
     rules:
-      - if: $CI_COMMIT_TAG && $CI_COMMIT_TAG =~ /^assignment-[0-9][0-9]+$/
+      - if: $CI_COMMIT_TAG && $CI_COMMIT_TAG =~ /[[ .TagPattern ]]/
     script:
       - assignmentctl ci release gitlab > .env
       - source .env 
@@ -75,13 +77,17 @@ type Asset struct {
 	Filepath string `json:"filepath,omitempty"`
 }
 
-func TemplateGitlabCIEnvFile(artifactsDirectory string, archiveNameTemplate string, ad map[string]interface{}) (*bytes.Buffer, error) {
+// GitlabCI renders the Gitlab CI template with tag rules matching the naming scheme
+func GitlabCI(n *naming.Scheme) (string, error) {
+	return renderTemplate("gitlab", GitlabCITemplate, n)
+}
+
+func TemplateGitlabCIEnvFile(ctx *context.AppContext, artifactsDirectory string) (*bytes.Buffer, error) {
 	tag := os.Getenv("CI_COMMIT_TAG")
 	artifactsId := os.Getenv("CI_JOB_ID")
 	projectURL := os.Getenv("CI_PROJECT_URL")
-	assignment := strings.Replace(tag, "assignment-", "", 1)
 
-	artifacts, err := archiveAndPdfName(assignment, artifactsDirectory, archiveNameTemplate, ad)
+	artifacts, err := archiveAndPdfName(ctx, tag, artifactsDirectory)
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +125,7 @@ func TemplateGitlabCIEnvFile(artifactsDirectory string, archiveNameTemplate stri
 		PdfAssets     string
 		ArchiveAssets string
 	}{
-		Assignment:    assignment,
+		Assignment:    artifacts.ID,
 		Tag:           tag,
 		ArtifactsId:   artifactsId,
 		PdfName:       artifacts.PDF,
@@ -17,43 +17,119 @@ limitations under the License.
 package ci
 
 import (
+	"bytes"
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
 
 	"github.com/zoomoid/assignments/v1/internal/bundle"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/naming"
 )
 
+// plainScalar matches strings that are safe to use as plain YAML scalars
+var plainScalar = regexp.MustCompile(`^[A-Za-z0-9_.\-][A-Za-z0-9_.\-\[\]+]*$`)
+
 type Artifacts struct {
+	ID      string
 	PDF     string
 	Archive string
 }
 
-func archiveAndPdfName(assignment string, artifactsDirectory string, archiveNameTemplate string, ad map[string]interface{}) (*Artifacts, error) {
-	ad["_id"] = assignment
+// archiveAndPdfName resolves the assignment released by the tag and finds its
+// PDF and archive in the artifacts directory
+func archiveAndPdfName(ctx *context.AppContext, tag string, artifactsDirectory string) (*Artifacts, error) {
+	n := ctx.Naming()
+	number, err := n.Parse(tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release tag, %w", err)
+	}
+	id := n.ID(number)
+
+	assignmentCtx, err := ctx.ForAssignment(n.Name(id))
+	if err != nil {
+		return nil, err
+	}
+	spec := assignmentCtx.Configuration.Spec
+	archiveNameTemplate := ""
+	ad := make(map[string]interface{})
+	if spec.BundleOptions != nil {
+		archiveNameTemplate = spec.BundleOptions.Template
+		ad = spec.BundleOptions.Clone().Data
+		if ad == nil {
+			ad = make(map[string]interface{})
+		}
+	}
 	ad["_format"] = "*" // glob the archive name later so that the actual bundle's format is irrelevant
+	ad = bundle.ArchiveNameData(ad, n, id, spec.Roster(number), bundle.BundlerBackendZip)
+
 	archiveGlobName, err := bundle.MakeArchiveName(archiveNameTemplate, ad)
 	if err != nil {
 		return nil, err
 	}
-
 	absArchiveGlobName := filepath.Join(artifactsDirectory, archiveGlobName)
-
-	matches, err := filepath.Glob(absArchiveGlobName)
+	globMatches, err := filepath.Glob(absArchiveGlobName)
 	if err != nil {
 		return nil, err
 	}
+	// the glob may match the assignment's PDF, too
+	matches := []string{}
+	for _, m := range globMatches {
+		if filepath.Base(m) != n.PDF(id) {
+			matches = append(matches, m)
+		}
+	}
 	if len(matches) == 0 {
 		return nil, fmt.Errorf("cannot find archive in artifacts directory")
 	}
 	if len(matches) > 1 {
 		return nil, fmt.Errorf("archive name is ambiguous, can only export a single archive per tag")
 	}
-
 	archive := filepath.Base(matches[0])
-	pdf := fmt.Sprintf("assignment-%s.pdf", assignment)
-
 	return &Artifacts{
-		PDF:     pdf,
+		ID:      id,
+		PDF:     n.PDF(id),
 		Archive: archive,
 	}, nil
 }
+
+// tagRules holds the patterns matching release tags in CI templates
+type tagRules struct {
+	// TagFilters are Github Actions filter patterns
+	TagFilters []string
+	// TagPattern is a regular expression
+	TagPattern string
+}
+
+// renderTemplate executes a CI template with the tag rules of the naming scheme.
+// CI templates use [[ and ]] as delimiters, as the SCM providers' own expressions
+// use curly braces
+func renderTemplate(name string, tpl string, n *naming.Scheme) (string, error) {
+	tmpl, err := template.New(name).Delims("[[", "]]").Parse(strings.TrimSpace(tpl))
+	if err != nil {
+		return "", err
+	}
+	filters := []string{}
+	for _, f := range n.TagFilters() {
+		filters = append(filters, yamlScalar(f))
+	}
+	output := &bytes.Buffer{}
+	err = tmpl.Execute(output, tagRules{
+		TagFilters: filters,
+		TagPattern: n.TagPattern(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
+
+// yamlScalar quotes s if it cannot be used as plain YAML scalar
+func yamlScalar(s string) string {
+	if plainScalar.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// AssignmentConfigurationFileName is the name of the optional configuration file
+	// in an assignment's directory
+	AssignmentConfigurationFileName string = "assignment.yaml"
+)
+
+// AssignmentConfiguration is the optional configuration of a single assignment,
+// read from assignment.yaml in the assignment's directory
+type AssignmentConfiguration struct {
+	// Title is the assignment's title, available to templates as .Assignment.Title
+	Title string `json:"title,omitempty" yaml:"title,omitempty"`
+	// Due is the assignment's due date, which takes precedence over the due date
+	// recorded in .status.assignments
+	Due *time.Time `json:"due,omitempty" yaml:"due,omitempty"`
+	// Spec is deep-merged over the repository's .spec for this assignment. Nested
+	// maps are merged, all other values, including lists, are replaced
+	Spec map[string]interface{} `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+// ReadAssignment reads the assignment configuration file in dir. Returns nil
+// without error if there is none
+func ReadAssignment(dir string) (*AssignmentConfiguration, error) {
+	p := filepath.Join(dir, AssignmentConfigurationFileName)
+	b, err := os.ReadFile(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	a := &AssignmentConfiguration{}
+	if err := yaml.UnmarshalStrict(b, a); err != nil {
+		return nil, fmt.Errorf("failed to read %s, %w", p, err)
+	}
+	return a, nil
+}
+
+// MergeSpec returns a copy of spec with overrides deep-merged over it, see MergeData.
+// The spec passed is not modified
+func MergeSpec(spec *ConfigurationSpec, overrides map[string]interface{}) (*ConfigurationSpec, error) {
+	if len(overrides) == 0 {
+		return spec.Clone(), nil
+	}
+	b, err := yaml.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	base := map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &base); err != nil {
+		return nil, err
+	}
+	b, err = yaml.Marshal(MergeData(base, overrides))
+	if err != nil {
+		return nil, err
+	}
+	merged := &ConfigurationSpec{}
+	if err := yaml.UnmarshalStrict(b, merged); err != nil {
+		return nil, fmt.Errorf("invalid spec overrides, %w", err)
+	}
+	return merged, nil
+}
+
+// ForAssignment returns a copy of the configuration with the assignment's
+// configuration applied, i.e., its spec merged over .spec and its due date
+// recorded in .status.assignments. Returns the configuration itself if a is nil
+func (c *Configuration) ForAssignment(id string, a *AssignmentConfiguration) (*Configuration, error) {
+	if a == nil {
+		return c, nil
+	}
+	spec, err := MergeSpec(c.Spec, a.Spec)
+	if err != nil {
+		return nil, err
+	}
+	// the naming scheme locates the assignment in the first place and thus cannot
+	// be changed per assignment
+	spec.NamingOptions = nil
+	if c.Spec.NamingOptions != nil {
+		spec.NamingOptions = c.Spec.NamingOptions.Clone()
+	}
+	nc := c.Clone()
+	nc.Spec = spec
+	if a.Due != nil {
+		if nc.Status == nil {
+			nc.Status = &ConfigurationStatus{}
+		}
+		due := *a.Due
+		nc.Status.Upsert(id).Due = &due
+	}
+	return nc, nil
+}
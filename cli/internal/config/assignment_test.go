@@ -0,0 +1,107 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMergeSpec(t *testing.T) {
+	spec := &ConfigurationSpec{
+		Course:  "LA",
+		Group:   "G",
+		Members: []GroupMember{{Name: "Max Mustermann", ID: "1"}},
+		BundleOptions: &BundleOptions{
+			Template: "{{._id}}.{{._format}}",
+			Include:  []string{"code/*"},
+		},
+	}
+	merged, err := MergeSpec(spec, map[string]interface{}{
+		"course": "Linear Algebra",
+		"bundle": map[interface{}]interface{}{
+			"include": []interface{}{"extra/*"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Course != "Linear Algebra" || merged.Group != "G" {
+		t.Errorf("expected course to be replaced and group to be kept, found %s, %s", merged.Course, merged.Group)
+	}
+	if len(merged.Members) != 1 {
+		t.Errorf("expected members to be kept, found %v", merged.Members)
+	}
+	if merged.BundleOptions.Template != spec.BundleOptions.Template {
+		t.Errorf("expected nested bundle template to be kept, found %s", merged.BundleOptions.Template)
+	}
+	if len(merged.BundleOptions.Include) != 1 || merged.BundleOptions.Include[0] != "extra/*" {
+		t.Errorf("expected bundle includes to be replaced, found %v", merged.BundleOptions.Include)
+	}
+	if spec.Course != "LA" || spec.BundleOptions.Include[0] != "code/*" {
+		t.Error("expected original spec not to be modified")
+	}
+
+	if _, err := MergeSpec(spec, map[string]interface{}{"unknown": true}); err == nil {
+		t.Error("expected unknown fields to be rejected")
+	}
+}
+
+func TestForAssignment(t *testing.T) {
+	dir := t.TempDir()
+	a, err := ReadAssignment(dir)
+	if err != nil || a != nil {
+		t.Fatalf("expected no assignment configuration, found %v, %v", a, err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, AssignmentConfigurationFileName), []byte(`title: Linear maps
+due: 2022-05-02T12:00:00Z
+spec:
+  group: H
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err = ReadAssignment(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Title != "Linear maps" {
+		t.Errorf("expected title %s, found %s", "Linear maps", a.Title)
+	}
+
+	c := &Configuration{
+		Spec:   &ConfigurationSpec{Course: "LA", Group: "G"},
+		Status: &ConfigurationStatus{},
+	}
+	nc, err := c.ForAssignment("02", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nc.Spec.Group != "H" || nc.Spec.Course != "LA" {
+		t.Errorf("expected merged spec, found %+v", nc.Spec)
+	}
+	due := nc.Status.Lookup("02")
+	if due == nil || due.Due == nil || !due.Due.Equal(time.Date(2022, 5, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected due date to be applied, found %v", due)
+	}
+	if c.Spec.Group != "G" || c.Status.Lookup("02") != nil {
+		t.Error("expected original configuration not to be modified")
+	}
+}
@@ -16,7 +16,16 @@ limitations under the License.
 
 package config
 
+import (
+	"fmt"
+	"time"
+)
+
 type Configuration struct {
+	// APIVersion is the version of the configuration's schema, see Migrate
+	APIVersion string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	// Kind identifies the file as assignmentctl's configuration
+	Kind   string               `json:"kind,omitempty" yaml:"kind,omitempty"`
 	Spec   *ConfigurationSpec   `json:"spec,omitempty" yaml:"spec,omitempty"`
 	Status *ConfigurationStatus `json:"status,omitempty" yaml:"status,omitempty"`
 }
@@ -41,13 +50,74 @@ type ConfigurationSpec struct {
 	BuildOptions *BuildOptions `json:"build,omitempty" yaml:"build,omitempty"`
 	// BundleOptions are user options for bundling
 	BundleOptions *BundleOptions `json:"bundle,omitempty" yaml:"bundle,omitempty"`
+	// DueOptions configure parsing and formatting of due dates
+	DueOptions *DueOptions `json:"due,omitempty" yaml:"due,omitempty"`
+	// CalendarOptions configure the export of deadlines to iCalendar files
+	CalendarOptions *CalendarOptions `json:"calendar,omitempty" yaml:"calendar,omitempty"`
+	// NamingOptions configure the names of assignment directories, artifacts, and tags
+	NamingOptions *NamingOptions `json:"naming,omitempty" yaml:"naming,omitempty"`
+	// TrashOptions configure how long snapshots taken before destructive operations
+	// are kept
+	TrashOptions *TrashOptions `json:"trash,omitempty" yaml:"trash,omitempty"`
+	// Locale is the language used for formatting dates and ordinals in the sheet
+	// template, e.g., "de". Defaults to "en"
+	Locale string `json:"locale,omitempty" yaml:"locale,omitempty"`
+	// Data contains arbitrary data passed to the sheet template as .Data, e.g.,
+	// the tutor's name or the semester. Can be overridden per assignment
+	Data map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
+// DueOptions contains configuration for due dates
+type DueOptions struct {
+	// Timezone is the IANA name of the course's timezone, e.g., Europe/Berlin,
+	// in which due dates are interpreted. Defaults to the local timezone
+	Timezone string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	// Time is the time of day for due dates given without time, defaults to 23:59
+	Time string `json:"time,omitempty" yaml:"time,omitempty"`
+	// Layout is the Go time layout used to format due dates for the sheet template,
+	// e.g., "Monday, January 2, 15:04". Defaults to the locale's date layout
+	Layout string `json:"layout,omitempty" yaml:"layout,omitempty"`
+	// Warn is the time before a due date in which build and bundle warn about the
+	// upcoming deadline, e.g., "48h" or "3d". Defaults to 48h, "none" disables warnings
+	Warn string `json:"warn,omitempty" yaml:"warn,omitempty"`
+}
+
+// NamingOptions contains configuration for naming assignments
+type NamingOptions struct {
+	// Template is a Golang template for the name of an assignment's directory,
+	// which must contain the assignment's padded number as {{.ID}} exactly once,
+	// e.g., "sheet-{{.ID}}". Defaults to "assignment-{{.ID}}"
+	Template string `json:"template,omitempty" yaml:"template,omitempty"`
+	// Width is the number of digits assignment numbers are padded to with
+	// leading zeros. Defaults to 2
+	Width int `json:"width,omitempty" yaml:"width,omitempty"`
+}
+
+// TrashOptions contains the retention policy of snapshots in .assignments/trash/
+type TrashOptions struct {
+	// Retention is the age after which snapshots are deleted, e.g., "14d" or "72h".
+	// Defaults to 30d, "none" keeps snapshots forever
+	Retention string `json:"retention,omitempty" yaml:"retention,omitempty"`
+	// Keep is the maximum number of snapshots kept, deleting the oldest ones first.
+	// Zero keeps any number of snapshots
+	Keep int `json:"keep,omitempty" yaml:"keep,omitempty"`
+}
+
+// CalendarOptions contains configuration for exporting deadlines to calendars
+type CalendarOptions struct {
+	// Alarm is the time before a deadline at which calendar applications remind,
+	// e.g., "24h" or "2d". Defaults to 24h, "none" disables reminders
+	Alarm string `json:"alarm,omitempty" yaml:"alarm,omitempty"`
+	// Pattern is the regular expression mapping the summaries of imported events to
+	// assignment numbers, captured by its first group or the group named "number"
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
 }
 
 type Include struct {
 	// Path defines a relative path for additional files to include in a TeX template
 	// They are included as literals in the template, thus should be relative to
 	// the assignment TeX file
-	Path string `json:"path" yaml:"path"`
+	Path string `json:"path" yaml:"path" jsonschema:"required"`
 }
 
 // BundleOptions contains configuration for bundling
@@ -63,6 +133,24 @@ type BundleOptions struct {
 type GenerateOptions struct {
 	// Create defines a list of bare directories to create when generating a new assignment
 	Create []string `json:"create" yaml:"create"`
+	// Exercises defines the default exercise skeletons of a new assignment
+	Exercises []Exercise `json:"exercises,omitempty" yaml:"exercises,omitempty"`
+	// Template is the name of the template pack in .assignments/templates/ used
+	// to generate new assignments, unless overridden by flags
+	Template string `json:"template,omitempty" yaml:"template,omitempty"`
+	// SplitExercises renders each exercise into its own exercise-N.tex file,
+	// which is then \input from the assignment's main source file
+	SplitExercises bool `json:"splitExercises,omitempty" yaml:"splitExercises,omitempty"`
+}
+
+// Exercise is a skeleton for an \exercise in a generated assignment
+type Exercise struct {
+	// Title of the exercise, may be empty
+	Title string `json:"title,omitempty" yaml:"title,omitempty"`
+	// Points awarded for the exercise, omitted from the template if zero
+	Points float64 `json:"points,omitempty" yaml:"points,omitempty"`
+	// Subexercises is the number of \subexercise skeletons to add to the exercise
+	Subexercises int `json:"subexercises,omitempty" yaml:"subexercises,omitempty"`
 }
 
 type BuildOptions struct {
@@ -93,7 +181,7 @@ type Recipe []Tool
 
 type Tool struct {
 	// Program name of the LaTeX compiler (or proxy) to use
-	Command string `json:"command" yaml:"command"`
+	Command string `json:"command" yaml:"command" jsonschema:"required"`
 	// Argument list for the compiler
 	Args []string `json:"args" yaml:"args"`
 }
@@ -101,20 +189,137 @@ type Tool struct {
 // GroupMembers are part of an assignments group
 type GroupMember struct {
 	// Name is the group member's full name
-	Name string `json:"name" yaml:"name"`
+	Name string `json:"name" yaml:"name" jsonschema:"required"`
 	// ID is the group member's student ID or else
 	ID string `json:"id" yaml:"id"`
+	// From is the number of the first assignment the member is part of the
+	// group. Zero means from the first assignment on
+	From uint32 `json:"from,omitempty" yaml:"from,omitempty"`
+	// Until is the number of the last assignment the member is part of the
+	// group. Zero means until the last assignment
+	Until uint32 `json:"until,omitempty" yaml:"until,omitempty"`
+}
+
+// MemberOf returns true if the member is part of the group for the given assignment.
+// Suffixed IDs count as their number, e.g., 5a as 5. Named assignments are outside
+// the sequence and thus belong to the current group, i.e., all members without until
+func (g *GroupMember) MemberOf(assignment AssignmentID) bool {
+	if assignment.Named() {
+		return g.Until == 0
+	}
+	if g.From != 0 && assignment.Number < g.From {
+		return false
+	}
+	if g.Until != 0 && assignment.Number > g.Until {
+		return false
+	}
+	return true
+}
+
+// Roster returns the group members that are part of the group for the given
+// assignment, see GroupMember.MemberOf
+func (c *ConfigurationSpec) Roster(assignment AssignmentID) []GroupMember {
+	roster := []GroupMember{}
+	for _, m := range c.Members {
+		if m.MemberOf(assignment) {
+			roster = append(roster, m)
+		}
+	}
+	return roster
 }
 
 // ConfigurationStatus contains the fields permutated by commands other than the bootstrapping
 type ConfigurationStatus struct {
-	// Assignment records the current assignment number
-	Assignment uint32 `json:"assignment,omitempty" yaml:"assignment,omitempty"`
+	// Assignment records the current assignment, whose successor is generated next
+	Assignment AssignmentID `json:"assignment,omitempty" yaml:"assignment,omitempty"`
+	// Assignments contains metadata of individual assignments, keyed by the
+	// assignment's ID with leading zeros, e.g., "05", "05a", or "bonus"
+	Assignments map[string]*AssignmentStatus `json:"assignments,omitempty" yaml:"assignments,omitempty"`
+}
+
+// AssignmentStatus is the metadata recorded for a single assignment
+type AssignmentStatus struct {
+	// Due is the assignment's due date, stored in RFC3339 format
+	Due *time.Time `json:"due,omitempty" yaml:"due,omitempty"`
+	// State is the assignment's current lifecycle state
+	State State `json:"state,omitempty" yaml:"state,omitempty"`
+	// History contains all lifecycle transitions of the assignment, oldest first
+	History []Transition `json:"history,omitempty" yaml:"history,omitempty"`
+	// Data overrides the keys of .spec.data for this assignment
+	Data map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
+// Lookup returns the metadata of the assignment with the given id, or nil if
+// there is none
+func (c *ConfigurationStatus) Lookup(id string) *AssignmentStatus {
+	if c == nil || c.Assignments == nil {
+		return nil
+	}
+	return c.Assignments[id]
+}
+
+// Upsert returns the metadata of the assignment with the given id, creating
+// an empty entry if there is none yet
+func (c *ConfigurationStatus) Upsert(id string) *AssignmentStatus {
+	if c.Assignments == nil {
+		c.Assignments = make(map[string]*AssignmentStatus)
+	}
+	a, ok := c.Assignments[id]
+	if !ok || a == nil {
+		a = &AssignmentStatus{}
+		c.Assignments[id] = a
+	}
+	return a
+}
+
+// MergeData merges maps of template data. Keys of later maps take precedence over
+// earlier ones. Nested maps are merged recursively, all other values, including
+// lists, are replaced. The maps passed are not modified.
+func MergeData(data ...map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for _, d := range data {
+		for k, v := range d {
+			merged[k] = mergeValue(merged[k], v)
+		}
+	}
+	return merged
+}
+
+// mergeValue merges override into base if both are maps, and returns override otherwise
+func mergeValue(base interface{}, override interface{}) interface{} {
+	b, ok := normalizeMap(base)
+	if !ok {
+		return override
+	}
+	o, ok := normalizeMap(override)
+	if !ok {
+		return override
+	}
+	return MergeData(b, o)
+}
+
+// normalizeMap converts the map[interface{}]interface{} produced by YAML decoding
+// into a map[string]interface{}
+func normalizeMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		n := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			n[fmt.Sprint(k)] = v
+		}
+		return n, true
+	}
+	return nil, false
 }
 
+// Minimal returns an empty configuration in the current API version
 func Minimal() *Configuration {
 	return &Configuration{
-		Spec:   &ConfigurationSpec{},
-		Status: &ConfigurationStatus{},
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		Spec:       &ConfigurationSpec{},
+		Status:     &ConfigurationStatus{},
 	}
 }
@@ -21,6 +21,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 
 	"gopkg.in/yaml.v2"
 )
@@ -32,22 +33,32 @@ const (
 
 var (
 	ErrNoConfigmap error = errors.New("failed to find configmap in working directory or above")
+
+	// linePrefix matches the line numbers in yaml.TypeError's errors
+	linePrefix = regexp.MustCompile(`^line [0-9]+: `)
 )
 
-// Read reads in a config file an unmarshals it into a configuration struct
+// Read reads in a config file an unmarshals it into a configuration struct,
+// upgrading it to the current API version and ignoring unknown fields
 func Read(path string) (*Configuration, error) {
-	config := &Configuration{}
+	config, _, err := Load(path, false)
+	return config, err
+}
+
+// Load reads in a config file like Read, but also returns the migrations applied
+// to upgrade it to the current API version. In strict mode, unknown fields are
+// errors, see Decode
+func Load(path string, strict bool) (*Configuration, []Migration, error) {
 	fd, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	defer fd.Close()
 	in, err := io.ReadAll(fd)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-
-	err = Unmarshal(in, config)
-	return config, err
+	return Decode(in, strict)
 }
 
 // Write marshals a configuration struct into YAML and writes it to the designated file
@@ -90,9 +101,61 @@ func Find(start string) (string, error) {
 	return "", ErrNoConfigmap
 }
 
-// Unmarshal implements yaml unmarshalling for configuration structs
+// Decode unmarshals a configuration from YAML and upgrades it to the current API
+// version in memory, see Migrate. Returns the migrations applied. The returned
+// configuration keeps the document's apiVersion and kind, such that writing it
+// does not upgrade the file, which is up to "assignmentctl config migrate". In
+// strict mode, fields unknown to the configuration are errors, otherwise they are
+// ignored
+func Decode(in []byte, strict bool) (*Configuration, []Migration, error) {
+	doc := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(in, &doc); err != nil {
+		return nil, nil, err
+	}
+	if doc == nil {
+		// the file is empty
+		doc = map[interface{}]interface{}{}
+	}
+	version, _ := doc["apiVersion"].(string)
+	kind, _ := doc["kind"].(string)
+	applied, err := Migrate(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(applied) > 0 {
+		// decode the original otherwise, such that errors refer to its lines
+		if in, err = yaml.Marshal(doc); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	unmarshal := yaml.Unmarshal
+	if strict {
+		unmarshal = yaml.UnmarshalStrict
+	}
+	config := &Configuration{}
+	if err := unmarshal(in, config); err != nil {
+		var typeErr *yaml.TypeError
+		if len(applied) > 0 && errors.As(err, &typeErr) {
+			// lines of the migrated document do not match those of the file
+			for i, e := range typeErr.Errors {
+				typeErr.Errors[i] = linePrefix.ReplaceAllString(e, "")
+			}
+		}
+		return nil, applied, err
+	}
+	config.APIVersion, config.Kind = version, kind
+	return config, applied, nil
+}
+
+// Unmarshal implements yaml unmarshalling for configuration structs, see Decode
 func Unmarshal(in []byte, out *Configuration) error {
-	return yaml.Unmarshal(in, out)
+	config, _, err := Decode(in, false)
+	if err != nil {
+		return err
+	}
+	*out = *config
+	return nil
 }
 
 // Marshal implements yaml marshalling for configuration structs
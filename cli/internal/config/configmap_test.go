@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/lithammer/dedent"
@@ -85,7 +86,7 @@ func TestWrite(t *testing.T) {
 			}},
 		},
 		Status: &ConfigurationStatus{
-			Assignment: 1,
+			Assignment: NumberedID(1),
 		},
 	}
 	defer os.Remove(configFile)
@@ -120,7 +121,7 @@ func TestWriteThenRead(t *testing.T) {
 			}},
 		},
 		Status: &ConfigurationStatus{
-			Assignment: 1,
+			Assignment: NumberedID(1),
 		},
 	}
 	defer os.Remove(configFile)
@@ -135,7 +136,7 @@ func TestWriteThenRead(t *testing.T) {
 	}
 
 	if config.Status.Assignment != readConfig.Status.Assignment {
-		t.Fatalf("Failed to read config back in, differing values, expected .Status.Assignment to be %d, got %d", config.Status.Assignment, readConfig.Status.Assignment)
+		t.Fatalf("Failed to read config back in, differing values, expected .Status.Assignment to be %s, got %s", config.Status.Assignment, readConfig.Status.Assignment)
 	}
 }
 
@@ -219,7 +220,7 @@ func TestMarshal(t *testing.T) {
 			Group:  "Test Group",
 		},
 		Status: &ConfigurationStatus{
-			Assignment: 1,
+			Assignment: NumberedID(1),
 		},
 	}
 	out, err := Marshal(config)
@@ -262,7 +263,80 @@ status:
 	if out.Spec.Group != "Test Group" {
 		t.Fatal(fmt.Errorf("expected %s, found %s", "Test Group", out.Spec.Group))
 	}
-	if out.Status.Assignment != 1 {
-		t.Fatal(fmt.Errorf("expected %d, found %d", 1, out.Status.Assignment))
+	if out.Status.Assignment != NumberedID(1) {
+		t.Fatal(fmt.Errorf("expected %d, found %s", 1, out.Status.Assignment))
+	}
+}
+
+func TestMergeData(t *testing.T) {
+	marshalledConfig := dedent.Dedent(`
+spec:
+  data:
+    tutor: Jane Doe
+    slot:
+      day: Monday
+      time: "10:00"
+status:
+  assignments:
+    "03":
+      data:
+        slot:
+          time: "12:00"
+	`)
+	out := Configuration{}
+	err := Unmarshal([]byte(marshalledConfig), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := out.Status.Lookup("03")
+	if status == nil {
+		t.Fatal("expected metadata for assignment 03")
+	}
+	if out.Status.Lookup("04") != nil {
+		t.Fatal("expected no metadata for assignment 04")
+	}
+
+	data := MergeData(out.Spec.Data, status.Data)
+	if data["tutor"] != "Jane Doe" {
+		t.Errorf("expected %s, found %v", "Jane Doe", data["tutor"])
+	}
+	slot, ok := data["slot"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected nested map, found %T", data["slot"])
+	}
+	if slot["day"] != "Monday" || slot["time"] != "12:00" {
+		t.Errorf("expected merged slot Monday 12:00, found %v", slot)
+	}
+	if out.Spec.Data["slot"].(map[interface{}]interface{})["time"] != "10:00" {
+		t.Error("expected MergeData not to modify its arguments")
+	}
+}
+
+func TestRoster(t *testing.T) {
+	spec := &ConfigurationSpec{
+		Members: []GroupMember{
+			{Name: "Max Mustermann", ID: "1"},
+			{Name: "Erika Musterfrau", ID: "2", Until: 4},
+			{Name: "Ludwig van Beethoven", ID: "3", From: 5},
+			{Name: "Clara Schumann", ID: "4", From: 3, Until: 6},
+		},
+	}
+	cases := map[AssignmentID][]string{
+		NumberedID(1):            {"1", "2"},
+		NumberedID(3):            {"1", "2", "4"},
+		{Number: 4, Suffix: "b"}: {"1", "2", "4"},
+		NumberedID(5):            {"1", "3", "4"},
+		NumberedID(7):            {"1", "3"},
+		{Name: "bonus"}:          {"1", "3"},
+	}
+	for n, ids := range cases {
+		roster := spec.Roster(n)
+		found := []string{}
+		for _, m := range roster {
+			found = append(found, m.ID)
+		}
+		if strings.Join(found, ",") != strings.Join(ids, ",") {
+			t.Errorf("expected roster %v for assignment %s, found %v", ids, n, found)
+		}
 	}
 }
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// numberedIDPattern matches numbered assignment IDs with an optional suffix, e.g., 5, 05, or 05a
+	numberedIDPattern = regexp.MustCompile(`^([0-9]+)([a-z]?)$`)
+	// namedIDPattern matches named assignment IDs, e.g., bonus or exam-prep
+	namedIDPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+)
+
+const (
+	// IDSuffixPattern is a regular expression for the optional suffix of numbered
+	// IDs following the number, for use in other patterns
+	IDSuffixPattern string = "[a-z]?"
+	// NamedIDPattern is a regular expression for named IDs, for use in other patterns
+	NamedIDPattern string = "[A-Za-z][A-Za-z0-9_-]*"
+)
+
+// AssignmentID identifies an assignment. Numbered IDs consist of a number and an
+// optional single-letter suffix for sheets in multiple parts, e.g., 5, 5a, and
+// 5b. Named IDs, e.g., bonus or exam-prep, are for assignments outside the
+// regular sequence. The zero value is the numbered ID 0, i.e., no assignment
+type AssignmentID struct {
+	// Number is the assignment's number, zero for named IDs
+	Number uint32
+	// Suffix is the lowercase letter following the number, if any
+	Suffix string
+	// Name is the name of named IDs, empty for numbered ones
+	Name string
+}
+
+// NumberedID returns the numbered ID without suffix
+func NumberedID(n uint32) AssignmentID {
+	return AssignmentID{Number: n}
+}
+
+// ParseAssignmentID parses numbered IDs, e.g., "5", "05" or "05a", and named IDs,
+// which start with a letter, e.g., "bonus"
+func ParseAssignmentID(s string) (AssignmentID, error) {
+	if m := numberedIDPattern.FindStringSubmatch(s); m != nil {
+		n, err := strconv.ParseUint(m[1], 10, 32)
+		if err != nil {
+			return AssignmentID{}, fmt.Errorf("invalid assignment %q, %w", s, err)
+		}
+		return AssignmentID{Number: uint32(n), Suffix: m[2]}, nil
+	}
+	if namedIDPattern.MatchString(s) {
+		return AssignmentID{Name: s}, nil
+	}
+	return AssignmentID{}, fmt.Errorf("invalid assignment %q, use a number with an optional letter, e.g., 5 or 5a, or a name, e.g., bonus", s)
+}
+
+// Named returns true for named IDs
+func (a AssignmentID) Named() bool {
+	return a.Name != ""
+}
+
+// IsZero returns true for the zero value
+func (a AssignmentID) IsZero() bool {
+	return a == AssignmentID{}
+}
+
+// String returns the ID without padding, e.g., "5a" or "bonus"
+func (a AssignmentID) String() string {
+	return a.Pad(0)
+}
+
+// Pad returns the ID with its number padded with leading zeros to width digits,
+// e.g., "05a" for width 2. Named IDs are returned as is
+func (a AssignmentID) Pad(width int) string {
+	if a.Named() {
+		return a.Name
+	}
+	return fmt.Sprintf("%0*d%s", width, a.Number, a.Suffix)
+}
+
+// Next returns the numbered ID following the ID's number, e.g., 6 for 5 and 5b.
+// Named IDs have no successor
+func (a AssignmentID) Next() (AssignmentID, error) {
+	if a.Named() {
+		return AssignmentID{}, fmt.Errorf("named assignment %s has no successor", a.Name)
+	}
+	return NumberedID(a.Number + 1), nil
+}
+
+// Compare orders IDs by number, then suffix, with the unsuffixed ID first, e.g.,
+// 5 < 5a < 5b < 6. Named IDs follow all numbered ones in alphabetical order.
+// Returns -1, 0, or 1 if a is less, equal, or greater than b
+func (a AssignmentID) Compare(b AssignmentID) int {
+	if a.Named() != b.Named() {
+		if a.Named() {
+			return 1
+		}
+		return -1
+	}
+	if a.Named() {
+		return strings.Compare(a.Name, b.Name)
+	}
+	if a.Number != b.Number {
+		if a.Number < b.Number {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a.Suffix, b.Suffix)
+}
+
+// Less returns true if a is ordered before b, see Compare
+func (a AssignmentID) Less(b AssignmentID) bool {
+	return a.Compare(b) < 0
+}
+
+// value returns the number for IDs without suffix, such that these are written
+// as numbers to the configuration file, and the ID's string otherwise
+func (a AssignmentID) value() interface{} {
+	if !a.Named() && a.Suffix == "" {
+		return a.Number
+	}
+	return a.String()
+}
+
+func (a AssignmentID) MarshalYAML() (interface{}, error) {
+	return a.value(), nil
+}
+
+func (a *AssignmentID) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	id, err := ParseAssignmentID(s)
+	if err != nil {
+		return err
+	}
+	*a = id
+	return nil
+}
+
+func (a AssignmentID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.value())
+}
+
+func (a *AssignmentID) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	id, err := ParseAssignmentID(s)
+	if err != nil {
+		return err
+	}
+	*a = id
+	return nil
+}
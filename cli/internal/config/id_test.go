@@ -0,0 +1,124 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestParseAssignmentID(t *testing.T) {
+	cases := map[string]AssignmentID{
+		"5":         NumberedID(5),
+		"05":        NumberedID(5),
+		"05a":       {Number: 5, Suffix: "a"},
+		"12b":       {Number: 12, Suffix: "b"},
+		"bonus":     {Name: "bonus"},
+		"exam-prep": {Name: "exam-prep"},
+	}
+	for s, expected := range cases {
+		id, err := ParseAssignmentID(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != expected {
+			t.Errorf("expected %s to parse to %+v, found %+v", s, expected, id)
+		}
+	}
+	for _, s := range []string{"", "5ab", "5A", "-bonus", "05 a", "bonus!"} {
+		if _, err := ParseAssignmentID(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+
+	id := AssignmentID{Number: 5, Suffix: "a"}
+	if p := id.Pad(2); p != "05a" {
+		t.Errorf("expected 05a, found %s", p)
+	}
+	if s := id.String(); s != "5a" {
+		t.Errorf("expected 5a, found %s", s)
+	}
+}
+
+func TestAssignmentIDOrder(t *testing.T) {
+	ids := []AssignmentID{
+		{Name: "exam-prep"},
+		NumberedID(10),
+		{Number: 5, Suffix: "b"},
+		{Name: "bonus"},
+		NumberedID(5),
+		{Number: 5, Suffix: "a"},
+		NumberedID(2),
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].Less(ids[j])
+	})
+	expected := []string{"2", "5", "5a", "5b", "10", "bonus", "exam-prep"}
+	for i, id := range ids {
+		if id.String() != expected[i] {
+			t.Errorf("expected %s at position %d, found %s", expected[i], i, id)
+		}
+	}
+
+	next, err := AssignmentID{Number: 5, Suffix: "b"}.Next()
+	if err != nil || next != NumberedID(6) {
+		t.Errorf("expected 6 to follow 5b, found %s, %v", next, err)
+	}
+	if _, err := (AssignmentID{Name: "bonus"}).Next(); err == nil {
+		t.Error("expected named IDs to have no successor")
+	}
+}
+
+func TestAssignmentIDMarshal(t *testing.T) {
+	status := ConfigurationStatus{Assignment: NumberedID(3)}
+	b, err := yaml.Marshal(status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "assignment: 3\n" {
+		t.Errorf("expected numeric ID to be written as number, found %q", string(b))
+	}
+
+	for _, id := range []AssignmentID{NumberedID(3), {Number: 5, Suffix: "a"}, {Name: "bonus"}} {
+		b, err := yaml.Marshal(ConfigurationStatus{Assignment: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := ConfigurationStatus{}
+		if err := yaml.Unmarshal(b, &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.Assignment != id {
+			t.Errorf("expected %s after YAML round trip, found %s", id, out.Assignment)
+		}
+
+		b, err = json.Marshal(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fromJSON AssignmentID
+		if err := json.Unmarshal(b, &fromJSON); err != nil {
+			t.Fatal(err)
+		}
+		if fromJSON != id {
+			t.Errorf("expected %s after JSON round trip, found %s", id, fromJSON)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// State is a step in an assignment's lifecycle
+type State string
+
+const (
+	// StateGenerated is recorded by generate
+	StateGenerated State = "generated"
+	// StateInProgress marks assignments that are being worked on
+	StateInProgress State = "in-progress"
+	// StateBuilt is recorded by build, together with the PDF's checksum
+	StateBuilt State = "built"
+	// StateBundled is recorded by bundle, together with the archive's checksum
+	StateBundled State = "bundled"
+	// StateSubmitted marks assignments that were handed in
+	StateSubmitted State = "submitted"
+	// StateGraded marks assignments that were returned with grades
+	StateGraded State = "graded"
+)
+
+var (
+	// States lists all lifecycle states in order
+	States []State = []State{StateGenerated, StateInProgress, StateBuilt, StateBundled, StateSubmitted, StateGraded}
+)
+
+// Transition is a single entry in an assignment's lifecycle history
+type Transition struct {
+	// State is the state the assignment transitioned to
+	State State `json:"state" yaml:"state" jsonschema:"required"`
+	// Timestamp is the time of the transition
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp" jsonschema:"required"`
+	// Checksum is the checksum of the artifact produced by the transition, i.e., the
+	// PDF for built and the archive for bundled
+	Checksum string `json:"checksum,omitempty" yaml:"checksum,omitempty"`
+	// Late is true for transitions after the assignment's due date, i.e., bundling
+	// an assignment with --late
+	Late bool `json:"late,omitempty" yaml:"late,omitempty"`
+}
+
+// ParseState returns the state with the given name, or an error listing all
+// valid states
+func ParseState(name string) (State, error) {
+	for _, s := range States {
+		if string(s) == name {
+			return s, nil
+		}
+	}
+	names := make([]string, len(States))
+	for i, s := range States {
+		names[i] = string(s)
+	}
+	return "", fmt.Errorf("unknown state %q, use one of %s", name, strings.Join(names, ", "))
+}
+
+// Manual returns true for states that are only ever set by users, as opposed to
+// the states recorded automatically by generate, build, and bundle
+func (s State) Manual() bool {
+	return s == StateSubmitted || s == StateGraded
+}
+
+// Transition records an automatic transition into state in the assignment's
+// history and makes it the current state. Automatic transitions, e.g., rebuilding
+// a submitted assignment, are recorded, but do not replace a manual state.
+// Repeating the latest transition with the same artifact, e.g., rebuilding an
+// unchanged assignment, is not recorded again, such that the history does not
+// grow with every build
+func (a *AssignmentStatus) Transition(state State, at time.Time, checksum string) {
+	if n := len(a.History); n > 0 && checksum != "" {
+		if last := a.History[n-1]; last.State == state && last.Checksum == checksum {
+			return
+		}
+	}
+	a.record(state, at, checksum)
+	if a.State.Manual() && !state.Manual() {
+		return
+	}
+	a.State = state
+}
+
+// Mark records a transition set by the user, which always replaces the current state
+func (a *AssignmentStatus) Mark(state State, at time.Time) {
+	a.record(state, at, "")
+	a.State = state
+}
+
+func (a *AssignmentStatus) record(state State, at time.Time, checksum string) {
+	a.History = append(a.History, Transition{
+		State:     state,
+		Timestamp: at.Truncate(time.Second),
+		Checksum:  checksum,
+	})
+}
+
+// Last returns the most recent transition into state, or nil if the assignment
+// has never been in state
+func (a *AssignmentStatus) Last(state State) *Transition {
+	for i := len(a.History) - 1; i >= 0; i-- {
+		if a.History[i].State == state {
+			return &a.History[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLifecycle(t *testing.T) {
+	status := &ConfigurationStatus{}
+	a := status.Upsert("03")
+	now := time.Now()
+
+	a.Transition(StateGenerated, now, "")
+	a.Transition(StateBuilt, now, "sha256:aa")
+	if a.State != StateBuilt {
+		t.Errorf("expected state %s, found %s", StateBuilt, a.State)
+	}
+	a.Transition(StateBuilt, now.Add(time.Minute), "sha256:aa")
+	if len(a.History) != 2 {
+		t.Errorf("expected rebuilding the same PDF not to be recorded again, found %d transitions", len(a.History))
+	}
+
+	a.Mark(StateSubmitted, now)
+	a.Transition(StateBuilt, now, "sha256:bb")
+	if a.State != StateSubmitted {
+		t.Errorf("expected automatic transition not to replace %s, found %s", StateSubmitted, a.State)
+	}
+	if len(a.History) != 4 {
+		t.Fatalf("expected 4 transitions in history, found %d", len(a.History))
+	}
+	if last := a.Last(StateBuilt); last == nil || last.Checksum != "sha256:bb" {
+		t.Errorf("expected last build with checksum sha256:bb, found %+v", last)
+	}
+	if a.Last(StateGraded) != nil {
+		t.Error("expected no transition into graded")
+	}
+
+	a.Mark(StateInProgress, now)
+	if a.State != StateInProgress {
+		t.Errorf("expected manual mark to replace the state, found %s", a.State)
+	}
+
+	clone := status.Clone().Lookup("03")
+	clone.Transition(StateBundled, now, "")
+	if len(a.History) != 5 {
+		t.Error("expected clone not to share the history")
+	}
+
+	if _, err := ParseState("shipped"); err == nil {
+		t.Error("expected error for unknown state")
+	}
+	if s, err := ParseState("in-progress"); err != nil || s != StateInProgress {
+		t.Errorf("expected %s, found %s, %v", StateInProgress, s, err)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// APIVersion is the current version of the configuration's schema. Changing
+	// the schema incompatibly requires a new version and a migration to it
+	APIVersion string = "assignments.zoomoid.dev/v1"
+	// Kind identifies .assignments.yaml files
+	Kind string = "Configuration"
+)
+
+var (
+	// ErrUnsupportedVersion is returned for configurations of API versions without
+	// migration to the current one, e.g., those written by newer versions of
+	// assignmentctl
+	ErrUnsupportedVersion error = errors.New("unsupported apiVersion")
+)
+
+// Migration upgrades configurations of one API version to the next one
+type Migration struct {
+	// From is the API version the migration applies to, which is empty for
+	// configurations written before versioning
+	From string
+	// To is the API version after the migration
+	To string
+	// Description summarizes the changes to the configuration
+	Description string
+	// migrate changes the document in place, except for its apiVersion
+	migrate func(doc map[interface{}]interface{}) error
+}
+
+// migrations is the chain of migrations to APIVersion, oldest first
+var migrations = []Migration{
+	{
+		From:        "",
+		To:          "assignments.zoomoid.dev/v1",
+		Description: "adds apiVersion and kind, and moves a list of patterns in .spec.build.cleanup.glob to .spec.build.cleanup.glob.patterns",
+		migrate: func(doc map[interface{}]interface{}) error {
+			cleanup, ok := lookup(doc, "spec", "build", "cleanup")
+			if !ok {
+				return nil
+			}
+			if patterns, ok := cleanup["glob"].([]interface{}); ok {
+				cleanup["glob"] = map[interface{}]interface{}{"patterns": patterns}
+			}
+			return nil
+		},
+	},
+}
+
+// VersionName returns the API version for messages, i.e., "unversioned" for
+// configurations written before versioning
+func VersionName(version string) string {
+	if version == "" {
+		return "unversioned"
+	}
+	return version
+}
+
+// Migrate upgrades a configuration document, as unmarshalled from YAML, to the
+// current APIVersion in place, and returns the migrations applied, oldest first
+func Migrate(doc map[interface{}]interface{}) ([]Migration, error) {
+	kind, ok := doc["kind"]
+	if ok && kind != Kind {
+		return nil, fmt.Errorf("unsupported kind %v, expected %s", kind, Kind)
+	}
+	version := ""
+	if v, ok := doc["apiVersion"]; ok {
+		if version, ok = v.(string); !ok {
+			return nil, fmt.Errorf("%w %v, expected a string", ErrUnsupportedVersion, v)
+		}
+	}
+
+	applied := []Migration{}
+	for version != APIVersion {
+		m := migrationFrom(version)
+		if m == nil {
+			return nil, fmt.Errorf("%w %s, this version of assignmentctl supports %s and older", ErrUnsupportedVersion, VersionName(version), APIVersion)
+		}
+		if err := m.migrate(doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate configuration from %s to %s, %w", VersionName(m.From), m.To, err)
+		}
+		version = m.To
+		applied = append(applied, *m)
+	}
+	doc["apiVersion"] = APIVersion
+	doc["kind"] = Kind
+	return applied, nil
+}
+
+func migrationFrom(version string) *Migration {
+	for i := range migrations {
+		if migrations[i].From == version {
+			return &migrations[i]
+		}
+	}
+	return nil
+}
+
+// lookup returns the nested mapping at the path of keys, if all of them exist
+func lookup(doc map[interface{}]interface{}, keys ...string) (map[interface{}]interface{}, bool) {
+	current := doc
+	for _, k := range keys {
+		next, ok := current[k].(map[interface{}]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+	return current, true
+}
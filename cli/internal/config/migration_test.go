@@ -0,0 +1,101 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lithammer/dedent"
+)
+
+func TestDecode(t *testing.T) {
+	t.Run("unversioned", func(t *testing.T) {
+		cfg, applied, err := Decode([]byte(dedent.Dedent(`
+			spec:
+			  course: Linear Algebra I
+			  build:
+			    cleanup:
+			      glob:
+			        - "*.log"
+			        - "*.aux"
+			status:
+			  assignment: 3
+		`)), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(applied) != 1 || applied[0].From != "" || applied[0].To != APIVersion {
+			t.Errorf("expected migration from unversioned to %s, found %v", APIVersion, applied)
+		}
+		if cfg.APIVersion != "" || cfg.Kind != "" {
+			t.Errorf("expected the document's missing version to be kept, found %s %s", cfg.APIVersion, cfg.Kind)
+		}
+		patterns := cfg.Spec.BuildOptions.Cleanup.Glob.Patterns
+		if !reflect.DeepEqual(patterns, []string{"*.log", "*.aux"}) {
+			t.Errorf("expected glob list to be migrated to patterns, found %v", patterns)
+		}
+		if cfg.Status.Assignment != NumberedID(3) {
+			t.Errorf("expected assignment 3, found %s", cfg.Status.Assignment)
+		}
+	})
+
+	t.Run("current", func(t *testing.T) {
+		_, applied, err := Decode([]byte("apiVersion: "+APIVersion+"\nkind: Configuration\nspec:\n  course: Linear Algebra I\n"), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(applied) != 0 {
+			t.Errorf("expected no migrations, found %v", applied)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		in := []byte("apiVersion: " + APIVersion + "\nspec:\n  cours: Linear Algebra I\n")
+		if _, _, err := Decode(in, true); err == nil || !strings.Contains(err.Error(), "line 3: field cours not found") {
+			t.Errorf("expected unknown field with its line in strict mode, found %v", err)
+		}
+		cfg, _, err := Decode(in, false)
+		if err != nil {
+			t.Fatalf("expected unknown field to be ignored, found %v", err)
+		}
+		if cfg.Spec.Course != "" {
+			t.Errorf("expected empty course, found %s", cfg.Spec.Course)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		if _, _, err := Decode([]byte("apiVersion: assignments.zoomoid.dev/v99\n"), false); !errors.Is(err, ErrUnsupportedVersion) {
+			t.Errorf("expected unsupported version, found %v", err)
+		}
+		if _, _, err := Decode([]byte("apiVersion: "+APIVersion+"\nkind: Deployment\n"), false); err == nil {
+			t.Error("expected error for other kinds")
+		}
+	})
+}
+
+func TestMinimalWritesVersion(t *testing.T) {
+	out, err := Marshal(*Minimal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(out), "apiVersion: "+APIVersion+"\nkind: "+Kind+"\n") {
+		t.Errorf("expected apiVersion and kind first, found\n%s", out)
+	}
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Patch writes the configuration into the YAML document it was read from. Values
+// that did not change are kept as they are in the document, including comments,
+// the order of keys, and quoting, such that writing an unchanged configuration
+// reproduces the document, and changes only touch the lines of changed values.
+// New fields are inserted in the order of the configuration's fields, and removed
+// ones are dropped with their comments
+func Patch(document []byte, config *Configuration) ([]byte, error) {
+	out, err := Marshal(*config)
+	if err != nil {
+		return nil, err
+	}
+	var original, updated yamlv3.Node
+	if err := yamlv3.Unmarshal(document, &original); err != nil {
+		return nil, err
+	}
+	if len(original.Content) == 0 {
+		// the document is empty, there is nothing to preserve
+		return out, nil
+	}
+	if err := yamlv3.Unmarshal(out, &updated); err != nil {
+		return nil, err
+	}
+
+	// yaml.v2, which wrote .assignments.yaml before, does not indent lists in
+	// objects, whereas yaml.v3 always does. Keep the document's style
+	compact := hasCompactSequences(original.Content[0])
+	root := original.Content[0]
+	var first *yamlv3.Node
+	if root.Kind == yamlv3.MappingNode && len(root.Content) > 0 {
+		first = root.Content[0]
+	}
+	original.Content[0] = mergeNodes(root, updated.Content[0])
+	if root := original.Content[0]; first != nil && root.Kind == yamlv3.MappingNode && len(root.Content) > 0 && root.Content[0] != first {
+		// the comment at the top of the document stays there when fields are
+		// inserted before the first one, e.g., apiVersion by migrations
+		root.Content[0].HeadComment, first.HeadComment = first.HeadComment, ""
+	}
+
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&original); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	if !compact {
+		return buf.Bytes(), nil
+	}
+	return compactSequences(buf.Bytes())
+}
+
+// mergeNodes changes the original node to the updated one, keeping whatever is
+// equal in both. Returns the original node, or the updated one if it is new
+func mergeNodes(original *yamlv3.Node, updated *yamlv3.Node) *yamlv3.Node {
+	if original == nil {
+		return updated
+	}
+	if equalNodes(original, updated) {
+		return original
+	}
+	if original.Kind == updated.Kind && len(original.Content) == 0 && len(updated.Content) > 0 {
+		// an empty collection is written as {} or [], don't write its new items on
+		// the same line
+		original.Style = updated.Style
+	}
+	switch {
+	case original.Kind == yamlv3.MappingNode && updated.Kind == yamlv3.MappingNode:
+		mergeMappings(original, updated)
+		return original
+	case original.Kind == yamlv3.SequenceNode && updated.Kind == yamlv3.SequenceNode:
+		original.Content = mergeSequences(original.Content, updated.Content)
+		return original
+	case original.Kind == yamlv3.ScalarNode && updated.Kind == yamlv3.ScalarNode:
+		if original.Value == updated.Value {
+			// e.g., 123456 read into a string, which is written as "123456"
+			return original
+		}
+		quoted := original.Style&(yamlv3.SingleQuotedStyle|yamlv3.DoubleQuotedStyle) != 0
+		original.Value = updated.Value
+		if quoted && original.Tag == "!!str" && updated.Tag == "!!str" {
+			// keep the document's quotes, the encoder falls back to double quotes
+			// if single quotes cannot represent the new value
+			original.Style = original.Style & (yamlv3.SingleQuotedStyle | yamlv3.DoubleQuotedStyle)
+		} else {
+			original.Style = updated.Style
+		}
+		original.Tag = updated.Tag
+		return original
+	}
+	// the type changed, replace the node in place, such that aliases of it remain
+	// valid, but keep its comments
+	head, line, foot, anchor := original.HeadComment, original.LineComment, original.FootComment, original.Anchor
+	*original = *updated
+	original.HeadComment, original.LineComment, original.FootComment, original.Anchor = head, line, foot, anchor
+	return original
+}
+
+// mergeMappings changes the original mapping's values to the updated ones in the
+// original order of keys. New keys are inserted after the key preceding them in
+// the updated mapping. Lists missing from the updated mapping are kept empty, as
+// empty lists are omitted when marshalling
+func mergeMappings(original *yamlv3.Node, updated *yamlv3.Node) {
+	values := map[string]*yamlv3.Node{}
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		values[updated.Content[i].Value] = updated.Content[i+1]
+	}
+
+	content := []*yamlv3.Node{}
+	position := map[string]int{}
+	for i := 0; i+1 < len(original.Content); i += 2 {
+		key := original.Content[i]
+		value, ok := values[key.Value]
+		if _, seen := position[key.Value]; seen {
+			continue
+		}
+		if !ok && original.Content[i+1].Kind == yamlv3.SequenceNode {
+			value = &yamlv3.Node{Kind: yamlv3.SequenceNode, Tag: "!!seq", Style: yamlv3.FlowStyle}
+		} else if !ok {
+			continue
+		}
+		position[key.Value] = len(content)
+		content = append(content, key, mergeNodes(original.Content[i+1], value))
+	}
+
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		key := updated.Content[i]
+		if _, ok := position[key.Value]; ok {
+			continue
+		}
+		at := 0
+		for p := i - 2; p >= 0; p -= 2 {
+			if q, ok := position[updated.Content[p].Value]; ok {
+				at = q + 2
+				break
+			}
+		}
+		content = append(content[:at], append([]*yamlv3.Node{key, updated.Content[i+1]}, content[at:]...)...)
+		for k, q := range position {
+			if q >= at {
+				position[k] = q + 2
+			}
+		}
+		position[key.Value] = at
+	}
+	original.Content = content
+}
+
+// mergeSequences aligns the original items with the updated ones by their longest
+// common subsequence, such that unchanged items are kept with their comments when
+// items are added or removed in between. Changed items are merged in place
+func mergeSequences(original []*yamlv3.Node, updated []*yamlv3.Node) []*yamlv3.Node {
+	n, m := len(original), len(updated)
+	equal := make([][]bool, n)
+	// common[i][j] is the length of the longest common subsequence of original[i:]
+	// and updated[j:]
+	common := make([][]int, n+1)
+	for i := range common {
+		common[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		equal[i] = make([]bool, m)
+		for j := m - 1; j >= 0; j-- {
+			equal[i][j] = equalNodes(original[i], updated[j])
+			switch {
+			case equal[i][j]:
+				common[i][j] = common[i+1][j+1] + 1
+			case common[i+1][j] >= common[i][j+1]:
+				common[i][j] = common[i+1][j]
+			default:
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	merged := []*yamlv3.Node{}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i == n:
+			merged = append(merged, updated[j])
+			j++
+		case j == m:
+			i++
+		case equal[i][j] && common[i][j] == common[i+1][j+1]+1:
+			merged = append(merged, original[i])
+			i++
+			j++
+		case common[i+1][j] == common[i][j] && common[i][j+1] == common[i][j]:
+			// neither item is part of the common subsequence, the item changed
+			merged = append(merged, mergeNodes(original[i], updated[j]))
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			i++
+		default:
+			merged = append(merged, updated[j])
+			j++
+		}
+	}
+	return merged
+}
+
+// equalNodes returns true if both nodes decode to the same value
+func equalNodes(a *yamlv3.Node, b *yamlv3.Node) bool {
+	var x, y interface{}
+	if err := a.Decode(&x); err != nil {
+		return false
+	}
+	if err := b.Decode(&y); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// hasCompactSequences returns true if lists in objects are not indented, i.e.,
+// their items start in the column of the list's key. Documents without such lists
+// are treated as compact, which is how assignmentctl used to write them
+func hasCompactSequences(n *yamlv3.Node) bool {
+	compact, found := sequenceStyle(n)
+	return compact || !found
+}
+
+// sequenceStyle returns whether the first list in an object below the node is
+// compact, and whether there is any
+func sequenceStyle(n *yamlv3.Node) (compact bool, found bool) {
+	if n.Kind == yamlv3.AliasNode || n.Style&yamlv3.FlowStyle != 0 {
+		return false, false
+	}
+	for i, child := range n.Content {
+		if n.Kind == yamlv3.MappingNode && i%2 == 1 && child.Kind == yamlv3.SequenceNode && child.Style&yamlv3.FlowStyle == 0 && len(child.Content) > 0 {
+			return child.Column == n.Content[i-1].Column, true
+		}
+		if compact, found := sequenceStyle(child); found {
+			return compact, true
+		}
+	}
+	return false, false
+}
+
+// compactSequences removes the indentation of lists in objects from YAML written
+// by yaml.v3, which indents lists by two spaces relative to their key
+func compactSequences(out []byte) ([]byte, error) {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(out, &doc); err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(out), "\n")
+	shift := make([]int, len(lines))
+
+	var walk func(n *yamlv3.Node)
+	walk = func(n *yamlv3.Node) {
+		if n.Style&yamlv3.FlowStyle != 0 {
+			return
+		}
+		for i, child := range n.Content {
+			if n.Kind == yamlv3.MappingNode && i%2 == 1 && child.Kind == yamlv3.SequenceNode && child.Style&yamlv3.FlowStyle == 0 && len(child.Content) > 0 {
+				key := n.Content[i-1]
+				indent := key.Column - 1
+				// the list spans all lines after its key indented deeper than the
+				// key, including comments of its first item
+				for l := key.Line; l < len(lines); l++ {
+					text := strings.TrimLeft(lines[l], " ")
+					depth := len(lines[l]) - len(text)
+					if text == "" {
+						continue
+					}
+					if depth <= indent {
+						break
+					}
+					if depth >= indent+2 {
+						shift[l] += 2
+					}
+				}
+			}
+			walk(child)
+		}
+	}
+	for _, n := range doc.Content {
+		walk(n)
+	}
+
+	for l, s := range shift {
+		if depth := len(lines[l]) - len(strings.TrimLeft(lines[l], " ")); s > depth {
+			s = depth
+		}
+		lines[l] = lines[l][s:]
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
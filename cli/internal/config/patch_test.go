@@ -0,0 +1,145 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lithammer/dedent"
+)
+
+const patchDocument = `# Course configuration
+apiVersion: assignments.zoomoid.dev/v1
+kind: Configuration
+spec:
+  course: Linear Algebra I # the course
+  group: 'Group Alpha'
+  members:
+  # joined first
+  - name: Max Mustermann
+    id: 123456
+  - name: Erika Mustermann
+    id: AB123456
+status:
+  assignment: 2
+  assignments:
+    "01":
+      state: submitted
+`
+
+func TestPatch(t *testing.T) {
+	patch := func(t *testing.T, document string, change func(c *Configuration)) string {
+		t.Helper()
+		cfg, _, err := Decode([]byte(document), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		change(cfg)
+		out, err := Patch([]byte(document), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(out)
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		out := patch(t, patchDocument, func(c *Configuration) {})
+		if out != patchDocument {
+			t.Errorf("expected document to be unchanged, found\n%s", out)
+		}
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		out := patch(t, patchDocument, func(c *Configuration) {
+			c.Spec.Group = "Group Beta"
+			c.Spec.Locale = "de"
+			c.Spec.Members = append(c.Spec.Members, GroupMember{Name: "Kim Took", ID: "69420"})
+			c.Status.Assignment = NumberedID(3)
+			delete(c.Status.Assignments, "01")
+		})
+		expected := strings.NewReplacer(
+			"group: 'Group Alpha'", "group: 'Group Beta'",
+			"    id: AB123456\n", "    id: AB123456\n  - name: Kim Took\n    id: \"69420\"\n  locale: de\n",
+			"assignment: 2\n  assignments:\n    \"01\":\n      state: submitted\n", "assignment: 3\n",
+		).Replace(patchDocument)
+		if out != expected {
+			t.Errorf("expected\n%s\nfound\n%s", expected, out)
+		}
+	})
+
+	t.Run("indented lists", func(t *testing.T) {
+		document := dedent.Dedent(`
+			apiVersion: assignments.zoomoid.dev/v1
+			kind: Configuration
+			spec:
+			  bundle:
+			    include:
+			      - code/**
+		`)[1:]
+		out := patch(t, document, func(c *Configuration) {
+			c.Spec.BundleOptions.Include = append(c.Spec.BundleOptions.Include, "feedback/**")
+		})
+		if expected := document + "      - feedback/**\n"; out != expected {
+			t.Errorf("expected\n%s\nfound\n%s", expected, out)
+		}
+	})
+
+	t.Run("empty collections", func(t *testing.T) {
+		document := dedent.Dedent(`
+			apiVersion: assignments.zoomoid.dev/v1
+			kind: Configuration
+			spec:
+			  course: Linear Algebra I
+			  members: []
+			status: {}
+		`)[1:]
+		out := patch(t, document, func(c *Configuration) {
+			c.Status.Assignment = NumberedID(1)
+			c.Status.Upsert("01").State = StateGenerated
+		})
+		expected := strings.Replace(document, "status: {}\n", "status:\n  assignment: 1\n  assignments:\n    \"01\":\n      state: generated\n", 1)
+		if out != expected {
+			t.Errorf("expected\n%s\nfound\n%s", expected, out)
+		}
+
+		out = patch(t, patchDocument, func(c *Configuration) {
+			c.Spec.Members = nil
+		})
+		if !strings.Contains(out, "\n  members: []\n") || strings.Contains(out, "Mustermann") {
+			t.Errorf("expected members to be kept as empty list, found\n%s", out)
+		}
+	})
+
+	t.Run("unversioned", func(t *testing.T) {
+		document := "# Course configuration\nspec:\n  course: Linear Algebra I\n"
+		out := patch(t, document, func(c *Configuration) {
+			c.Spec.Course = "Linear Algebra II"
+		})
+		if expected := strings.Replace(document, "Algebra I\n", "Algebra II\n", 1); out != expected {
+			t.Errorf("expected changes not to add the version, found\n%s", out)
+		}
+
+		out = patch(t, document, func(c *Configuration) {
+			c.APIVersion, c.Kind = APIVersion, Kind
+		})
+		expected := "# Course configuration\napiVersion: assignments.zoomoid.dev/v1\nkind: Configuration\nspec:\n  course: Linear Algebra I\n"
+		if out != expected {
+			t.Errorf("expected\n%s\nfound\n%s", expected, out)
+		}
+	})
+}
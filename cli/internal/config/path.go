@@ -0,0 +1,312 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// ErrPathNotFound is returned for paths to values missing in the configuration
+	ErrPathNotFound error = errors.New("path not found")
+
+	// keyValuePairs matches objects given as comma-separated key=value pairs
+	keyValuePairs = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*=[^,]*(?:,[A-Za-z][A-Za-z0-9_-]*=[^,]*)*$`)
+)
+
+// segment is a single step of a path, i.e., a key of an object or an index of a list
+type segment struct {
+	key   string
+	index int
+	// isIndex is true for segments in brackets, e.g., [0]. Keys of numbers also
+	// index lists, e.g., spec.members.0
+	isIndex bool
+}
+
+func (s segment) String() string {
+	if s.isIndex {
+		return fmt.Sprintf("[%d]", s.index)
+	}
+	return "." + s.key
+}
+
+// parsePath splits a path of YAML field names, e.g., spec.members[0].name, into
+// its segments. The leading dot is optional, and the empty path is the root
+func parsePath(path string) ([]segment, error) {
+	segments := []segment{}
+	rest := strings.TrimPrefix(path, ".")
+	for rest != "" {
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q, missing ]", path)
+			}
+			i, err := strconv.Atoi(rest[1:end])
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid path %q, %q is no index", path, rest[1:end])
+			}
+			segments = append(segments, segment{index: i, isIndex: true})
+			rest = strings.TrimPrefix(rest[end+1:], ".")
+			continue
+		}
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("invalid path %q, empty field name", path)
+		}
+		segments = append(segments, segment{key: rest[:end]})
+		rest = strings.TrimPrefix(rest[end:], ".")
+	}
+	return segments, nil
+}
+
+// ToDocument converts the configuration into its generic form, as unmarshalled
+// from YAML, for changing it by path, see GetPath, SetPath, and AppendPath
+func ToDocument(config *Configuration) (map[interface{}]interface{}, error) {
+	out, err := Marshal(*config)
+	if err != nil {
+		return nil, err
+	}
+	doc := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(out, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// FromDocument converts a document changed by path back into a configuration.
+// Returns the problems found by ValidateDocument instead if there are any, e.g.,
+// unknown fields or values of the wrong type. Warnings are ignored
+func FromDocument(doc map[interface{}]interface{}) (*Configuration, []Problem, error) {
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	problems, err := ValidateDocument(out)
+	if err != nil {
+		return nil, nil, err
+	}
+	if problems = Errors(problems); len(problems) > 0 {
+		return nil, problems, nil
+	}
+	config, _, err := Decode(out, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return config, nil, nil
+}
+
+// ParseValue reads a value given on the command line for the path. Values of
+// string fields are taken as they are, such that, e.g., student IDs with leading
+// zeros or titles containing colons are not mistaken for numbers or objects.
+// Other values are read as YAML, falling back to the string itself if it is no
+// valid YAML
+func ParseValue(path string, arg string) interface{} {
+	return parseValue(JSONSchema().Lookup(path), arg)
+}
+
+// ParseItem reads an item given on the command line for the list at the path,
+// like ParseValue. Items of objects can be given as comma-separated key=value
+// pairs, e.g., name=Erika,id=0123456, whose values are read by their fields
+func ParseItem(path string, arg string) interface{} {
+	var items *Schema
+	if s := JSONSchema().Lookup(path); s != nil {
+		items = s.Items
+	}
+	if (items != nil && items.Type == "string") || !keyValuePairs.MatchString(arg) {
+		return parseValue(items, arg)
+	}
+	item := map[interface{}]interface{}{}
+	for _, pair := range strings.Split(arg, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		var field *Schema
+		if items != nil {
+			field = items.Properties[kv[0]]
+		}
+		item[kv[0]] = parseValue(field, kv[1])
+	}
+	return item
+}
+
+func parseValue(s *Schema, arg string) interface{} {
+	if arg == "" || (s != nil && s.Type == "string") {
+		return arg
+	}
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(arg), &value); err != nil {
+		return arg
+	}
+	return value
+}
+
+// GetPath returns the value at the path in the document
+func GetPath(doc map[interface{}]interface{}, path string) (interface{}, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	var current interface{} = doc
+	traversed := ""
+	for _, s := range segments {
+		traversed += s.String()
+		switch node := current.(type) {
+		case map[interface{}]interface{}:
+			k, ok := findKey(node, s)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, traversed)
+			}
+			current = node[k]
+		case []interface{}:
+			i, err := listIndex(node, s, traversed)
+			if err != nil {
+				return nil, err
+			}
+			current = node[i]
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, traversed)
+		}
+	}
+	return current, nil
+}
+
+// SetPath sets the value at the path in the document, creating missing objects
+// along the path
+func SetPath(doc map[interface{}]interface{}, path string, value interface{}) error {
+	return updatePath(doc, path, func(interface{}) (interface{}, error) {
+		return value, nil
+	})
+}
+
+// AppendPath appends the value to the list at the path in the document, creating
+// the list if it is missing
+func AppendPath(doc map[interface{}]interface{}, path string, value interface{}) error {
+	return updatePath(doc, path, func(old interface{}) (interface{}, error) {
+		switch list := old.(type) {
+		case nil:
+			return []interface{}{value}, nil
+		case []interface{}:
+			return append(list, value), nil
+		}
+		return nil, fmt.Errorf("%s is no list", displayPath(path))
+	})
+}
+
+func updatePath(doc map[interface{}]interface{}, path string, fn func(old interface{}) (interface{}, error)) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	if len(segments) == 0 {
+		return errors.New("cannot replace the whole configuration, pass a path")
+	}
+	_, err = update(doc, segments, "", fn)
+	return err
+}
+
+// update replaces the value at the segments below the node with the result of fn,
+// and returns the node, or a new one if the node was missing
+func update(node interface{}, segments []segment, traversed string, fn func(old interface{}) (interface{}, error)) (interface{}, error) {
+	if len(segments) == 0 {
+		return fn(node)
+	}
+	s := segments[0]
+	traversed += s.String()
+	switch n := node.(type) {
+	case nil:
+		if s.isIndex {
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, traversed)
+		}
+		child, err := update(nil, segments[1:], traversed, fn)
+		if err != nil {
+			return nil, err
+		}
+		return map[interface{}]interface{}{s.key: child}, nil
+	case map[interface{}]interface{}:
+		if s.isIndex {
+			return nil, fmt.Errorf("%s is no list", displayPath(strings.TrimSuffix(traversed, s.String())))
+		}
+		k, ok := findKey(n, s)
+		if !ok {
+			k = s.key
+		}
+		child, err := update(n[k], segments[1:], traversed, fn)
+		if err != nil {
+			return nil, err
+		}
+		n[k] = child
+		return n, nil
+	case []interface{}:
+		i, err := listIndex(n, s, traversed)
+		if err != nil {
+			return nil, err
+		}
+		child, err := update(n[i], segments[1:], traversed, fn)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = child
+		return n, nil
+	}
+	return nil, fmt.Errorf("%s is no object or list", displayPath(strings.TrimSuffix(traversed, s.String())))
+}
+
+// findKey returns the key of the map matching the segment. Keys are compared by
+// their text, as YAML decodes keys such as 1 to numbers
+func findKey(m map[interface{}]interface{}, s segment) (interface{}, bool) {
+	if s.isIndex {
+		return nil, false
+	}
+	for k := range m {
+		if fmt.Sprint(k) == s.key {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+func listIndex(l []interface{}, s segment, traversed string) (int, error) {
+	i := s.index
+	if !s.isIndex {
+		var err error
+		if i, err = strconv.Atoi(s.key); err != nil {
+			return 0, fmt.Errorf("%s is a list, expected an index such as [0]", displayPath(strings.TrimSuffix(traversed, s.String())))
+		}
+	}
+	if i < 0 || i >= len(l) {
+		return 0, fmt.Errorf("%w: %s, the list has %d items", ErrPathNotFound, traversed, len(l))
+	}
+	return i, nil
+}
+
+// displayPath returns the path with a leading dot for messages
+func displayPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	if !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "[") {
+		return "." + path
+	}
+	return path
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	config := &Configuration{
+		Spec: &ConfigurationSpec{
+			Course:  "Linear Algebra I",
+			Members: []GroupMember{{Name: "Max Mustermann", ID: "123456"}},
+		},
+		Status: &ConfigurationStatus{Assignment: NumberedID(3)},
+	}
+
+	t.Run("get", func(t *testing.T) {
+		doc, err := ToDocument(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for path, expected := range map[string]interface{}{
+			"spec.course":           "Linear Algebra I",
+			".spec.members[0].name": "Max Mustermann",
+			"spec.members.0.id":     "123456",
+			"status.assignment":     3,
+		} {
+			value, err := GetPath(doc, path)
+			if err != nil {
+				t.Errorf("%s: %v", path, err)
+				continue
+			}
+			if value != expected {
+				t.Errorf("%s: expected %v, found %v", path, expected, value)
+			}
+		}
+		if _, err := GetPath(doc, "spec.members[1]"); !errors.Is(err, ErrPathNotFound) {
+			t.Errorf("expected index out of range to be not found, found %v", err)
+		}
+		if _, err := GetPath(doc, "spec.bundle.template"); !errors.Is(err, ErrPathNotFound) {
+			t.Errorf("expected missing field to be not found, found %v", err)
+		}
+	})
+
+	t.Run("set and add", func(t *testing.T) {
+		doc, err := ToDocument(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SetPath(doc, "spec.bundle.template", "sheet-{{._id}}"); err != nil {
+			t.Fatal(err)
+		}
+		if err := SetPath(doc, "status.assignment", 7); err != nil {
+			t.Fatal(err)
+		}
+		if err := AppendPath(doc, "spec.members", map[interface{}]interface{}{"name": "Erika Mustermann", "id": 654321}); err != nil {
+			t.Fatal(err)
+		}
+		if err := AppendPath(doc, "spec.course", "x"); err == nil {
+			t.Errorf("expected appending to a string to fail")
+		}
+		changed, problems, err := FromDocument(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(problems) != 0 {
+			t.Fatalf("expected no problems, found %v", problems)
+		}
+		if changed.Spec.BundleOptions == nil || changed.Spec.BundleOptions.Template != "sheet-{{._id}}" {
+			t.Errorf("expected bundle template to be created, found %v", changed.Spec.BundleOptions)
+		}
+		if changed.Status.Assignment != NumberedID(7) {
+			t.Errorf("expected assignment 7, found %s", changed.Status.Assignment)
+		}
+		if len(changed.Spec.Members) != 2 || changed.Spec.Members[1].ID != "654321" {
+			t.Errorf("expected member to be added, found %v", changed.Spec.Members)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		doc, err := ToDocument(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SetPath(doc, "spec.bundel.template", "x"); err != nil {
+			t.Fatal(err)
+		}
+		_, problems, err := FromDocument(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(problems) != 1 || problems[0].Path != ".spec.bundel" {
+			t.Errorf("expected unknown field .spec.bundel, found %v", problems)
+		}
+
+		doc, err = ToDocument(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SetPath(doc, "status.assignment", ParseValue("status.assignment", "abc")); err != nil {
+			t.Fatal(err)
+		}
+		if _, problems, err = FromDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+		if len(problems) != 1 || problems[0].Path != ".status.assignment" {
+			t.Errorf("expected named current assignment to be rejected, found %v", problems)
+		}
+	})
+}
+
+func TestParseValue(t *testing.T) {
+	for _, tc := range []struct {
+		path     string
+		arg      string
+		expected interface{}
+	}{
+		{"spec.group", "01", "01"},
+		{"spec.course", "Theory: Part 2", "Theory: Part 2"},
+		{"spec.members[0].id", "0123456", "0123456"},
+		{"status.assignment", "7", 7},
+		{"spec.generate.splitExercises", "true", true},
+		{"spec.data.semester", "3", 3},
+	} {
+		if value := ParseValue(tc.path, tc.arg); !reflect.DeepEqual(value, tc.expected) {
+			t.Errorf("%s: expected %#v, found %#v", tc.path, tc.expected, value)
+		}
+	}
+
+	item := ParseItem("spec.members", "name=Eve: the Second,id=0123456")
+	expected := map[interface{}]interface{}{"name": "Eve: the Second", "id": "0123456"}
+	if !reflect.DeepEqual(item, expected) {
+		t.Errorf("expected %#v, found %#v", expected, item)
+	}
+	if item := ParseItem("spec.bundle.include", "code=1"); item != "code=1" {
+		t.Errorf("expected items of string lists to be taken as they are, found %#v", item)
+	}
+
+	config := &Configuration{Spec: &ConfigurationSpec{Course: "Linear Algebra I"}}
+	doc, err := ToDocument(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPath(doc, "spec.course", ParseValue("spec.course", "Theory: Part 2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendPath(doc, "spec.members", item); err != nil {
+		t.Fatal(err)
+	}
+	changed, problems, err := FromDocument(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 0 {
+		t.Fatalf("expected no problems, found %v", problems)
+	}
+	if changed.Spec.Course != "Theory: Part 2" || changed.Spec.Members[0].ID != "0123456" {
+		t.Errorf("expected values to be kept as they are, found %q and %q", changed.Spec.Course, changed.Spec.Members[0].ID)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SchemaDialect is the JSON Schema draft of the generated schema
+	SchemaDialect string = "https://json-schema.org/draft/2020-12/schema"
+
+	// schemaTag is the struct tag marking required fields, i.e., `jsonschema:"required"`
+	schemaTag string = "jsonschema"
+)
+
+// Schema is the subset of JSON Schema needed to describe the configuration
+type Schema struct {
+	Dialect     string        `json:"$schema,omitempty"`
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Type        string        `json:"type,omitempty"`
+	Format      string        `json:"format,omitempty"`
+	Pattern     string        `json:"pattern,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Minimum     *float64      `json:"minimum,omitempty"`
+	// Properties of objects, keyed by their YAML names
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	// AdditionalProperties is either false, rejecting unknown properties, or the
+	// schema of the values of maps
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	Items                *Schema     `json:"items,omitempty"`
+	OneOf                []*Schema   `json:"oneOf,omitempty"`
+}
+
+var (
+	// zero is the minimum of unsigned integers
+	zero float64 = 0
+
+	// customSchemas describe types with custom YAML (un)marshalling
+	customSchemas = map[reflect.Type]func() *Schema{
+		reflect.TypeOf(AssignmentID{}): func() *Schema {
+			return &Schema{
+				OneOf: []*Schema{
+					{Type: "integer", Minimum: &zero},
+					{Type: "string", Pattern: "^(?:[0-9]+" + IDSuffixPattern + "|" + NamedIDPattern + ")$"},
+				},
+			}
+		},
+		reflect.TypeOf(time.Time{}): func() *Schema {
+			return &Schema{Type: "string", Format: "date-time"}
+		},
+		reflect.TypeOf(State("")): func() *Schema {
+			enum := []interface{}{}
+			for _, s := range States {
+				enum = append(enum, string(s))
+			}
+			return &Schema{Type: "string", Enum: enum}
+		},
+	}
+)
+
+// JSONSchema generates the JSON Schema of .assignments.yaml from the configuration
+// structs. Fields tagged with `jsonschema:"required"` are required, and objects
+// reject unknown fields
+func JSONSchema() *Schema {
+	s := schemaOf(reflect.TypeOf(Configuration{}))
+	s.Dialect = SchemaDialect
+	s.Title = ConfigurationFileName
+	s.Description = "Configuration of assignmentctl, see https://github.com/zoomoid/assignments"
+	s.Properties["apiVersion"].Enum = []interface{}{APIVersion}
+	s.Properties["kind"].Enum = []interface{}{Kind}
+	// the current assignment is where generate continues the numbered sequence,
+	// which named IDs are not part of
+	s.Properties["status"].Properties["assignment"] = &Schema{
+		OneOf: []*Schema{
+			{Type: "integer", Minimum: &zero},
+			{Type: "string", Pattern: "^[0-9]+" + IDSuffixPattern + "$"},
+		},
+	}
+	return s
+}
+
+func schemaOf(t reflect.Type) *Schema {
+	if custom, ok := customSchemas[t]; ok {
+		return custom()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem())
+	case reflect.Struct:
+		s := &Schema{
+			Type:                 "object",
+			Properties:           map[string]*Schema{},
+			AdditionalProperties: false,
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(f.Name)
+			}
+			s.Properties[name] = schemaOf(f.Type)
+			if f.Tag.Get(schemaTag) == "required" {
+				s.Required = append(s.Required, name)
+			}
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+	// any value, e.g., interface{}
+	return &Schema{}
+}
+
+// Lookup returns the schema of the values at the path of YAML field names, e.g.,
+// spec.members[0], or nil if the path leaves the schema
+func (s *Schema) Lookup(path string) *Schema {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil
+	}
+	current := s
+	for _, seg := range segments {
+		switch {
+		case current == nil:
+			return nil
+		case current.Type == "array" && (seg.isIndex || isNumber(seg.key)):
+			current = current.Items
+		case current.Type == "object" && !seg.isIndex:
+			if p, ok := current.Properties[seg.key]; ok {
+				current = p
+			} else if additional, ok := current.AdditionalProperties.(*Schema); ok {
+				current = additional
+			} else {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
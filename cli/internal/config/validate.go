@@ -0,0 +1,386 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrAmbiguousCleanup is returned if both cleanup modes are configured
+	ErrAmbiguousCleanup error = errors.New("found ambiguous cleanup mode, only use either glob or command")
+)
+
+// Validate returns ErrAmbiguousCleanup if both glob and command are configured
+func (c *CleanupOptions) Validate() error {
+	if c != nil && c.Glob != nil && c.Command != nil {
+		return ErrAmbiguousCleanup
+	}
+	return nil
+}
+
+// Problem is a violation of the configuration's schema, or of a rule across
+// fields, at a position in the configuration file
+type Problem struct {
+	// Path is the YAML path of the offending value, e.g., .spec.members[1].name
+	Path    string
+	Line    int
+	Column  int
+	Message string
+	// Warning marks problems that do not keep the configuration from being used
+	Warning bool
+}
+
+func (p Problem) String() string {
+	if p.Warning {
+		return fmt.Sprintf("%d:%d: %s: warning: %s", p.Line, p.Column, p.Path, p.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", p.Line, p.Column, p.Path, p.Message)
+}
+
+// Errors returns the problems that are no warnings
+func Errors(problems []Problem) []Problem {
+	found := []Problem{}
+	for _, p := range problems {
+		if !p.Warning {
+			found = append(found, p)
+		}
+	}
+	return found
+}
+
+// crossFieldRules check constraints that the schema cannot express
+var crossFieldRules = []func(root *yamlv3.Node) []Problem{
+	// configurations without version are still read, but should be migrated
+	func(root *yamlv3.Node) []Problem {
+		if root.Kind == yamlv3.MappingNode && child(root, "apiVersion") == nil {
+			return []Problem{{Path: ".", Line: root.Line, Column: root.Column, Message: "missing apiVersion, upgrade the configuration with \"assignmentctl config migrate\"", Warning: true}}
+		}
+		return nil
+	},
+	// the cleanup modes are mutually exclusive
+	func(root *yamlv3.Node) []Problem {
+		cleanup := child(root, "spec", "build", "cleanup")
+		if cleanup == nil || isNull(child(cleanup, "glob")) || isNull(child(cleanup, "command")) {
+			return nil
+		}
+		// report the problem at the cleanup key, the mapping starts at its first field
+		k := keyNode(child(root, "spec", "build"), "cleanup")
+		return []Problem{{Path: ".spec.build.cleanup", Line: k.Line, Column: k.Column, Message: "glob and command are mutually exclusive, only use either"}}
+	},
+	// members join the group before they leave it
+	func(root *yamlv3.Node) []Problem {
+		members := child(root, "spec", "members")
+		if members == nil || members.Kind != yamlv3.SequenceNode {
+			return nil
+		}
+		problems := []Problem{}
+		for i, m := range members.Content {
+			from, errFrom := strconv.ParseUint(value(child(m, "from")), 10, 32)
+			until, errUntil := strconv.ParseUint(value(child(m, "until")), 10, 32)
+			if errFrom == nil && errUntil == nil && until != 0 && from > until {
+				problems = append(problems, Problem{Path: fmt.Sprintf(".spec.members[%d]", i), Line: m.Line, Column: m.Column, Message: fmt.Sprintf("from %d is after until %d", from, until)})
+			}
+		}
+		return problems
+	},
+}
+
+// ValidateDocument checks a configuration file against JSONSchema and the rules
+// across fields that the schema cannot express, e.g., that the cleanup modes are
+// mutually exclusive. Returns all problems, including warnings, ordered by their
+// position, or an error if the document is no valid YAML
+func ValidateDocument(in []byte) ([]Problem, error) {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(in, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		// the file is empty
+		return []Problem{}, nil
+	}
+	root := resolve(doc.Content[0])
+
+	problems := []Problem{}
+	validate(JSONSchema(), root, "", &problems)
+	for _, rule := range crossFieldRules {
+		problems = append(problems, rule(root)...)
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+	return problems, nil
+}
+
+// validate checks the node against the schema and appends violations to problems.
+// As when reading the configuration, null is the zero value of any type, and
+// scalars are read as their text where strings are expected
+func validate(s *Schema, n *yamlv3.Node, path string, problems *[]Problem) {
+	n = resolve(n)
+	if isNull(n) {
+		return
+	}
+	report := func(node *yamlv3.Node, p string, format string, args ...interface{}) {
+		if p == "" {
+			p = "."
+		}
+		*problems = append(*problems, Problem{Path: p, Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.OneOf) > 0 {
+		expected := []string{}
+		for _, alternative := range s.OneOf {
+			var p []Problem
+			validate(alternative, n, path, &p)
+			if len(p) == 0 {
+				return
+			}
+			expected = append(expected, describe(alternative))
+		}
+		report(n, path, "expected %s, found %s", strings.Join(expected, " or "), kindOf(n))
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		if n.Kind != yamlv3.MappingNode {
+			report(n, path, "expected object, found %s", kindOf(n))
+			return
+		}
+		present := map[string]bool{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, val := n.Content[i], n.Content[i+1]
+			if key.Value == "<<" {
+				// merge keys are resolved by YAML
+				continue
+			}
+			present[key.Value] = true
+			p := path + "." + key.Value
+			if prop, ok := s.Properties[key.Value]; ok {
+				validate(prop, val, p, problems)
+				continue
+			}
+			switch additional := s.AdditionalProperties.(type) {
+			case *Schema:
+				validate(additional, val, p, problems)
+			case bool:
+				if !additional {
+					message := "unknown field " + strconv.Quote(key.Value)
+					if suggestion := closest(key.Value, s.Properties); suggestion != "" {
+						message += fmt.Sprintf(", did you mean %q?", suggestion)
+					}
+					report(key, p, "%s", message)
+				}
+			}
+		}
+		for _, r := range s.Required {
+			if !present[r] {
+				report(n, path, "missing required field %q", r)
+			}
+		}
+	case "array":
+		if n.Kind != yamlv3.SequenceNode {
+			report(n, path, "expected list, found %s", kindOf(n))
+			return
+		}
+		for i, item := range n.Content {
+			validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case "string", "integer", "number", "boolean":
+		if n.Kind != yamlv3.ScalarNode {
+			report(n, path, "expected %s, found %s", s.Type, kindOf(n))
+			return
+		}
+		if !scalarMatches(s.Type, n) {
+			report(n, path, "expected %s, found %s", s.Type, kindOf(n))
+			return
+		}
+		if s.Format == "date-time" && n.Tag != "!!timestamp" {
+			if _, err := time.Parse(time.RFC3339, n.Value); err != nil {
+				report(n, path, "expected date and time in RFC 3339 format, e.g., 2006-01-02T15:04:05Z")
+			}
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(n.Value) {
+			report(n, path, "%q does not match %s", n.Value, s.Pattern)
+		}
+		if s.Minimum != nil {
+			if f, err := strconv.ParseFloat(n.Value, 64); err == nil && f < *s.Minimum {
+				report(n, path, "must be at least %v", *s.Minimum)
+			}
+		}
+		if len(s.Enum) > 0 {
+			allowed := []string{}
+			for _, e := range s.Enum {
+				if fmt.Sprint(e) == n.Value {
+					return
+				}
+				allowed = append(allowed, fmt.Sprint(e))
+			}
+			report(n, path, "%q is none of %s", n.Value, strings.Join(allowed, ", "))
+		}
+	}
+}
+
+// scalarMatches returns true if the scalar can be read as the type
+func scalarMatches(typ string, n *yamlv3.Node) bool {
+	switch typ {
+	case "string":
+		return n.Tag != "!!binary"
+	case "integer":
+		return n.Tag == "!!int"
+	case "number":
+		return n.Tag == "!!int" || n.Tag == "!!float"
+	case "boolean":
+		return n.Tag == "!!bool"
+	}
+	return true
+}
+
+// describe names the type of values matching the schema for messages
+func describe(s *Schema) string {
+	if s.Pattern != "" {
+		return fmt.Sprintf("%s matching %s", s.Type, s.Pattern)
+	}
+	if s.Minimum != nil && *s.Minimum == 0 {
+		return "non-negative " + s.Type
+	}
+	return s.Type
+}
+
+// kindOf names the type of the node's value for messages
+func kindOf(n *yamlv3.Node) string {
+	switch n.Kind {
+	case yamlv3.MappingNode:
+		return "object"
+	case yamlv3.SequenceNode:
+		return "list"
+	}
+	switch n.Tag {
+	case "!!int":
+		return "integer " + n.Value
+	case "!!float":
+		return "number " + n.Value
+	case "!!bool":
+		return "boolean " + n.Value
+	}
+	return strconv.Quote(n.Value)
+}
+
+// closest returns the property with the smallest edit distance to the name, if
+// it is a likely typo
+func closest(name string, properties map[string]*Schema) string {
+	best, distance := "", 3
+	for p := range properties {
+		if d := levenshtein(strings.ToLower(name), strings.ToLower(p)); d < distance || (d == distance && best != "" && p < best) {
+			best, distance = p, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// child returns the value at the path of keys below the mapping node, or nil
+func child(n *yamlv3.Node, keys ...string) *yamlv3.Node {
+	for _, k := range keys {
+		n = resolve(n)
+		if n == nil || n.Kind != yamlv3.MappingNode {
+			return nil
+		}
+		var next *yamlv3.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == k {
+				next = n.Content[i+1]
+			}
+		}
+		n = next
+	}
+	return resolve(n)
+}
+
+// keyNode returns the node of the key in the mapping node, or nil
+func keyNode(n *yamlv3.Node, k string) *yamlv3.Node {
+	if n == nil || n.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	var found *yamlv3.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == k {
+			found = n.Content[i]
+		}
+	}
+	return found
+}
+
+// resolve follows aliases to the anchored node
+func resolve(n *yamlv3.Node) *yamlv3.Node {
+	for n != nil && n.Kind == yamlv3.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+func isNull(n *yamlv3.Node) bool {
+	return n == nil || (n.Kind == yamlv3.ScalarNode && n.Tag == "!!null")
+}
+
+// value returns the scalar's text, or the empty string for other nodes
+func value(n *yamlv3.Node) string {
+	if n == nil || n.Kind != yamlv3.ScalarNode {
+		return ""
+	}
+	return n.Value
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/lithammer/dedent"
+)
+
+func TestValidateDocument(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		problems, err := ValidateDocument([]byte(dedent.Dedent(`
+			apiVersion: assignments.zoomoid.dev/v1
+			kind: Configuration
+			spec:
+			  course: Linear Algebra I
+			  members:
+			    - name: Max Mustermann
+			      id: 123456
+			  build:
+			    recipe:
+			      - command: latexmk
+			        args: ["-pdf"]
+			    cleanup:
+			      glob:
+			        patterns: ["*.log"]
+			status:
+			  assignment: 3
+			  assignments:
+			    "2a":
+			      state: submitted
+			      history:
+			        - state: submitted
+			          timestamp: 2022-05-01T12:00:00Z
+		`)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(problems) != 0 {
+			t.Errorf("expected no problems, found %v", problems)
+		}
+	})
+
+	t.Run("problems", func(t *testing.T) {
+		problems, err := ValidateDocument([]byte(dedent.Dedent(`
+			apiVersion: assignments.zoomoid.dev/v1
+			kind: Configuration
+			spec:
+			  build:
+			    recipe:
+			      - args: ["-pdf"]
+			    cleanup:
+			      globs:
+			        patterns: ["*.log"]
+			      glob:
+			        patterns: ["*.aux"]
+			      command:
+			        recipe: []
+			status:
+			  assignment: -1
+		`)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []Problem{
+			{Path: ".spec.build.recipe[0]", Line: 7, Column: 9, Message: `missing required field "command"`},
+			{Path: ".spec.build.cleanup", Line: 8, Column: 5, Message: "glob and command are mutually exclusive"},
+			{Path: ".spec.build.cleanup.globs", Line: 9, Column: 7, Message: `did you mean "glob"?`},
+			{Path: ".status.assignment", Line: 16, Column: 15, Message: `expected non-negative integer or string`},
+		}
+		if len(problems) != len(expected) {
+			t.Fatalf("expected %d problems, found %v", len(expected), problems)
+		}
+		for i, p := range problems {
+			e := expected[i]
+			if p.Path != e.Path || p.Line != e.Line || p.Column != e.Column || !strings.Contains(p.Message, e.Message) {
+				t.Errorf("expected %s containing %q, found %s", e.Path, e.Message, p)
+			}
+		}
+	})
+
+	t.Run("named current assignment", func(t *testing.T) {
+		problems, err := ValidateDocument([]byte("apiVersion: assignments.zoomoid.dev/v1\nkind: Configuration\nstatus:\n  assignment: abc\n  assignments:\n    abc: {}\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(problems) != 1 || problems[0].Path != ".status.assignment" {
+			t.Errorf("expected named ID to be rejected as current assignment, found %v", problems)
+		}
+	})
+
+	t.Run("unversioned", func(t *testing.T) {
+		problems, err := ValidateDocument([]byte("spec:\n  course: Linear Algebra I\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(problems) != 1 || !problems[0].Warning || !strings.Contains(problems[0].Message, "config migrate") {
+			t.Errorf("expected warning about missing apiVersion, found %v", problems)
+		}
+		if len(Errors(problems)) != 0 {
+			t.Errorf("expected no errors, found %v", Errors(problems))
+		}
+	})
+}
+
+func TestJSONSchema(t *testing.T) {
+	s := JSONSchema()
+	if _, err := json.Marshal(s); err != nil {
+		t.Fatal(err)
+	}
+	recipe := s.Properties["spec"].Properties["build"].Properties["recipe"].Items
+	if len(recipe.Required) != 1 || recipe.Required[0] != "command" {
+		t.Errorf("expected command to be required in recipes, found %v", recipe.Required)
+	}
+	if s.Properties["spec"].AdditionalProperties != false {
+		t.Errorf("expected unknown fields to be rejected")
+	}
+}
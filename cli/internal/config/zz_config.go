@@ -16,6 +16,8 @@ limitations under the License.
 
 package config
 
+import "time"
+
 func (c *ConfigurationSpec) Clone() *ConfigurationSpec {
 	nm := c.Members
 	if nm != nil {
@@ -48,6 +50,26 @@ func (c *ConfigurationSpec) Clone() *ConfigurationSpec {
 		bundleOptions = c.BundleOptions.Clone()
 	}
 
+	dueOptions := c.DueOptions
+	if dueOptions != nil {
+		dueOptions = c.DueOptions.Clone()
+	}
+
+	calendarOptions := c.CalendarOptions
+	if calendarOptions != nil {
+		calendarOptions = c.CalendarOptions.Clone()
+	}
+
+	namingOptions := c.NamingOptions
+	if namingOptions != nil {
+		namingOptions = c.NamingOptions.Clone()
+	}
+
+	trashOptions := c.TrashOptions
+	if trashOptions != nil {
+		trashOptions = c.TrashOptions.Clone()
+	}
+
 	return &ConfigurationSpec{
 		Course:          c.Course,
 		Group:           c.Group,
@@ -57,26 +79,111 @@ func (c *ConfigurationSpec) Clone() *ConfigurationSpec {
 		GenerateOptions: generateOptions,
 		BuildOptions:    buildOptions,
 		BundleOptions:   bundleOptions,
+		DueOptions:      dueOptions,
+		CalendarOptions: calendarOptions,
+		NamingOptions:   namingOptions,
+		TrashOptions:    trashOptions,
+		Locale:          c.Locale,
+		Data:            cloneData(c.Data),
 	}
 }
 
 func (c *ConfigurationStatus) Clone() *ConfigurationStatus {
+	if c == nil {
+		return nil
+	}
+	var na map[string]*AssignmentStatus
+	if c.Assignments != nil {
+		na = make(map[string]*AssignmentStatus, len(c.Assignments))
+		for k, a := range c.Assignments {
+			na[k] = a.Clone()
+		}
+	}
+
 	return &ConfigurationStatus{
-		Assignment: c.Assignment,
+		Assignment:  c.Assignment,
+		Assignments: na,
+	}
+}
+
+func (a *AssignmentStatus) Clone() *AssignmentStatus {
+	if a == nil {
+		return nil
+	}
+	var due *time.Time
+	if a.Due != nil {
+		d := *a.Due
+		due = &d
+	}
+	var history []Transition
+	if a.History != nil {
+		history = []Transition{}
+		history = append(history, a.History...)
+	}
+	return &AssignmentStatus{
+		Due:     due,
+		State:   a.State,
+		History: history,
+		Data:    cloneData(a.Data),
+	}
+}
+
+func (d *DueOptions) Clone() *DueOptions {
+	return &DueOptions{
+		Timezone: d.Timezone,
+		Time:     d.Time,
+		Layout:   d.Layout,
+		Warn:     d.Warn,
+	}
+}
+
+func (n *NamingOptions) Clone() *NamingOptions {
+	return &NamingOptions{
+		Template: n.Template,
+		Width:    n.Width,
+	}
+}
+
+func (t *TrashOptions) Clone() *TrashOptions {
+	return &TrashOptions{
+		Retention: t.Retention,
+		Keep:      t.Keep,
 	}
 }
 
+func (c *CalendarOptions) Clone() *CalendarOptions {
+	return &CalendarOptions{
+		Alarm:   c.Alarm,
+		Pattern: c.Pattern,
+	}
+}
+
+func cloneData(d map[string]interface{}) map[string]interface{} {
+	if d == nil {
+		return nil
+	}
+	nd := make(map[string]interface{}, len(d))
+	for k, v := range d {
+		nd[k] = v
+	}
+	return nd
+}
+
 func (c *Configuration) Clone() *Configuration {
 	return &Configuration{
-		Spec:   c.Spec.Clone(),
-		Status: c.Status.Clone(),
+		APIVersion: c.APIVersion,
+		Kind:       c.Kind,
+		Spec:       c.Spec.Clone(),
+		Status:     c.Status.Clone(),
 	}
 }
 
 func (g *GroupMember) Clone() GroupMember {
 	return GroupMember{
-		Name: g.Name,
-		ID:   g.ID,
+		Name:  g.Name,
+		ID:    g.ID,
+		From:  g.From,
+		Until: g.Until,
 	}
 }
 
@@ -112,8 +219,17 @@ func (g *GenerateOptions) Clone() *GenerateOptions {
 	o := []string{}
 	o = append(o, g.Create...)
 
+	var e []Exercise
+	if g.Exercises != nil {
+		e = []Exercise{}
+		e = append(e, g.Exercises...)
+	}
+
 	return &GenerateOptions{
-		Create: o,
+		Create:         o,
+		Exercises:      e,
+		Template:       g.Template,
+		SplitExercises: g.SplitExercises,
 	}
 }
 
@@ -17,11 +17,15 @@ limitations under the License.
 package context
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/naming"
 )
 
 // AppContext is initialized by a cobra command and carried through the application
@@ -42,6 +46,17 @@ type AppContext struct {
 	Configuration *config.Configuration
 	// Verbose toggles more explicit output down the line
 	Verbose bool
+	// Strict rejects fields of the configuration file unknown to assignmentctl
+	// instead of ignoring them
+	Strict bool
+	// naming is the naming scheme from the configuration, set by Read
+	naming *naming.Scheme
+	// document is the configuration file as last read or written, into which
+	// Write patches changes, see config.Patch
+	document []byte
+	// written is the configuration as of document, such that Write can skip
+	// writing an unchanged configuration
+	written []byte
 }
 
 // Read uses the context's root to read a configmap into the context's struct field
@@ -50,19 +65,74 @@ func (c *AppContext) Read() error {
 		return err
 	}
 	p := filepath.Join(c.Root, ".assignments.yaml")
-	cfg, err := config.Read(p)
+	document, err := os.ReadFile(p)
+	if err != nil {
+		return fmt.Errorf("failed to read %s, %w", p, err)
+	}
+	cfg, _, err := config.Decode(document, c.Strict)
+	if err != nil {
+		return fmt.Errorf("failed to read %s, %w", p, err)
+	}
+	n, err := naming.New(cfg.Spec.NamingOptions)
+	if err != nil {
+		return fmt.Errorf("invalid naming scheme in .spec.naming, %w", err)
+	}
+	written, err := config.Marshal(*cfg)
 	if err != nil {
 		return err
 	}
 	c.Configuration = cfg
+	c.naming = n
+	c.document = document
+	c.written = written
 	return nil
 }
 
-// Write writes the context's struct field to a file at the context's root
+// Naming returns the naming scheme of the repository's assignments, which
+// defaults to assignment-01, assignment-02, ... if none is configured
+func (c *AppContext) Naming() *naming.Scheme {
+	if c.naming != nil {
+		return c.naming
+	}
+	if c.Configuration != nil && c.Configuration.Spec != nil {
+		if n, err := naming.New(c.Configuration.Spec.NamingOptions); err == nil {
+			return n
+		}
+	}
+	return naming.Default()
+}
+
+// Write writes the context's struct field to a file at the context's root, if it
+// changed since Read. Changes are patched into the file as read, keeping its
+// comments and formatting, see config.Patch
 func (c *AppContext) Write() error {
 	p := filepath.Join(c.Root, ".assignments.yaml")
-	err := config.Write(c.Configuration, p)
-	return err
+	written, err := config.Marshal(*c.Configuration)
+	if err != nil {
+		return err
+	}
+	if c.document == nil {
+		// the configuration was not read from a file, e.g., by bootstrap
+		if err := config.Write(c.Configuration, p); err != nil {
+			return err
+		}
+		c.document = written
+		c.written = written
+		return nil
+	}
+	if bytes.Equal(written, c.written) {
+		return nil
+	}
+	document, err := config.Patch(c.document, c.Configuration)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(p, document, 0644); err != nil {
+		return err
+	}
+	c.document = document
+	c.written = written
+	return nil
 }
 
 func (c *AppContext) mustFindConfigFile() error {
@@ -83,10 +153,44 @@ func (c *AppContext) Clone() *AppContext {
 		Cwd:           c.Cwd,
 		Root:          c.Root,
 		Configuration: c.Configuration.Clone(),
+		Strict:        c.Strict,
+		naming:        c.naming,
+		document:      c.document,
+		written:       c.written,
 	}
 	return nc
 }
 
+// ForAssignment returns a copy of the context whose configuration has the optional
+// assignment.yaml in the assignment's directory applied, see
+// config.Configuration.ForAssignment. The directory is relative to the root, or
+// absolute. Returns the context itself if there is no assignment.yaml
+func (c *AppContext) ForAssignment(directory string) (*AppContext, error) {
+	dir := directory
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(c.Root, dir)
+	}
+	a, err := config.ReadAssignment(dir)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return c, nil
+	}
+	id, err := c.Naming().ParseID(dir)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := c.Configuration.ForAssignment(id, a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply %s, %w", filepath.Join(directory, config.AssignmentConfigurationFileName), err)
+	}
+	nc := c.Clone()
+	nc.Configuration = cfg
+	nc.Verbose = c.Verbose
+	return nc, nil
+}
+
 // NewDevelopment creates a new AppContext with development logger from scratch
 func NewDevelopment() (context *AppContext, err error) {
 	if err != nil {
@@ -118,3 +222,16 @@ func NewProduction() (context *AppContext, err error) {
 		Root:          cwd,
 	}, nil
 }
+
+// DueDate returns the assignment's due date from its assignment.yaml, which takes
+// precedence, or as recorded in .status.assignments. Returns nil if there is none
+func (c *AppContext) DueDate(id config.AssignmentID) (*time.Time, error) {
+	assignmentCtx, err := c.ForAssignment(c.Naming().Directory(id))
+	if err != nil {
+		return nil, err
+	}
+	if status := assignmentCtx.Configuration.Status.Lookup(c.Naming().ID(id)); status != nil {
+		return status.Due, nil
+	}
+	return nil, nil
+}
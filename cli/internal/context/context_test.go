@@ -19,7 +19,12 @@ package context
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
 )
 
 func TestNewProduction(t *testing.T) {
@@ -57,3 +62,73 @@ func TestNewDevelopment(t *testing.T) {
 		t.Fatal(fmt.Errorf("root does not match, expected %s, found %s", cwd, ctx.Root))
 	}
 }
+
+func TestWrite(t *testing.T) {
+	root := t.TempDir()
+	p := filepath.Join(root, ".assignments.yaml")
+	document := "# Course configuration\napiVersion: assignments.zoomoid.dev/v1\nkind: Configuration\nspec:\n  course: Linear Algebra I\nstatus:\n  assignment: 1 # current\n"
+	if err := os.WriteFile(p, []byte(document), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := &AppContext{Cwd: root, Root: root}
+	if err := ctx.Read(); err != nil {
+		t.Fatal(err)
+	}
+
+	// an unchanged configuration is not written at all
+	if err := os.WriteFile(p, []byte("# changed elsewhere\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := os.ReadFile(p); string(out) != "# changed elsewhere\n" {
+		t.Errorf("expected unchanged configuration not to be written, found\n%s", out)
+	}
+
+	ctx.Configuration.Spec.Course = "Linear Algebra II"
+	if err := ctx.Write(); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := strings.Replace(document, "Algebra I\n", "Algebra II\n", 1); string(out) != expected {
+		t.Errorf("expected\n%s\nfound\n%s", expected, out)
+	}
+}
+
+func TestDueDate(t *testing.T) {
+	root := t.TempDir()
+	recorded := time.Date(2026, time.October, 30, 23, 59, 0, 0, time.UTC)
+	override := time.Date(2026, time.November, 6, 12, 0, 0, 0, time.UTC)
+	cfg := config.Minimal()
+	cfg.Status.Upsert("02").Due = &recorded
+	cfg.Status.Upsert("03").Due = &recorded
+	ctx := &AppContext{Cwd: root, Root: root, Configuration: cfg}
+
+	if err := os.MkdirAll(filepath.Join(root, "assignment-03"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "assignment-03", config.AssignmentConfigurationFileName), []byte("due: 2026-11-06T12:00:00Z\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		id       config.AssignmentID
+		expected *time.Time
+	}{
+		{config.NumberedID(1), nil},
+		{config.NumberedID(2), &recorded},
+		{config.NumberedID(3), &override},
+	} {
+		due, err := ctx.DueDate(tc.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (due == nil) != (tc.expected == nil) || (due != nil && !due.Equal(*tc.expected)) {
+			t.Errorf("%s: expected due date %v, found %v", tc.id, tc.expected, due)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deadline
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeOfDay is the time of day used for due dates given without time
+	DefaultTimeOfDay string = "23:59"
+	// DefaultWarningWindow is the time before a due date in which build and bundle warn
+	DefaultWarningWindow string = "48h"
+)
+
+var (
+	// ErrInvalidDueDate is returned for due dates in none of the supported formats
+	ErrInvalidDueDate error = errors.New("invalid due date")
+	// ErrDeadlinePassed is returned when bundling an assignment after its due date
+	ErrDeadlinePassed error = errors.New("deadline has passed")
+
+	// Layouts are the absolute date formats accepted by Parse, tried in order.
+	// Layouts without time of day get the configured default time
+	Layouts = []string{
+		time.RFC3339,
+		"2006-01-02",
+		"02.01.2006",
+		"2.1.2006",
+		"January 2, 2006",
+		"January 2 2006",
+		"Jan 2, 2006",
+		"Jan 2 2006",
+		"2 January 2006",
+		"2. January 2006",
+		"2 Jan 2006",
+	}
+
+	// timeOfDayPattern matches a trailing time of day, e.g., "at 23:59" or "12:00"
+	timeOfDayPattern = regexp.MustCompile(`(?i)(?:^|\s+)(?:at\s+)?([0-9]{1,2}):([0-9]{2})$`)
+	// offsetPattern matches offsets relative to now, e.g., "+7d" or "+36h"
+	offsetPattern = regexp.MustCompile(`^\+([0-9]+)\s*([mhdw])$`)
+	// inPattern matches offsets in words, e.g., "in 2 weeks"
+	inPattern = regexp.MustCompile(`(?i)^in\s+([0-9]+)\s+(minute|hour|day|week)s?$`)
+	// ordinalSuffixPattern matches English ordinal suffixes of days, e.g., "20th"
+	ordinalSuffixPattern = regexp.MustCompile(`\b([0-9]{1,2})(?:st|nd|rd|th)\b`)
+
+	weekdays = map[string]time.Weekday{
+		"sunday": time.Sunday, "sun": time.Sunday,
+		"monday": time.Monday, "mon": time.Monday,
+		"tuesday": time.Tuesday, "tue": time.Tuesday,
+		"wednesday": time.Wednesday, "wed": time.Wednesday,
+		"thursday": time.Thursday, "thu": time.Thursday,
+		"friday": time.Friday, "fri": time.Friday,
+		"saturday": time.Saturday, "sat": time.Saturday,
+	}
+)
+
+// Parser parses due dates relative to a point in time in the course's timezone
+type Parser struct {
+	// Now is the reference point for relative due dates
+	Now time.Time
+	// Location is the course's timezone, in which all due dates without explicit
+	// offset are interpreted
+	Location *time.Location
+	// TimeOfDay is the time used for dates without time, in 15:04 format
+	TimeOfDay string
+}
+
+// NewParser creates a parser for the given timezone name and default time of day.
+// An empty timezone selects the local timezone, an empty time of day selects
+// DefaultTimeOfDay
+func NewParser(timezone string, timeOfDay string) (*Parser, error) {
+	loc, err := Location(timezone)
+	if err != nil {
+		return nil, err
+	}
+	if timeOfDay == "" {
+		timeOfDay = DefaultTimeOfDay
+	}
+	if _, err := time.Parse("15:04", timeOfDay); err != nil {
+		return nil, fmt.Errorf("invalid default time of day %q, expected 15:04 format", timeOfDay)
+	}
+	return &Parser{
+		Now:       time.Now(),
+		Location:  loc,
+		TimeOfDay: timeOfDay,
+	}, nil
+}
+
+// Location loads the timezone with the given IANA name, e.g., "Europe/Berlin",
+// or returns the local timezone if name is empty
+func Location(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q, %w", name, err)
+	}
+	return loc, nil
+}
+
+// Parse parses a due date, either absolute in one of the Layouts, or relative to
+// the parser's reference point, e.g., "tomorrow", "friday", "next friday 12:00",
+// "in 2 weeks", "+7d", or "+36h". Except for offsets in minutes and hours, a time
+// of day can be appended, e.g., "at 23:59", which defaults to the parser's
+// TimeOfDay otherwise. A bare weekday is the next such day, including today,
+// while "next" skips today.
+func (p *Parser) Parse(input string) (time.Time, error) {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("%w: empty", ErrInvalidDueDate)
+	}
+	now := p.Now.In(p.Location)
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	if m := offsetPattern.FindStringSubmatch(strings.ToLower(s)); m != nil {
+		return p.offset(now, m[1], m[2])
+	}
+	if m := inPattern.FindStringSubmatch(s); m != nil {
+		return p.offset(now, m[1], strings.ToLower(m[2])[:1])
+	}
+
+	hour, minute, err := clock(p.TimeOfDay)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if m := timeOfDayPattern.FindStringSubmatchIndex(s); m != nil {
+		hour, _ = strconv.Atoi(s[m[2]:m[3]])
+		minute, _ = strconv.Atoi(s[m[4]:m[5]])
+		if hour > 23 || minute > 59 {
+			return time.Time{}, fmt.Errorf("%w: invalid time of day in %q", ErrInvalidDueDate, input)
+		}
+		s = strings.TrimSpace(s[:m[0]])
+	}
+
+	day, err := p.day(now, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q, use a date such as 2006-01-02 or a relative date such as \"next friday 23:59\" or \"+7d\"", ErrInvalidDueDate, input)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, p.Location), nil
+}
+
+// day resolves the date part of a due date, relative to now if necessary
+func (p *Parser) day(now time.Time, s string) (time.Time, error) {
+	lower := strings.ToLower(s)
+	switch lower {
+	case "", "today":
+		return now, nil
+	case "tomorrow":
+		return now.AddDate(0, 0, 1), nil
+	}
+
+	next := false
+	if strings.HasPrefix(lower, "next ") {
+		next = true
+		lower = strings.TrimSpace(strings.TrimPrefix(lower, "next "))
+	}
+	if wd, ok := weekdays[lower]; ok {
+		days := (int(wd) - int(now.Weekday()) + 7) % 7
+		if days == 0 && next {
+			days = 7
+		}
+		return now.AddDate(0, 0, days), nil
+	}
+	if next && lower == "week" {
+		return now.AddDate(0, 0, 7), nil
+	}
+
+	s = ordinalSuffixPattern.ReplaceAllString(s, "$1")
+	for _, layout := range Layouts {
+		if t, err := time.ParseInLocation(layout, s, p.Location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrInvalidDueDate
+}
+
+// offset adds a number of units to now. Days and weeks keep the date only and
+// use the default time of day
+func (p *Parser) offset(now time.Time, n string, unit string) (time.Time, error) {
+	i, err := strconv.Atoi(n)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", ErrInvalidDueDate, err)
+	}
+	switch unit {
+	case "m":
+		return now.Add(time.Duration(i) * time.Minute).Truncate(time.Minute), nil
+	case "h":
+		return now.Add(time.Duration(i) * time.Hour).Truncate(time.Minute), nil
+	}
+	days := i
+	if unit == "w" {
+		days = 7 * i
+	}
+	hour, minute, err := clock(p.TimeOfDay)
+	if err != nil {
+		return time.Time{}, err
+	}
+	d := now.AddDate(0, 0, days)
+	return time.Date(d.Year(), d.Month(), d.Day(), hour, minute, 0, 0, p.Location), nil
+}
+
+func clock(s string) (int, int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time of day %q, expected 15:04 format", s)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// ParseDuration parses a duration like time.ParseDuration, e.g., "90m" or "36h",
+// and additionally in days or weeks, e.g., "2d" or "1w". Negative durations are
+// invalid
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit != 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q, use, e.g., 24h, 2d, or 1w", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q, use, e.g., 24h, 2d, or 1w", s)
+	}
+	return d, nil
+}
+
+// Humanize formats the time left until a due date coarsely, e.g., "in 2d 3h",
+// "in 45m", or "1d 2h ago" for due dates in the past
+func Humanize(due time.Time, now time.Time) string {
+	d := due.Sub(now)
+	past := d < 0
+	if past {
+		d = -d
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	var s string
+	switch {
+	case days > 0:
+		s = fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		s = fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		s = fmt.Sprintf("%dm", minutes)
+	}
+	if past {
+		return s + " ago"
+	}
+	return "in " + s
+}
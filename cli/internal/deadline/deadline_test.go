@@ -0,0 +1,132 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deadline
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone database not available")
+	}
+	// Monday, October 19, 2026, 10:30 in Berlin
+	now := time.Date(2026, time.October, 19, 10, 30, 0, 0, berlin)
+	p := &Parser{
+		Now:       now,
+		Location:  berlin,
+		TimeOfDay: DefaultTimeOfDay,
+	}
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"2026-10-23", time.Date(2026, time.October, 23, 23, 59, 0, 0, berlin)},
+		{"2026-10-23 12:00", time.Date(2026, time.October, 23, 12, 0, 0, 0, berlin)},
+		{"23.10.2026", time.Date(2026, time.October, 23, 23, 59, 0, 0, berlin)},
+		{"October 23rd, 2026 at 8:15", time.Date(2026, time.October, 23, 8, 15, 0, 0, berlin)},
+		{"2026-10-23T12:00:00Z", time.Date(2026, time.October, 23, 12, 0, 0, 0, time.UTC)},
+		{"today", time.Date(2026, time.October, 19, 23, 59, 0, 0, berlin)},
+		{"tomorrow 10:00", time.Date(2026, time.October, 20, 10, 0, 0, 0, berlin)},
+		{"friday", time.Date(2026, time.October, 23, 23, 59, 0, 0, berlin)},
+		{"monday", time.Date(2026, time.October, 19, 23, 59, 0, 0, berlin)},
+		{"next monday", time.Date(2026, time.October, 26, 23, 59, 0, 0, berlin)},
+		{"Next Friday 23:59", time.Date(2026, time.October, 23, 23, 59, 0, 0, berlin)},
+		{"+7d", time.Date(2026, time.October, 26, 23, 59, 0, 0, berlin)},
+		{"+2w", time.Date(2026, time.November, 2, 23, 59, 0, 0, berlin)},
+		{"+36h", time.Date(2026, time.October, 20, 22, 30, 0, 0, berlin)},
+		{"in 2 weeks", time.Date(2026, time.November, 2, 23, 59, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		d, err := p.Parse(tt.input)
+		if err != nil {
+			t.Errorf("failed to parse %q, %v", tt.input, err)
+			continue
+		}
+		if !d.Equal(tt.expected) {
+			t.Errorf("expected %q to parse as %s, found %s", tt.input, tt.expected, d)
+		}
+	}
+
+	for _, input := range []string{"", "someday", "friday 25:00", "+7y"} {
+		if _, err := p.Parse(input); !errors.Is(err, ErrInvalidDueDate) {
+			t.Errorf("expected ErrInvalidDueDate for %q, found %v", input, err)
+		}
+	}
+}
+
+func TestNewParser(t *testing.T) {
+	if _, err := NewParser("Mars/Olympus_Mons", ""); err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+	if _, err := NewParser("", "noon"); err == nil {
+		t.Error("expected error for invalid time of day")
+	}
+	p, err := NewParser("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Location != time.Local || p.TimeOfDay != DefaultTimeOfDay {
+		t.Errorf("expected local timezone and default time of day, found %s and %s", p.Location, p.TimeOfDay)
+	}
+}
+
+func TestHumanize(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		due      time.Time
+		expected string
+	}{
+		{now.Add(51 * time.Hour), "in 2d 3h"},
+		{now.Add(90 * time.Minute), "in 1h 30m"},
+		{now.Add(45 * time.Minute), "in 45m"},
+		{now.Add(-26 * time.Hour), "1d 2h ago"},
+	}
+	for _, tt := range tests {
+		if s := Humanize(tt.due, now); s != tt.expected {
+			t.Errorf("expected %q, found %q", tt.expected, s)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"48h": 48 * time.Hour,
+		"90m": 90 * time.Minute,
+		"2d":  48 * time.Hour,
+		"1w":  7 * 24 * time.Hour,
+	}
+	for s, expected := range tests {
+		d, err := ParseDuration(s)
+		if err != nil {
+			t.Errorf("expected %q to parse, found %v", s, err)
+			continue
+		}
+		if d != expected {
+			t.Errorf("expected %v for %q, found %v", expected, s, d)
+		}
+	}
+	for _, s := range []string{"", "soon", "-2d", "xd"} {
+		if _, err := ParseDuration(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/zoomoid/assignments/v1/internal/bundle"
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/naming"
+	"github.com/zoomoid/assignments/v1/internal/runner"
+	"github.com/zoomoid/assignments/v1/internal/util"
+)
+
+// BuildStatus describes the compiled PDF of an assignment relative to its sources
+type BuildStatus string
+
+const (
+	// BuildStatusMissing indicates that there is no PDF in dist/
+	BuildStatusMissing BuildStatus = "missing"
+	// BuildStatusStale indicates that sources were modified after the PDF was built
+	BuildStatusStale BuildStatus = "stale"
+	// BuildStatusFresh indicates that the PDF is newer than all sources
+	BuildStatusFresh BuildStatus = "fresh"
+)
+
+var (
+	// errStale stops walking the sources once a file newer than the PDF is found
+	errStale error = errors.New("sources are newer than the PDF")
+)
+
+// Assignment is the state of a single assignment in the repository
+type Assignment struct {
+	// Number is the assignment's ID, written as plain number for assignments
+	// without suffix
+	Number config.AssignmentID `json:"number"`
+	// ID is the assignment's ID with leading zeros, e.g., "05a"
+	ID string `json:"id"`
+	// Directory is the assignment's source directory relative to the repository's
+	// root, or empty if it does not exist
+	Directory string `json:"directory,omitempty"`
+	// Due is the assignment's due date, if recorded
+	Due *time.Time `json:"due,omitempty"`
+	// State is the assignment's lifecycle state recorded in the configuration
+	State config.State `json:"state,omitempty"`
+	// History contains the assignment's lifecycle transitions, oldest first
+	History []config.Transition `json:"history,omitempty"`
+	// Build is the state of the assignment's PDF
+	Build BuildStatus `json:"build"`
+	// PDF is the path of the PDF in dist/ relative to the repository's root, if built
+	PDF string `json:"pdf,omitempty"`
+	// BuiltAt is the modification time of the PDF, if built
+	BuiltAt *time.Time `json:"builtAt,omitempty"`
+	// Archives are the paths of all archives of the assignment in dist/
+	Archives []string `json:"archives"`
+	// Tag is the git tag releasing the assignment, if any
+	Tag string `json:"tag,omitempty"`
+}
+
+// Bundled returns true if there is at least one archive of the assignment
+func (a *Assignment) Bundled() bool {
+	return len(a.Archives) > 0
+}
+
+// Released returns true if the assignment is tagged in git
+func (a *Assignment) Released() bool {
+	return a.Tag != ""
+}
+
+// Scan collects all assignments known to the repository, i.e., those with a source
+// directory, a PDF in dist/, or metadata in the configuration's status, and
+// determines their state. Git tags are only considered if git is available.
+// The context's configuration must be read before.
+func Scan(ctx *context.AppContext) ([]*Assignment, error) {
+	assignments := map[config.AssignmentID]*Assignment{}
+	get := func(n config.AssignmentID) *Assignment {
+		a, ok := assignments[n]
+		if !ok {
+			a = &Assignment{
+				Number:   n,
+				ID:       ctx.Naming().ID(n),
+				Build:    BuildStatusMissing,
+				Archives: []string{},
+			}
+			assignments[n] = a
+		}
+		return a
+	}
+
+	directories, err := matching(ctx.Root, ctx.Naming(), true)
+	if err != nil {
+		return nil, err
+	}
+	for n, dir := range directories {
+		get(n).Directory = dir
+	}
+
+	dist := filepath.Join(ctx.Root, "dist")
+	pdfs, err := matching(dist, ctx.Naming(), false)
+	if err != nil {
+		return nil, err
+	}
+	for n, pdf := range pdfs {
+		get(n).PDF = filepath.Join("dist", pdf)
+	}
+
+	if ctx.Configuration.Status != nil {
+		for id := range ctx.Configuration.Status.Assignments {
+			n, err := config.ParseAssignmentID(id)
+			if err != nil {
+				continue
+			}
+			get(n)
+		}
+	}
+
+	tags, err := Tags(ctx.Root)
+	if err != nil {
+		log.Debug().Err(err).Msg("Skipping git tags")
+		tags = util.NewSet()
+	}
+
+	result := make([]*Assignment, 0, len(assignments))
+	for _, a := range assignments {
+		if status := ctx.Configuration.Status.Lookup(a.ID); status != nil {
+			a.Due = status.Due
+			a.State = status.State
+			a.History = status.History
+		}
+		// archive names and due dates are determined with the assignment's
+		// configuration file applied, as when bundling
+		spec := ctx.Configuration.Spec
+		if a.Directory != "" {
+			assignmentCtx, err := ctx.ForAssignment(a.Directory)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Ignoring configuration file of assignment %s", a.ID)
+			} else {
+				spec = assignmentCtx.Configuration.Spec
+				if status := assignmentCtx.Configuration.Status.Lookup(a.ID); status != nil && status.Due != nil {
+					a.Due = status.Due
+				}
+			}
+		}
+
+		if a.PDF != "" {
+			a.Build, a.BuiltAt, err = buildStatus(ctx.Root, a)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, backend := range bundle.Backends {
+			name, err := bundle.ArchiveNameFor(spec, ctx.Naming(), a.ID, backend)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := os.Stat(filepath.Join(dist, name)); err == nil {
+				a.Archives = append(a.Archives, filepath.Join("dist", name))
+			}
+		}
+
+		if tag := ctx.Naming().Name(a.ID); tags.Has(tag) {
+			a.Tag = tag
+		}
+
+		result = append(result, a)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Number.Less(result[j].Number)
+	})
+	return result, nil
+}
+
+// Tags lists all git tags of the repository at root
+func Tags(root string) (util.Set, error) {
+	cmd := exec.Command("git", "tag", "--list")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	tags := util.NewSet()
+	for _, t := range strings.Split(string(out), "\n") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags.Insert(t)
+		}
+	}
+	return tags, nil
+}
+
+// matching returns the names of all assignment directories, or all assignment PDFs,
+// in dir, keyed by the assignment ID from the naming scheme. A missing dir
+// has no entries
+func matching(dir string, scheme *naming.Scheme, directories bool) (map[config.AssignmentID]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[config.AssignmentID]string{}, nil
+		}
+		return nil, err
+	}
+	result := map[config.AssignmentID]string{}
+	for _, e := range entries {
+		if e.IsDir() != directories || (!directories && filepath.Ext(e.Name()) != ".pdf") {
+			continue
+		}
+		n, err := scheme.Parse(e.Name())
+		if err != nil {
+			continue
+		}
+		result[n] = e.Name()
+	}
+	return result, nil
+}
+
+// buildStatus compares the modification time of the assignment's PDF with the most
+// recently modified source file in the assignment's directory
+func buildStatus(root string, a *Assignment) (BuildStatus, *time.Time, error) {
+	fi, err := os.Stat(filepath.Join(root, a.PDF))
+	if err != nil {
+		return BuildStatusMissing, nil, nil
+	}
+	builtAt := fi.ModTime()
+	if a.Directory == "" {
+		return BuildStatusFresh, &builtAt, nil
+	}
+
+	err = filepath.WalkDir(filepath.Join(root, a.Directory), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || isByproduct(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(builtAt) {
+			return errStale
+		}
+		return nil
+	})
+	if errors.Is(err, errStale) {
+		return BuildStatusStale, &builtAt, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return BuildStatusFresh, &builtAt, nil
+}
+
+// isByproduct returns true for files created by compiling the assignment, i.e.,
+// those matched by runner.DefaultPatterns and the PDF next to the main source file
+func isByproduct(name string) bool {
+	return name == "assignment.pdf" || runner.IsByproduct(name)
+}
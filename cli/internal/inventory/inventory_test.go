@@ -0,0 +1,129 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+)
+
+func touch(t *testing.T, path string, mtime time.Time) {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	past := time.Now().Add(-time.Hour)
+	now := time.Now()
+
+	// fresh: sources are older than the PDF, byproducts are ignored
+	touch(t, filepath.Join(root, "assignment-01", "assignment.tex"), past)
+	touch(t, filepath.Join(root, "assignment-01", "assignment.log"), now)
+	touch(t, filepath.Join(root, "assignment-01", "assignment.synctex.gz"), now)
+	touch(t, filepath.Join(root, "dist", "assignment-01.pdf"), now)
+	touch(t, filepath.Join(root, "dist", "assignment-01.zip"), now)
+	// stale: sources were modified after the build
+	touch(t, filepath.Join(root, "assignment-02", "code", "main.py"), now)
+	touch(t, filepath.Join(root, "dist", "assignment-02.pdf"), past)
+	// missing: never built
+	touch(t, filepath.Join(root, "assignment-10", "assignment.tex"), past)
+
+	due := time.Date(2026, time.October, 23, 23, 59, 0, 0, time.UTC)
+	cfg := config.Minimal()
+	cfg.Status.Upsert("04").Due = &due
+
+	assignments, err := Scan(&context.AppContext{
+		Root:          root,
+		Cwd:           root,
+		Configuration: cfg,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		id    string
+		build BuildStatus
+	}{
+		{"01", BuildStatusFresh},
+		{"02", BuildStatusStale},
+		{"04", BuildStatusMissing},
+		{"10", BuildStatusMissing},
+	}
+	if len(assignments) != len(expected) {
+		t.Fatalf("expected %d assignments, found %d", len(expected), len(assignments))
+	}
+	for i, e := range expected {
+		a := assignments[i]
+		if a.ID != e.id || a.Build != e.build {
+			t.Errorf("expected assignment %s to be %s, found %s is %s", e.id, e.build, a.ID, a.Build)
+		}
+	}
+	if !assignments[0].Bundled() || assignments[0].Archives[0] != filepath.Join("dist", "assignment-01.zip") {
+		t.Errorf("expected assignment 01 to be bundled, found %v", assignments[0].Archives)
+	}
+	if assignments[1].Bundled() {
+		t.Errorf("expected assignment 02 not to be bundled, found %v", assignments[1].Archives)
+	}
+	if assignments[2].Due == nil || !assignments[2].Due.Equal(due) || assignments[2].Directory != "" {
+		t.Errorf("expected assignment 04 with due date only, found %+v", assignments[2])
+	}
+}
+
+func TestScanAssignmentConfiguration(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	touch(t, filepath.Join(root, "assignment-03", "assignment.tex"), now)
+	touch(t, filepath.Join(root, "dist", "sheet-03.zip"), now)
+	override := "due: 2026-11-06T12:00:00Z\nspec:\n  bundle:\n    template: \"sheet-{{._id}}.{{._format}}\"\n"
+	if err := os.WriteFile(filepath.Join(root, "assignment-03", config.AssignmentConfigurationFileName), []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the configuration has no status, as when read from a file without one
+	assignments, err := Scan(&context.AppContext{
+		Root:          root,
+		Cwd:           root,
+		Configuration: &config.Configuration{Spec: &config.ConfigurationSpec{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assignments) != 1 {
+		t.Fatalf("expected 1 assignment, found %d", len(assignments))
+	}
+	a := assignments[0]
+	if len(a.Archives) != 1 || a.Archives[0] != filepath.Join("dist", "sheet-03.zip") {
+		t.Errorf("expected archive named by assignment.yaml, found %v", a.Archives)
+	}
+	if due := time.Date(2026, time.November, 6, 12, 0, 0, 0, time.UTC); a.Due == nil || !a.Due.Equal(due) {
+		t.Errorf("expected due date from assignment.yaml, found %v", a.Due)
+	}
+}
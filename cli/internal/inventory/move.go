@@ -0,0 +1,441 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/zoomoid/assignments/v1/internal/bundle"
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/template"
+	"github.com/zoomoid/assignments/v1/internal/trash"
+)
+
+// Change is a file, directory, or git tag of an assignment that was moved or
+// removed
+type Change struct {
+	// From is the path relative to the repository's root, or the tag's name
+	From string
+	// To is the new path or tag name, empty for removals
+	To string
+	// Tag is true for git tags
+	Tag bool
+}
+
+// Result lists the changes made by Move and Remove
+type Result struct {
+	Changes []Change
+	// Patched is the source file whose \sheet{} was updated to the new ID, if any
+	Patched string
+	// Snapshot contains the files, status, and tags removed or overwritten, if any
+	Snapshot *trash.Snapshot
+}
+
+// Find returns the assignment with the given ID, or nil if the repository knows
+// neither its sources, its artifacts, nor its status
+func Find(ctx *context.AppContext, id config.AssignmentID) (*Assignment, error) {
+	assignments, err := Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range assignments {
+		if a.Number == id {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+// Move renames the source directory, PDF, and archives of an assignment, its git
+// tag, and its entry in .status.assignments to another ID. If the sheet was
+// generated from the default template, \sheet{} in its main source file is
+// updated as well. Nothing is changed if any target already exists, unless force
+// is set, in which case the targets are kept in a snapshot in the trash and then
+// replaced. If the assignment was the current one in .status.assignment, the
+// latest remaining assignment becomes the current one
+func Move(ctx *context.AppContext, from config.AssignmentID, to config.AssignmentID, force bool) (*Result, error) {
+	n := ctx.Naming()
+	fromID, toID := n.ID(from), n.ID(to)
+	if fromID == toID {
+		return nil, fmt.Errorf("assignment %s cannot be moved onto itself", fromID)
+	}
+	a, err := Find(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, fmt.Errorf("assignment %s does not exist", fromID)
+	}
+
+	renames, err := artifacts(ctx, a, toID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Directory != "" {
+		renames = append([]Change{{From: a.Directory, To: n.Directory(to)}}, renames...)
+	}
+	if a.Tag != "" {
+		renames = append(renames, Change{From: a.Tag, To: n.Name(toID), Tag: true})
+	}
+
+	conflicts := []string{}
+	overwritten := &trash.Snapshot{Operation: "mv", Assignment: toID}
+	paths := []string{}
+	tags, _ := Tags(ctx.Root)
+	for _, r := range renames {
+		if r.Tag {
+			if tags.Has(r.To) {
+				conflicts = append(conflicts, "tag "+r.To)
+				if err := keepTag(ctx.Root, overwritten, r.To); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(ctx.Root, r.To)); err == nil {
+			conflicts = append(conflicts, r.To)
+			paths = append(paths, r.To)
+		}
+	}
+	if status := ctx.Configuration.Status.Lookup(toID); status != nil && len(status.History) > 0 {
+		conflicts = append(conflicts, fmt.Sprintf("status of assignment %s", toID))
+		overwritten.Status = map[string]*config.AssignmentStatus{toID: status.Clone()}
+	}
+	if len(conflicts) > 0 && !force {
+		return nil, fmt.Errorf("%s already exist(s), use --force to overwrite", strings.Join(conflicts, ", "))
+	}
+
+	result := &Result{Changes: []Change{}}
+	if len(conflicts) > 0 {
+		result.Snapshot, err = save(ctx, overwritten, paths...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range renames {
+		if r.Tag {
+			if err := renameTag(ctx.Root, r.From, r.To, force); err != nil {
+				return result, err
+			}
+		} else {
+			dst := filepath.Join(ctx.Root, r.To)
+			if force {
+				if err := os.RemoveAll(dst); err != nil {
+					return result, err
+				}
+			}
+			if err := os.Rename(filepath.Join(ctx.Root, r.From), dst); err != nil {
+				return result, err
+			}
+		}
+		result.Changes = append(result.Changes, r)
+	}
+
+	if a.Directory != "" {
+		patched, err := patchSheet(ctx, n.Directory(to), fromID, toID)
+		if err != nil {
+			return result, err
+		}
+		result.Patched = patched
+	}
+
+	status := ctx.Configuration.Status
+	if status == nil {
+		return result, nil
+	}
+	if s, ok := status.Assignments[fromID]; ok {
+		delete(status.Assignments, fromID)
+		status.Assignments[toID] = s
+	}
+	if status.Assignment == from {
+		status.Assignment, err = latest(ctx)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// Remove deletes the source directory, PDF, and archives of an assignment and its
+// entry in .status.assignments. Assignments that were submitted, graded, or
+// released are only removed with force, which also deletes their git tag. All of
+// it is kept in a snapshot in the trash before. If the assignment was the current
+// one in .status.assignment, the latest remaining assignment becomes the current
+// one
+func Remove(ctx *context.AppContext, id config.AssignmentID, force bool) (*Result, error) {
+	n := ctx.Naming()
+	a, err := Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, fmt.Errorf("assignment %s does not exist", n.ID(id))
+	}
+	if !force {
+		if a.State.Manual() {
+			return nil, fmt.Errorf("assignment %s is %s, use --force to remove it anyway", a.ID, a.State)
+		}
+		if a.Released() {
+			return nil, fmt.Errorf("assignment %s is released as %s, use --force to remove it and its tag anyway", a.ID, a.Tag)
+		}
+	}
+
+	removals, err := artifacts(ctx, a, "")
+	if err != nil {
+		return nil, err
+	}
+	if a.Directory != "" {
+		removals = append([]Change{{From: a.Directory}}, removals...)
+	}
+	if a.Tag != "" {
+		removals = append(removals, Change{From: a.Tag, Tag: true})
+	}
+
+	removed := &trash.Snapshot{Operation: "rm", Assignment: a.ID}
+	paths := []string{}
+	for _, r := range removals {
+		if !r.Tag {
+			paths = append(paths, r.From)
+		} else if err := keepTag(ctx.Root, removed, r.From); err != nil {
+			return nil, err
+		}
+	}
+	if status := ctx.Configuration.Status.Lookup(a.ID); status != nil {
+		removed.Status = map[string]*config.AssignmentStatus{a.ID: status.Clone()}
+	}
+	if ctx.Configuration.Status != nil && ctx.Configuration.Status.Assignment == id {
+		current := id
+		removed.Current = &current
+	}
+
+	result := &Result{Changes: []Change{}}
+	result.Snapshot, err = save(ctx, removed, paths...)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range removals {
+		if r.Tag {
+			if err := git(ctx.Root, "tag", "-d", r.From); err != nil {
+				return result, err
+			}
+		} else if err := os.RemoveAll(filepath.Join(ctx.Root, r.From)); err != nil {
+			return result, err
+		}
+		result.Changes = append(result.Changes, Change{From: r.From, Tag: r.Tag})
+	}
+
+	status := ctx.Configuration.Status
+	if status == nil {
+		return result, nil
+	}
+	delete(status.Assignments, a.ID)
+	if status.Assignment == id {
+		status.Assignment, err = latest(ctx)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// artifacts pairs the PDF and the archives of an assignment with their names for
+// the ID to. Archive names are determined with the assignment's configuration
+// file applied, as when bundling
+func artifacts(ctx *context.AppContext, a *Assignment, to string) ([]Change, error) {
+	n := ctx.Naming()
+	changes := []Change{}
+	if a.PDF != "" {
+		changes = append(changes, Change{From: a.PDF, To: target(filepath.Join("dist", n.PDF(to)), to)})
+	}
+
+	spec := ctx.Configuration.Spec
+	if a.Directory != "" {
+		assignmentCtx, err := ctx.ForAssignment(a.Directory)
+		if err != nil {
+			return nil, err
+		}
+		spec = assignmentCtx.Configuration.Spec
+	}
+	for _, backend := range bundle.Backends {
+		name, err := bundle.ArchiveNameFor(spec, n, a.ID, backend)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(filepath.Join(ctx.Root, "dist", name)); err != nil {
+			continue
+		}
+		renamed := ""
+		if to != "" {
+			renamed, err = bundle.ArchiveNameFor(spec, n, to, backend)
+			if err != nil {
+				return nil, err
+			}
+		}
+		changes = append(changes, Change{From: filepath.Join("dist", name), To: target(filepath.Join("dist", renamed), to)})
+	}
+	return changes, nil
+}
+
+// target returns path, or the empty string if there is no target ID for removals
+func target(path string, to string) string {
+	if to == "" {
+		return ""
+	}
+	return path
+}
+
+// patchSheet replaces \sheet{from} by \sheet{to} in the main source file of sheets
+// generated from the default template. Custom templates may render the ID
+// anywhere, so they are left alone. Returns the patched file, if any
+func patchSheet(ctx *context.AppContext, directory string, from string, to string) (string, error) {
+	assignmentCtx, err := ctx.ForAssignment(directory)
+	if err != nil {
+		return "", err
+	}
+	if assignmentCtx.Configuration.Spec.Template != "" {
+		return "", nil
+	}
+	file := filepath.Join(directory, "assignment.tex")
+	source, err := os.ReadFile(filepath.Join(ctx.Root, file))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	// the default template escapes the ID, e.g., named IDs with underscores
+	old := []byte(`\sheet{` + template.TexEscape(from) + `}`)
+	if !bytes.Contains(source, old) {
+		return "", nil
+	}
+	source = bytes.Replace(source, old, []byte(`\sheet{`+template.TexEscape(to)+`}`), 1)
+	if err := os.WriteFile(filepath.Join(ctx.Root, file), source, 0644); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+// latest returns the ID of the last numbered assignment with a source directory,
+// or the zero ID if there is none
+func latest(ctx *context.AppContext) (config.AssignmentID, error) {
+	assignments, err := Scan(ctx)
+	if err != nil {
+		return config.AssignmentID{}, err
+	}
+	for i := len(assignments) - 1; i >= 0; i-- {
+		if a := assignments[i]; a.Directory != "" && !a.Number.Named() {
+			return a.Number, nil
+		}
+	}
+	return config.AssignmentID{}, nil
+}
+
+// save keeps paths and the snapshot's status and tags in the trash
+func save(ctx *context.AppContext, snapshot *trash.Snapshot, paths ...string) (*trash.Snapshot, error) {
+	store, err := trash.Open(ctx.Root, ctx.Configuration.Spec.TrashOptions)
+	if err != nil {
+		return nil, err
+	}
+	return store.Save(snapshot, paths...)
+}
+
+// keepTag records the commit tagged by tag in the snapshot, such that the tag can
+// be restored
+func keepTag(root string, snapshot *trash.Snapshot, tag string) error {
+	cmd := exec.Command("git", "rev-parse", tag+"^{}")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to resolve tag %s, %w", tag, err)
+	}
+	if snapshot.Tags == nil {
+		snapshot.Tags = map[string]string{}
+	}
+	snapshot.Tags[tag] = strings.TrimSpace(string(out))
+	return nil
+}
+
+// RestoreTags creates the tags deleted by an operation kept in the snapshot, see
+// trash.Snapshot.Tags. Existing tags are moved to the kept commit
+func RestoreTags(root string, snapshot *trash.Snapshot) error {
+	for tag, commit := range snapshot.Tags {
+		if err := git(root, "tag", "-f", tag, commit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renameTag points the tag to at the commit tagged by from and deletes from.
+// Annotated tags are recreated with their message. Only the local repository is
+// changed
+func renameTag(root string, from string, to string, force bool) error {
+	args := []string{"tag"}
+	if force {
+		args = append(args, "-f")
+	}
+	message, annotated, err := tagMessage(root, from)
+	if err != nil {
+		return err
+	}
+	if annotated {
+		args = append(args, "-a", "-m", message)
+	}
+	if err := git(root, append(args, to, from+"^{}")...); err != nil {
+		return err
+	}
+	return git(root, "tag", "-d", from)
+}
+
+// tagMessage returns the message of an annotated tag without its signature, and
+// false for lightweight tags
+func tagMessage(root string, tag string) (string, bool, error) {
+	cmd := exec.Command("git", "for-each-ref", "--format=%(objecttype)%00%(contents:subject)%00%(contents:body)", "refs/tags/"+tag)
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read tag %s, %w", tag, err)
+	}
+	fields := strings.SplitN(string(out), "\x00", 3)
+	if len(fields) != 3 || fields[0] != "tag" {
+		return "", false, nil
+	}
+	message := fields[1]
+	if body := strings.TrimSpace(fields[2]); body != "" {
+		message += "\n\n" + body
+	}
+	return message, true, nil
+}
+
+// git runs a git command in root, including its output in errors
+func git(root string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = root
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git %s failed, %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
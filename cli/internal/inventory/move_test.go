@@ -0,0 +1,189 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+)
+
+func exists(root string, path string) bool {
+	_, err := os.Stat(filepath.Join(root, path))
+	return err == nil
+}
+
+func TestMove(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	touch(t, filepath.Join(root, "assignment-02", "assignment.tex"), now)
+	touch(t, filepath.Join(root, "dist", "assignment-04.pdf"), now)
+	touch(t, filepath.Join(root, "dist", "assignment-04.zip"), now)
+	touch(t, filepath.Join(root, "assignment-04", "assignment.tex"), now)
+	if err := os.WriteFile(filepath.Join(root, "assignment-04", "assignment.tex"), []byte("\\sheet{04}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Minimal()
+	cfg.Status.Assignment = config.NumberedID(4)
+	cfg.Status.Upsert("04").Transition(config.StateGenerated, now, "")
+	ctx := &context.AppContext{Root: root, Cwd: root, Configuration: cfg}
+
+	result, err := Move(ctx, config.NumberedID(4), config.NumberedID(5), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 3 {
+		t.Errorf("expected directory, PDF, and archive to be moved, found %+v", result.Changes)
+	}
+	for _, p := range []string{"assignment-05", filepath.Join("dist", "assignment-05.pdf"), filepath.Join("dist", "assignment-05.zip")} {
+		if !exists(root, p) {
+			t.Errorf("expected %s to exist", p)
+		}
+	}
+	if exists(root, "assignment-04") {
+		t.Error("expected assignment-04 to be moved")
+	}
+	source, err := os.ReadFile(filepath.Join(root, "assignment-05", "assignment.tex"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(source) != "\\sheet{05}\n" || result.Patched == "" {
+		t.Errorf("expected \\sheet{} to be patched, found %q", source)
+	}
+	if cfg.Status.Lookup("04") != nil || cfg.Status.Lookup("05") == nil {
+		t.Error("expected status to be moved to 05")
+	}
+	if cfg.Status.Assignment != config.NumberedID(5) {
+		t.Errorf("expected current assignment 5, found %s", cfg.Status.Assignment)
+	}
+
+	// existing targets are only overwritten with force
+	touch(t, filepath.Join(root, "dist", "assignment-02.pdf"), now)
+	if _, err := Move(ctx, config.NumberedID(5), config.NumberedID(2), false); err == nil || !strings.Contains(err.Error(), "assignment-02") {
+		t.Errorf("expected conflict with assignment-02, found %v", err)
+	}
+	if !exists(root, "assignment-05") {
+		t.Error("expected nothing to change on conflict")
+	}
+	if _, err := Move(ctx, config.NumberedID(5), config.NumberedID(2), true); err != nil {
+		t.Fatal(err)
+	}
+	if exists(root, "assignment-05") || !exists(root, filepath.Join("dist", "assignment-02.zip")) {
+		t.Error("expected assignment 5 to replace assignment 2")
+	}
+
+	if _, err := Move(ctx, config.NumberedID(7), config.NumberedID(8), false); err == nil {
+		t.Error("expected error for unknown assignment")
+	}
+
+	// the default template escapes underscores in named IDs
+	if err := os.WriteFile(filepath.Join(root, "assignment-02", "assignment.tex"), []byte("\\sheet{02}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Move(ctx, config.NumberedID(2), config.AssignmentID{Name: "exam_prep"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Move(ctx, config.AssignmentID{Name: "exam_prep"}, config.AssignmentID{Name: "mock_exam"}, false); err != nil {
+		t.Fatal(err)
+	}
+	source, err = os.ReadFile(filepath.Join(root, "assignment-mock_exam", "assignment.tex"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(source) != "\\sheet{mock\\_exam}\n" {
+		t.Errorf("expected \\sheet{} to be patched with the escaped ID, found %q", source)
+	}
+}
+
+func TestMoveAnnotatedTag(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, v := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(v, "Max Mustermann")
+	}
+	for _, v := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(v, "max@example.com")
+	}
+	root := t.TempDir()
+	touch(t, filepath.Join(root, "assignment-04", "assignment.tex"), time.Now())
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"commit", "-q", "-m", "Add assignment 04"},
+		{"tag", "-a", "-m", "Release assignment 04\n\nSubmitted on time", "assignment-04"},
+	} {
+		if err := git(root, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := &context.AppContext{Root: root, Cwd: root, Configuration: config.Minimal()}
+	if _, err := Move(ctx, config.NumberedID(4), config.NumberedID(5), false); err != nil {
+		t.Fatal(err)
+	}
+	message, annotated, err := tagMessage(root, "assignment-05")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !annotated || message != "Release assignment 04\n\nSubmitted on time" {
+		t.Errorf("expected annotated tag with its message, found %q", message)
+	}
+	if _, annotated, _ := tagMessage(root, "assignment-04"); annotated {
+		t.Error("expected tag assignment-04 to be deleted")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	touch(t, filepath.Join(root, "assignment-01", "assignment.tex"), now)
+	touch(t, filepath.Join(root, "assignment-02", "assignment.tex"), now)
+	touch(t, filepath.Join(root, "dist", "assignment-02.pdf"), now)
+
+	cfg := config.Minimal()
+	cfg.Status.Assignment = config.NumberedID(2)
+	cfg.Status.Upsert("02").Mark(config.StateSubmitted, now)
+	ctx := &context.AppContext{Root: root, Cwd: root, Configuration: cfg}
+
+	if _, err := Remove(ctx, config.NumberedID(2), false); err == nil {
+		t.Error("expected submitted assignment to require force")
+	}
+	result, err := Remove(ctx, config.NumberedID(2), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 2 || exists(root, "assignment-02") || exists(root, filepath.Join("dist", "assignment-02.pdf")) {
+		t.Errorf("expected directory and PDF to be removed, found %+v", result.Changes)
+	}
+	if cfg.Status.Lookup("02") != nil {
+		t.Error("expected status to be removed")
+	}
+	if result.Snapshot == nil || len(result.Snapshot.Paths) != 2 || result.Snapshot.Status["02"] == nil {
+		t.Errorf("expected removed files and status to be kept in the trash, found %+v", result.Snapshot)
+	}
+	if cfg.Status.Assignment != config.NumberedID(1) {
+		t.Errorf("expected current assignment 1, found %s", cfg.Status.Assignment)
+	}
+}
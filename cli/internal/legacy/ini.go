@@ -0,0 +1,134 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// INI is a configuration file in the format of Python's configparser, with its
+// sections and keys in the order of the file
+type INI struct {
+	Sections []*Section
+}
+
+// Section is a named section of an INI file
+type Section struct {
+	Name string
+	Keys []*Key
+}
+
+// Key is a single setting of a section. Its name is lowercased, as configparser
+// treats keys case-insensitively
+type Key struct {
+	Name  string
+	Value string
+	// Line is the key's line in the file, for reporting
+	Line int
+}
+
+// Section returns the section with the given name, or nil if there is none
+func (i *INI) Section(name string) *Section {
+	for _, s := range i.Sections {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Get returns the value of the key in the section, and whether it exists
+func (s *Section) Get(name string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	for _, k := range s.Keys {
+		if k.Name == name {
+			return k.Value, true
+		}
+	}
+	return "", false
+}
+
+// ParseINI reads an INI file as written by configparser, i.e., [sections] with
+// "key = value" or "key: value" lines, full-line comments starting with # or ;,
+// and values continued on indented lines. Later occurrences of a key override
+// earlier ones
+func ParseINI(r io.Reader) (*INI, error) {
+	ini := &INI{}
+	var section *Section
+	var last *Key
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(raw)
+		switch {
+		case trimmed == "":
+			last = nil
+			continue
+		case strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";"):
+			continue
+		case last != nil && (raw[0] == ' ' || raw[0] == '\t'):
+			// continuation of the previous value
+			if last.Value == "" {
+				last.Value = trimmed
+			} else {
+				last.Value += "\n" + trimmed
+			}
+			continue
+		}
+		last = nil
+
+		if strings.HasPrefix(trimmed, "[") {
+			if !strings.HasSuffix(trimmed, "]") {
+				return nil, fmt.Errorf("line %d: malformed section header %q", line, trimmed)
+			}
+			name := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			section = ini.Section(name)
+			if section == nil {
+				section = &Section{Name: name}
+				ini.Sections = append(ini.Sections, section)
+			}
+			continue
+		}
+		if section == nil {
+			return nil, fmt.Errorf("line %d: key outside of any section", line)
+		}
+		i := strings.IndexAny(trimmed, "=:")
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: expected \"key = value\", found %q", line, trimmed)
+		}
+		name := strings.ToLower(strings.TrimSpace(trimmed[:i]))
+		for j, k := range section.Keys {
+			if k.Name == name {
+				section.Keys = append(section.Keys[:j], section.Keys[j+1:]...)
+				break
+			}
+		}
+		last = &Key{Name: name, Value: strings.TrimSpace(trimmed[i+1:]), Line: line}
+		section.Keys = append(section.Keys, last)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ini, nil
+}
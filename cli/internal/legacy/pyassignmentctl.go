@@ -0,0 +1,111 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacy
+
+import (
+	"strconv"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
+)
+
+const (
+	// PyassignmentctlFileName is pyassignmentctl's configuration file in the
+	// repository's root
+	PyassignmentctlFileName string = ".assignments.rc"
+	// PyassignmentctlArchiveNameTemplate reproduces pyassignmentctl's archive
+	// names, i.e., the assignment's ID followed by the members' IDs, e.g.,
+	// sheet_03_123456_789012.zip
+	PyassignmentctlArchiveNameTemplate string = `sheet_{{._id}}{{ range $m := ._members }}_{{ $m.ID }}{{ end }}.{{._format}}`
+)
+
+var (
+	// pyassignmentctlDirectories are created in each new assignment by pyassignmentctl
+	pyassignmentctlDirectories = []string{"source", "code"}
+	// pyassignmentctlIncludes are the files bundled next to the PDF by pyassignmentctl
+	pyassignmentctlIncludes = []string{"code/*"}
+)
+
+// Unmapped is a legacy setting without equivalent in .assignments.yaml
+type Unmapped struct {
+	Section string
+	// Key is empty for sections without any keys
+	Key    string
+	Value  string
+	Line   int
+	Reason string
+}
+
+// Migration is a configuration converted from a legacy one
+type Migration struct {
+	Configuration *config.Configuration
+	// Unmapped are the settings that could not be converted
+	Unmapped []Unmapped
+}
+
+// FromPyassignmentctl converts pyassignmentctl's configuration, i.e., the course,
+// group, members, and current assignment in .assignments.rc. Its build command
+// equals the default build recipe, so there is nothing to convert. Its archive
+// names, the directories it creates for new assignments, and the files it
+// bundles were fixed, and are configured explicitly
+func FromPyassignmentctl(ini *INI) *Migration {
+	cfg := config.Minimal()
+	cfg.Spec.GenerateOptions = &config.GenerateOptions{
+		Create: append([]string{}, pyassignmentctlDirectories...),
+	}
+	cfg.Spec.BundleOptions = &config.BundleOptions{
+		Template: PyassignmentctlArchiveNameTemplate,
+		Include:  append([]string{}, pyassignmentctlIncludes...),
+	}
+	m := &Migration{Configuration: cfg}
+	unmapped := func(s *Section, k *Key, reason string) {
+		m.Unmapped = append(m.Unmapped, Unmapped{Section: s.Name, Key: k.Name, Value: k.Value, Line: k.Line, Reason: reason})
+	}
+
+	for _, s := range ini.Sections {
+		if len(s.Keys) == 0 && s.Name != "members" {
+			m.Unmapped = append(m.Unmapped, Unmapped{Section: s.Name, Reason: "empty section"})
+		}
+		for _, k := range s.Keys {
+			switch {
+			case s.Name == "general" && k.Name == "course":
+				cfg.Spec.Course = k.Value
+			case s.Name == "general" && k.Name == "group":
+				// pyassignmentctl wrote Python's None for groups left out
+				if k.Value != "None" {
+					cfg.Spec.Group = k.Value
+				}
+			case s.Name == "members":
+				// members are keyed by their student ID
+				cfg.Spec.Members = append(cfg.Spec.Members, config.GroupMember{Name: k.Value, ID: k.Name})
+			case s.Name == "assignments" && k.Name == "number":
+				n, err := strconv.ParseUint(k.Value, 10, 32)
+				if err != nil {
+					unmapped(s, k, "not an assignment number")
+					continue
+				}
+				if n > 0 {
+					cfg.Status.Assignment = config.NumberedID(uint32(n))
+				}
+			case s.Name == "general" || s.Name == "assignments":
+				unmapped(s, k, "unknown setting")
+			default:
+				unmapped(s, k, "unknown section")
+			}
+		}
+	}
+	return m
+}
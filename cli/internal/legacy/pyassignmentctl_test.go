@@ -0,0 +1,75 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package legacy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
+)
+
+func TestFromPyassignmentctl(t *testing.T) {
+	ini, err := ParseINI(strings.NewReader(`[general]
+course = Algorithmen und Datenstrukturen
+group = None
+# written by hand
+Semester: WS21
+
+[members]
+123456 = Max Mustermann
+789012 = Erika
+	Mustermann
+
+[assignments]
+number = 4
+
+[latex]
+engine = xelatex
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := FromPyassignmentctl(ini)
+	spec := m.Configuration.Spec
+	if spec.Course != "Algorithmen und Datenstrukturen" || spec.Group != "" {
+		t.Errorf("expected course without group, found %q and %q", spec.Course, spec.Group)
+	}
+	members := []config.GroupMember{{Name: "Max Mustermann", ID: "123456"}, {Name: "Erika\nMustermann", ID: "789012"}}
+	if !reflect.DeepEqual(spec.Members, members) {
+		t.Errorf("expected members %v, found %v", members, spec.Members)
+	}
+	if m.Configuration.Status.Assignment != config.NumberedID(4) {
+		t.Errorf("expected current assignment 4, found %s", m.Configuration.Status.Assignment)
+	}
+	if spec.BundleOptions.Template != PyassignmentctlArchiveNameTemplate {
+		t.Errorf("expected pyassignmentctl's archive names, found %s", spec.BundleOptions.Template)
+	}
+
+	unmapped := []Unmapped{
+		{Section: "general", Key: "semester", Value: "WS21", Line: 5, Reason: "unknown setting"},
+		{Section: "latex", Key: "engine", Value: "xelatex", Line: 16, Reason: "unknown section"},
+	}
+	if !reflect.DeepEqual(m.Unmapped, unmapped) {
+		t.Errorf("expected unmapped settings %v, found %v", unmapped, m.Unmapped)
+	}
+
+	if _, err := ParseINI(strings.NewReader("course = outside\n")); err == nil {
+		t.Error("expected error for keys outside of sections")
+	}
+}
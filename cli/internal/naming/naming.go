@@ -0,0 +1,237 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package naming
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
+)
+
+const (
+	// DefaultTemplate is the name of assignment directories, artifacts and tags
+	// if .spec.naming.template is not set
+	DefaultTemplate string = "assignment-{{.ID}}"
+	// DefaultWidth is the number of digits assignment numbers are padded to if
+	// .spec.naming.width is not set
+	DefaultWidth int = 2
+
+	// TemplateName is the name of the naming template, as shown in errors. It
+	// matches the template's location in the configuration
+	TemplateName string = "spec.naming.template"
+
+	// placeholder is substituted for the ID when rendering the template to derive
+	// the parts of the name around the ID
+	placeholder string = "\x00"
+)
+
+// ErrInvalidName is returned when parsing names that do not match the naming scheme
+var ErrInvalidName error = errors.New("name does not match the naming scheme")
+
+// Scheme constructs and parses the names of assignments, i.e., the names of
+// their directories, of the PDFs in ./dist/ and of their release tags
+type Scheme struct {
+	prefix  string
+	suffix  string
+	width   int
+	pattern *regexp.Regexp
+}
+
+// Default returns the default naming scheme, i.e., assignment-01, assignment-02, ...
+func Default() *Scheme {
+	s, _ := New(nil)
+	return s
+}
+
+// New creates a naming scheme from the configuration, applying the defaults
+// for missing fields. Returns an error if the template does not contain the
+// ID exactly once or would produce names of nested paths
+func New(opts *config.NamingOptions) (*Scheme, error) {
+	tpl := DefaultTemplate
+	width := DefaultWidth
+	if opts != nil {
+		if opts.Template != "" {
+			tpl = opts.Template
+		}
+		if opts.Width != 0 {
+			width = opts.Width
+		}
+	}
+	if width < 1 {
+		return nil, fmt.Errorf("invalid width %d, must be at least 1", width)
+	}
+
+	tmpl, err := template.New(TemplateName).Option("missingkey=error").Parse(tpl)
+	if err != nil {
+		return nil, err
+	}
+	var output bytes.Buffer
+	if err := tmpl.Execute(&output, map[string]string{"ID": placeholder}); err != nil {
+		return nil, err
+	}
+	parts := strings.Split(output.String(), placeholder)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("template %q must contain {{.ID}} exactly once", tpl)
+	}
+	if strings.ContainsAny(output.String(), `/\`) {
+		return nil, fmt.Errorf("template %q must not contain path separators", tpl)
+	}
+
+	s := &Scheme{
+		prefix: parts[0],
+		suffix: parts[1],
+		width:  width,
+	}
+	s.pattern = regexp.MustCompile(s.Pattern())
+	return s, nil
+}
+
+// ID returns the assignment's ID with its number padded with leading zeros to
+// the scheme's width, e.g., "03" or "05a". IDs key the assignments in
+// .status.assignments
+func (s *Scheme) ID(id config.AssignmentID) string {
+	return id.Pad(s.width)
+}
+
+// Name returns the name of the assignment with the given ID, which is the name
+// of its directory and its release tag, e.g., "assignment-03"
+func (s *Scheme) Name(id string) string {
+	return s.prefix + id + s.suffix
+}
+
+// PDF returns the filename of the assignment's PDF in ./dist/, e.g., "assignment-03.pdf"
+func (s *Scheme) PDF(id string) string {
+	return s.Name(id) + ".pdf"
+}
+
+// Directory returns the name of the directory of the assignment
+func (s *Scheme) Directory(id config.AssignmentID) string {
+	return s.Name(s.ID(id))
+}
+
+// Parse returns the ID of the assignment from its name, the name of its PDF, or
+// a path to either
+func (s *Scheme) Parse(name string) (config.AssignmentID, error) {
+	base := strings.TrimSuffix(filepath.Base(name), ".pdf")
+	m := s.pattern.FindStringSubmatch(base)
+	if m == nil {
+		return config.AssignmentID{}, fmt.Errorf("%w: %s does not match %s", ErrInvalidName, base, s.pattern)
+	}
+	return config.ParseAssignmentID(m[1])
+}
+
+// ParseID is like Parse, but returns the assignment's padded ID
+func (s *Scheme) ParseID(name string) (string, error) {
+	id, err := s.Parse(name)
+	if err != nil {
+		return "", err
+	}
+	return s.ID(id), nil
+}
+
+// Argument parses a command's argument naming an assignment, which is either its
+// name, e.g., "assignment-03/", or its ID, e.g., "3", "03", "5a", or "bonus"
+func (s *Scheme) Argument(arg string) (config.AssignmentID, error) {
+	arg = strings.TrimSuffix(arg, string(filepath.Separator))
+	if id, err := s.Parse(arg); err == nil {
+		return id, nil
+	}
+	return config.ParseAssignmentID(arg)
+}
+
+// Match returns true if the name is an assignment's name, the name of its PDF,
+// or a path to either
+func (s *Scheme) Match(name string) bool {
+	_, err := s.Parse(name)
+	return err == nil
+}
+
+// Glob returns a glob pattern matching all assignment names, e.g., "assignment-*".
+// It may match other names as well, so filter matches with Match
+func (s *Scheme) Glob() string {
+	return globEscape(s.prefix) + "*" + globEscape(s.suffix)
+}
+
+// Pattern returns a regular expression matching assignment names, capturing
+// the assignment's ID, e.g., "^assignment-([0-9][0-9]+[a-z]?|[A-Za-z][A-Za-z0-9_-]*)$"
+func (s *Scheme) Pattern() string {
+	return "^" + regexp.QuoteMeta(s.prefix) + "(" + s.ids() + ")" + regexp.QuoteMeta(s.suffix) + "$"
+}
+
+// TagPattern returns a regular expression matching the release tags of
+// assignments, e.g., "^assignment-(?:[0-9][0-9]+[a-z]?|[A-Za-z][A-Za-z0-9_-]*)$"
+func (s *Scheme) TagPattern() string {
+	return "^" + regexp.QuoteMeta(s.prefix) + "(?:" + s.ids() + ")" + regexp.QuoteMeta(s.suffix) + "$"
+}
+
+// TagFilters returns GitHub Actions filter patterns matching the release tags
+// of assignments, e.g., "assignment-[0-9][0-9]+[a-z]?" and "assignment-[A-Za-z]*".
+// As filter patterns are less expressive than regular expressions, the latter
+// also matches tags that are not named assignments
+func (s *Scheme) TagFilters() []string {
+	filters := []string{filterEscape(s.prefix) + s.digits() + "[a-z]?" + filterEscape(s.suffix)}
+	if s.named() {
+		filters = append(filters, filterEscape(s.prefix)+"[A-Za-z]*"+filterEscape(s.suffix))
+	}
+	return filters
+}
+
+// ids matches numbered IDs of at least the scheme's width, and named IDs
+func (s *Scheme) ids() string {
+	if !s.named() {
+		return s.digits() + config.IDSuffixPattern
+	}
+	return s.digits() + config.IDSuffixPattern + "|" + config.NamedIDPattern
+}
+
+// digits matches numbers of at least the scheme's width
+func (s *Scheme) digits() string {
+	return strings.Repeat("[0-9]", s.width-1) + "[0-9]+"
+}
+
+// named returns true if the scheme supports named IDs. Without any text around
+// the ID, any name would be an assignment's name, e.g., dist
+func (s *Scheme) named() bool {
+	return s.prefix != "" || s.suffix != ""
+}
+
+// globEscape escapes the special characters of filepath.Match
+func globEscape(s string) string {
+	return escape(s, `*?[]\`)
+}
+
+// filterEscape escapes the special characters of GitHub Actions filter patterns
+func filterEscape(s string) string {
+	return escape(s, `*?+![]\`)
+}
+
+func escape(s string, special string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
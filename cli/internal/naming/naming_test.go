@@ -0,0 +1,175 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package naming
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
+)
+
+func TestDefault(t *testing.T) {
+	s := Default()
+	if id := s.ID(config.NumberedID(3)); id != "03" {
+		t.Errorf("expected ID 03, found %s", id)
+	}
+	if id := s.ID(config.AssignmentID{Number: 12, Suffix: "b"}); id != "12b" {
+		t.Errorf("expected ID 12b, found %s", id)
+	}
+	if name := s.Directory(config.NumberedID(3)); name != "assignment-03" {
+		t.Errorf("expected assignment-03, found %s", name)
+	}
+	if pdf := s.PDF("03"); pdf != "assignment-03.pdf" {
+		t.Errorf("expected assignment-03.pdf, found %s", pdf)
+	}
+	if p := s.Pattern(); p != "^assignment-([0-9][0-9]+[a-z]?|[A-Za-z][A-Za-z0-9_-]*)$" {
+		t.Errorf("expected default pattern, found %s", p)
+	}
+	if f := s.TagFilters(); len(f) != 2 || f[0] != "assignment-[0-9][0-9]+[a-z]?" || f[1] != "assignment-[A-Za-z]*" {
+		t.Errorf("expected default tag filters, found %v", f)
+	}
+	if g := s.Glob(); g != "assignment-*" {
+		t.Errorf("expected default glob, found %s", g)
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		opts    *config.NamingOptions
+		id      config.AssignmentID
+		name    string
+		filter  string
+		nomatch []string
+	}{
+		{
+			opts:    &config.NamingOptions{Template: "sheet-{{.ID}}"},
+			id:      config.NumberedID(3),
+			name:    "sheet-03",
+			filter:  "sheet-[0-9][0-9]+[a-z]?",
+			nomatch: []string{"assignment-03", "sheet-3", "sheet-03ab", "sheet-3a"},
+		},
+		{
+			opts:    &config.NamingOptions{Template: "sheet-{{.ID}}"},
+			id:      config.AssignmentID{Number: 5, Suffix: "a"},
+			name:    "sheet-05a",
+			filter:  "sheet-[0-9][0-9]+[a-z]?",
+			nomatch: []string{"sheet-05A", "sheet-"},
+		},
+		{
+			opts:    &config.NamingOptions{Template: "sheet-{{.ID}}"},
+			id:      config.AssignmentID{Name: "exam-prep"},
+			name:    "sheet-exam-prep",
+			filter:  "sheet-[0-9][0-9]+[a-z]?",
+			nomatch: []string{"sheet--prep", "exam-prep"},
+		},
+		{
+			opts:    &config.NamingOptions{Template: "uebung_{{.ID}}", Width: 1},
+			id:      config.NumberedID(3),
+			name:    "uebung_3",
+			filter:  "uebung_[0-9]+[a-z]?",
+			nomatch: []string{"uebung_", "uebung-3"},
+		},
+		{
+			opts:    &config.NamingOptions{Template: "lab{{.ID}}", Width: 3},
+			id:      config.NumberedID(7),
+			name:    "lab007",
+			filter:  "lab[0-9][0-9][0-9]+[a-z]?",
+			nomatch: []string{"lab07", "lab"},
+		},
+		{
+			opts:    &config.NamingOptions{Template: "[{{.ID}}]+x"},
+			id:      config.NumberedID(12),
+			name:    "[12]+x",
+			filter:  `\[[0-9][0-9]+[a-z]?\]\+x`,
+			nomatch: []string{"112]+x"},
+		},
+		{
+			opts:    &config.NamingOptions{Template: "{{.ID}}"},
+			id:      config.NumberedID(4),
+			name:    "04",
+			filter:  "[0-9][0-9]+[a-z]?",
+			nomatch: []string{"dist", "code"},
+		},
+	}
+	for _, c := range cases {
+		s, err := New(c.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := s.Directory(c.id)
+		if name != c.name {
+			t.Errorf("expected name %s, found %s", c.name, name)
+		}
+		id, err := s.Parse(name)
+		if err != nil || id != c.id {
+			t.Errorf("expected %s to parse to %s, found %s, %v", name, c.id, id, err)
+		}
+		id, err = s.Parse("dist/" + s.PDF(s.ID(c.id)))
+		if err != nil || id != c.id {
+			t.Errorf("expected PDF of %s to parse to %s, found %s, %v", name, c.id, id, err)
+		}
+		if f := s.TagFilters(); f[0] != c.filter {
+			t.Errorf("expected tag filter %s, found %s", c.filter, f[0])
+		}
+		if !regexp.MustCompile(s.TagPattern()).MatchString(name) {
+			t.Errorf("expected tag pattern %s to match %s", s.TagPattern(), name)
+		}
+		for _, other := range c.nomatch {
+			if s.Match(other) {
+				t.Errorf("expected %s not to match %s", other, s.Pattern())
+			}
+		}
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, opts := range []*config.NamingOptions{
+		{Template: "sheet"},
+		{Template: "{{.ID}}-{{.ID}}"},
+		{Template: "sheets/{{.ID}}"},
+		{Template: "sheet-{{.Number}}"},
+		{Template: "sheet-{{.ID}"},
+		{Width: -1},
+	} {
+		if _, err := New(opts); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+}
+
+func TestArgument(t *testing.T) {
+	s := Default()
+	for arg, expected := range map[string]config.AssignmentID{
+		"3":                config.NumberedID(3),
+		"03":               config.NumberedID(3),
+		"05a":              {Number: 5, Suffix: "a"},
+		"bonus":            {Name: "bonus"},
+		"assignment-03":    config.NumberedID(3),
+		"assignment-12/":   config.NumberedID(12),
+		"assignment-05b":   {Number: 5, Suffix: "b"},
+		"assignment-bonus": {Name: "bonus"},
+	} {
+		id, err := s.Argument(arg)
+		if err != nil || id != expected {
+			t.Errorf("expected %s to be assignment %s, found %s, %v", arg, expected, id, err)
+		}
+	}
+	if _, err := s.Argument("sheet 03"); err == nil {
+		t.Error("expected error for invalid argument")
+	}
+}
@@ -28,15 +28,25 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/zoomoid/assignments/v1/internal/config"
-	"github.com/zoomoid/assignments/v1/internal/util"
+	"github.com/zoomoid/assignments/v1/internal/tex"
 )
 
 type builder struct {
 	*RunnerContext
+	// artifact is the path of the exported PDF after a successful run
+	artifact string
 }
 
 type Builder interface {
 	Runner
+	// Artifact returns the path of the exported PDF after a successful run
+	Artifact() string
+}
+
+// Artifact returns the path of the exported PDF after a successful run, and the
+// empty string otherwise
+func (b *builder) Artifact() string {
+	return b.artifact
 }
 
 // MakeCommand implements the Runner spec in terms of transforming a given recipe into a
@@ -54,8 +64,25 @@ func (b *builder) MakeCommand() ([]*exec.Cmd, error) {
 		}
 	}
 
-	cmds, err := commandsFromRecipe(recipe, b.TargetDirectory(), b.Filename(), b.Quiet())
-	return cmds, err
+	cmds, err := commandsFromRecipe(recipe, b.TargetDirectory(), b.Filename(), b.Quiet(), b.Output())
+	if err != nil {
+		return nil, err
+	}
+	if tex.LocallyInstalled(b.root) {
+		// sources refer to the class by its name, TeX finds the one installed into
+		// the repository's root on TEXINPUTS
+		env := append(os.Environ(), texInputs(b.root))
+		for _, cmd := range cmds {
+			cmd.Env = env
+		}
+	}
+	return cmds, nil
+}
+
+// texInputs returns the TEXINPUTS variable with dir prepended to its current
+// value. If TEXINPUTS is unset, the trailing separator keeps TeX's default paths
+func texInputs(dir string) string {
+	return "TEXINPUTS=" + dir + string(os.PathListSeparator) + os.Getenv("TEXINPUTS")
 }
 
 // Run implements the Runner specification for running a set of commands in terms of building
@@ -87,6 +114,7 @@ func (b *builder) Run() error {
 		return err
 	}
 	log.Debug().Msgf("[runner/export] Finished exporting from %s to %s in %v", filepath.Join(b.targetDirectory, b.filename), dest, time.Since(exportTime))
+	b.artifact = dest
 	return nil
 }
 
@@ -112,7 +140,7 @@ func (b *builder) exportArtifacts() (string, error) {
 	artifactsPdf := strings.Replace(b.Filename(), ".tex", ".pdf", 1)
 
 	srcPath := filepath.Join(b.TargetDirectory(), artifactsPdf)
-	destPath := filepath.Join(d, fmt.Sprintf("assignment-%s.pdf", ai))
+	destPath := filepath.Join(d, b.Naming().PDF(ai))
 
 	if _, err := os.Stat(destPath); !b.overrideArtifacts && err == nil {
 		// file exists and the user did not specify --force flag,
@@ -162,9 +190,8 @@ func (b *builder) makeArtifactsDirectory() error {
 	return nil
 }
 
-// assignmentNumber returns the assignment's number as a leading-zero string.
-// Returns error when util.AssignmentNumberFromFilename returns an error
+// assignmentNumber returns the assignment's ID from the target directory's name.
+// Returns error when the directory does not match the naming scheme
 func (b *builder) assignmentNumber() (string, error) {
-	s := filepath.Base(b.TargetDirectory())
-	return util.AssignmentNumberFromRegex(util.AssignmentDirectoryPattern, s)
+	return b.Naming().ParseID(b.TargetDirectory())
 }
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/tex"
 )
 
 func TestBuildRunner(t *testing.T) {
@@ -249,3 +250,51 @@ func TestBuildRunner(t *testing.T) {
 		}
 	})
 }
+
+func TestBuildRunnerTexInputs(t *testing.T) {
+	t.Setenv("TEXINPUTS", "")
+	workingDirectory := t.TempDir()
+
+	targetDirectory, err := makeSourceFile(workingDirectory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := makeAppContext(workingDirectory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testRunner, err := New(ctx, &RunnerOptions{
+		TargetDirectory: targetDirectory,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := testRunner.Clone().Build()
+
+	cmds, err := r.MakeCommand()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range cmds {
+		if cmd.Env != nil {
+			t.Errorf("expected the environment to be inherited without a local class, found %v", cmd.Env)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(workingDirectory, tex.ClassFile), []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmds, err = r.MakeCommand()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cmds) == 0 {
+		t.Fatal("expected build commands")
+	}
+	expected := "TEXINPUTS=" + workingDirectory + string(os.PathListSeparator)
+	for _, cmd := range cmds {
+		if len(cmd.Env) == 0 || cmd.Env[len(cmd.Env)-1] != expected {
+			t.Errorf("expected %s in the environment, found %v", expected, cmd.Env)
+		}
+	}
+}
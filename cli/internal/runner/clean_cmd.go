@@ -53,7 +53,7 @@ func (c *cmdCleaner) MakeCommand() ([]*exec.Cmd, error) {
 		}
 	}
 
-	cmds, err := commandsFromRecipe(recipe, c.TargetDirectory(), c.Filename(), c.Quiet())
+	cmds, err := commandsFromRecipe(recipe, c.TargetDirectory(), c.Filename(), c.Quiet(), c.Output())
 	return cmds, err
 }
 
@@ -17,10 +17,13 @@ limitations under the License.
 package runner
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/rs/zerolog/log"
+	"github.com/zoomoid/assignments/v1/internal/trash"
 	"github.com/zoomoid/assignments/v1/internal/util"
 )
 
@@ -29,6 +32,9 @@ type globCleaner struct {
 }
 
 var (
+	// DefaultPatterns match the byproducts of compiling an assignment with LaTeX.
+	// They are cleaned up if no patterns are configured and ignored when determining
+	// whether an assignment's sources are newer than its PDF
 	DefaultPatterns = []string{
 		"*.aux",
 		"*.bbl",
@@ -49,10 +55,12 @@ var (
 		"*.log",
 		"*.fdb_latexmk",
 		"*.snm",
+		"*.synctex.gz",
 		"*.synctex(busy)",
 		"*.synctex.gz(busy)",
 		"*.nav",
 		"*.vrb",
+		"*.xdv",
 	}
 )
 
@@ -80,11 +88,58 @@ func (c *globCleaner) Run() error {
 		return elist
 	}
 
+	paths := []string{}
 	for _, v := range visitors {
 		v.Visit(func(path string) error {
-			return os.Remove(path)
+			paths = append(paths, path)
+			return nil
 		})
 	}
+	if err := c.keep(paths); err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		os.Remove(path)
+	}
 	log.Debug().Msgf("[runner/clean] Finished cleaning up %s with glob patterns", c.TargetDirectory())
 	return nil
 }
+
+// keep saves all files to be deleted that are not byproducts of LaTeX, i.e., not
+// matched by DefaultPatterns, to a snapshot in the trash. Custom patterns may match
+// more than intended, byproducts can be recreated by building again
+func (c *globCleaner) keep(paths []string) error {
+	kept := []string{}
+	for _, path := range paths {
+		if !IsByproduct(filepath.Base(path)) {
+			kept = append(kept, path)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	store, err := trash.Open(c.root, c.configuration.Spec.TrashOptions)
+	if err != nil {
+		return err
+	}
+	id, _ := c.Naming().ParseID(c.TargetDirectory())
+	snapshot, err := store.Save(&trash.Snapshot{Operation: "clean", Assignment: id}, kept...)
+	if err != nil {
+		return fmt.Errorf("failed to keep files before cleaning up, %w", err)
+	}
+	if snapshot != nil {
+		log.Info().Msgf("Kept %d file(s) matched by cleanup patterns in snapshot %s, restore with \"assignmentctl undo\"", len(snapshot.Paths), snapshot.Name)
+	}
+	return nil
+}
+
+// IsByproduct returns true for file names matched by DefaultPatterns
+func IsByproduct(name string) bool {
+	for _, pattern := range DefaultPatterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -20,6 +20,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -53,7 +54,7 @@ type substitutionContext struct {
 	OUTDIR string
 }
 
-func commandsFromRecipe(recipe *config.Recipe, cwd string, file string, quiet bool) ([]*exec.Cmd, error) {
+func commandsFromRecipe(recipe *config.Recipe, cwd string, file string, quiet bool, output io.Writer) ([]*exec.Cmd, error) {
 	cmds := []*exec.Cmd{}
 
 	ctx := makeSubstitutionContext(cwd, file)
@@ -77,7 +78,10 @@ func commandsFromRecipe(recipe *config.Recipe, cwd string, file string, quiet bo
 
 		out := &bytes.Buffer{}
 
-		if quiet {
+		if output != nil {
+			cmd.Stdout = output
+			cmd.Stderr = output
+		} else if quiet {
 			sink := bufio.NewWriter(out)
 			cmd.Stdout = sink
 		} else {
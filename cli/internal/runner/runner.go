@@ -19,6 +19,7 @@ package runner
 import (
 	"bufio"
 	"bytes"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -26,6 +27,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/zoomoid/assignments/v1/internal/config"
 	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/naming"
 )
 
 // RunnerOptions struct to carry configuration for the latexmk runs
@@ -38,6 +40,9 @@ type RunnerOptions struct {
 	Quiet bool
 	// OverrideArtifacts makes the builder override any existing artifacts
 	OverrideArtifacts bool
+	// Output receives both stdout and stderr of all commands if set, e.g., to capture
+	// the build log. Takes precedence over Quiet
+	Output io.Writer
 }
 
 type RunnerContext struct {
@@ -47,10 +52,12 @@ type RunnerContext struct {
 	options            *RunnerOptions
 	filename           string
 	quiet              bool
+	output             io.Writer
 	overrideArtifacts  bool
 	targetDirectory    string
 	artifactsDirectory string
 	continueOnError    bool
+	naming             *naming.Scheme
 	Commands           []*exec.Cmd
 }
 
@@ -75,15 +82,26 @@ var (
 
 // New creates a new runner context from the given parameters and applies sensible defaults
 func New(context *context.AppContext, options *RunnerOptions) (*RunnerContext, error) {
+	target := options.TargetDirectory
+	if target == "" {
+		target = context.Cwd
+	}
+	// apply the assignment's own configuration file, e.g., a different recipe
+	assignmentCtx, err := context.ForAssignment(target)
+	if err != nil {
+		return nil, err
+	}
 	// clone the app context for the runner context to not mutate the application context's
 	// state with setters on the runner
-	runnerCtx := context.Clone()
+	runnerCtx := assignmentCtx.Clone()
 	runner := &RunnerContext{
 		options:       options,
 		root:          runnerCtx.Root,
 		cwd:           runnerCtx.Cwd,
 		quiet:         options.Quiet,
+		output:        options.Output,
 		configuration: runnerCtx.Configuration,
+		naming:        runnerCtx.Naming(),
 	}
 
 	if options.TargetDirectory == "" {
@@ -128,7 +146,10 @@ func (b *RunnerContext) Clone() *RunnerContext {
 		destCmd.Dir = srcCmd.Dir
 		out := &bytes.Buffer{}
 
-		if b.quiet {
+		if b.output != nil {
+			destCmd.Stdout = b.output
+			destCmd.Stderr = b.output
+		} else if b.quiet {
 			sink := bufio.NewWriter(out)
 			destCmd.Stdout = sink
 		} else {
@@ -141,11 +162,13 @@ func (b *RunnerContext) Clone() *RunnerContext {
 		filename:           b.filename,
 		artifactsDirectory: b.artifactsDirectory,
 		quiet:              b.quiet,
+		output:             b.output,
 		overrideArtifacts:  b.overrideArtifacts,
 		Commands:           cmds,
 		cwd:                b.cwd,
 		root:               b.root,
 		configuration:      b.configuration.Clone(),
+		naming:             b.naming,
 	}
 }
 
@@ -200,6 +223,14 @@ func (r *RunnerContext) TargetDirectory() string {
 	}
 }
 
+// Naming returns the naming scheme of the repository's assignments
+func (r *RunnerContext) Naming() *naming.Scheme {
+	if r.naming == nil {
+		return naming.Default()
+	}
+	return r.naming
+}
+
 func (r *RunnerContext) Filename() string {
 	return r.filename
 }
@@ -208,6 +239,12 @@ func (r *RunnerContext) Quiet() bool {
 	return r.quiet
 }
 
+// Output returns the writer receiving the output of all commands, or nil if the
+// output is not captured
+func (r *RunnerContext) Output() io.Writer {
+	return r.output
+}
+
 func (r *RunnerContext) OverrideArtifacts() bool {
 	return r.overrideArtifacts
 }
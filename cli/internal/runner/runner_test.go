@@ -26,7 +26,7 @@ import (
 	"github.com/lithammer/dedent"
 	"github.com/zoomoid/assignments/v1/internal/config"
 	"github.com/zoomoid/assignments/v1/internal/context"
-	"github.com/zoomoid/assignments/v1/internal/util"
+	"github.com/zoomoid/assignments/v1/internal/naming"
 )
 
 var (
@@ -94,7 +94,7 @@ var (
 			},
 		},
 		Status: &config.ConfigurationStatus{
-			Assignment: 7,
+			Assignment: config.NumberedID(7),
 		},
 	}
 )
@@ -108,7 +108,7 @@ func makeAppContext(root string) (*context.AppContext, error) {
 }
 
 func makeSourceFile(root string) (string, error) {
-	dirName := fmt.Sprintf("assignment-%s", util.AddLeadingZero(cfg.Status.Assignment))
+	dirName := naming.Default().Directory(cfg.Status.Assignment)
 	err := os.Mkdir(filepath.Join(root, dirName), 0777)
 	if err != nil {
 		return "", err
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// errorPattern matches the location prefix of errors returned by text/template, e.g.
+	// `template: spec.template:2:5: executing "spec.template" at <.Foo>: ...`
+	errorPattern = regexp.MustCompile(`(?s)^template: (.+?):([0-9]+)(?::([0-9]+))?: (.*)$`)
+	// executingPattern matches the redundant part of execution errors
+	executingPattern = regexp.MustCompile(`^executing "[^"]*" `)
+)
+
+// Error is an error from parsing or executing a template, enriched with the
+// position in the template's source. Column is 0 if text/template does not
+// report one, which is the case for most parsing errors.
+type Error struct {
+	// Template is the name of the template the error occurred in, e.g., "spec.template"
+	Template string
+	// Line is the 1-based line of the error
+	Line int
+	// Column is the 1-based column of the error, or 0 if unknown
+	Column int
+	// Message is the error message without the location prefix
+	Message string
+	// Context is the line of source the error occurred in, if known
+	Context string
+
+	err error
+}
+
+// NewError wraps an error returned by text/template for the template name with
+// source into an Error. Returns nil if err is nil, and wraps err without
+// position if its message does not carry a location.
+func NewError(name string, source string, err error) error {
+	if err == nil {
+		return nil
+	}
+	m := errorPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return &Error{
+			Template: name,
+			Message:  err.Error(),
+			err:      err,
+		}
+	}
+
+	e := &Error{
+		Template: m[1],
+		Message:  executingPattern.ReplaceAllString(m[4], ""),
+		err:      err,
+	}
+	e.Line, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		// text/template reports the column as byte offset into the line
+		offset, _ := strconv.Atoi(m[3])
+		e.Column = offset + 1
+	}
+	if e.Template == name {
+		lines := strings.Split(source, "\n")
+		if e.Line > 0 && e.Line <= len(lines) {
+			e.Context = lines[e.Line-1]
+		}
+	}
+	return e
+}
+
+// Error implements the error interface. If the source line is known, it is
+// appended to the message together with a marker at the error's column
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Template)
+	if e.Line > 0 {
+		fmt.Fprintf(&b, ":%d", e.Line)
+	}
+	if e.Column > 0 {
+		fmt.Fprintf(&b, ":%d", e.Column)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Message)
+	if e.Context != "" {
+		prefix := fmt.Sprintf("%5d | ", e.Line)
+		fmt.Fprintf(&b, "\n%s%s", prefix, e.Context)
+		if e.Column > 0 && e.Column <= len(e.Context)+1 {
+			fmt.Fprintf(&b, "\n%s|%s^", strings.Repeat(" ", len(prefix)-2), strings.Repeat(" ", e.Column))
+		}
+	}
+	return b.String()
+}
+
+// Unwrap returns the original error from text/template
+func (e *Error) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,339 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultLocale is used for formatting if the configuration does not set a locale
+const DefaultLocale string = "en"
+
+// Locale contains everything needed to format dates and numbers in a language
+type Locale struct {
+	// Months are the full names of the months, starting with January
+	Months [12]string
+	// ShortMonths are the abbreviated names of the months
+	ShortMonths [12]string
+	// Weekdays are the full names of the days of the week, starting with Sunday
+	Weekdays [7]string
+	// ShortWeekdays are the abbreviated names of the days of the week
+	ShortWeekdays [7]string
+	// DateLayout is the layout used by formatDue, in the syntax of time.Format
+	DateLayout string
+	// Ordinal formats a number as ordinal, e.g., 1st or 1.
+	Ordinal func(n int) string
+}
+
+var (
+	// Locales contains all supported locales, keyed by ISO 639-1 language code
+	Locales = map[string]*Locale{
+		"en": {
+			Months:        [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+			ShortMonths:   [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+			Weekdays:      [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+			ShortWeekdays: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+			DateLayout:    "January 2, 2006",
+			Ordinal: func(n int) string {
+				suffix := "th"
+				switch {
+				case n%100 >= 11 && n%100 <= 13:
+				case n%10 == 1:
+					suffix = "st"
+				case n%10 == 2:
+					suffix = "nd"
+				case n%10 == 3:
+					suffix = "rd"
+				}
+				return fmt.Sprintf("%d%s", n, suffix)
+			},
+		},
+		"de": {
+			Months:        [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+			ShortMonths:   [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+			Weekdays:      [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+			ShortWeekdays: [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+			DateLayout:    "2. January 2006",
+			Ordinal: func(n int) string {
+				return fmt.Sprintf("%d.", n)
+			},
+		},
+		"fr": {
+			Months:        [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+			ShortMonths:   [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+			Weekdays:      [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+			ShortWeekdays: [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+			DateLayout:    "2 January 2006",
+			Ordinal: func(n int) string {
+				if n == 1 {
+					return "1er"
+				}
+				return fmt.Sprintf("%de", n)
+			},
+		},
+		"nl": {
+			Months:        [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+			ShortMonths:   [12]string{"jan.", "feb.", "mrt.", "apr.", "mei", "jun.", "jul.", "aug.", "sep.", "okt.", "nov.", "dec."},
+			Weekdays:      [7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+			ShortWeekdays: [7]string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+			DateLayout:    "2 January 2006",
+			Ordinal: func(n int) string {
+				return fmt.Sprintf("%de", n)
+			},
+		},
+	}
+
+	// dueLayouts are the layouts tried in order when formatting due dates given as strings
+	dueLayouts = []string{
+		time.RFC3339,
+		"2006-01-02 15:04",
+		"2006-01-02",
+		"January 2, 2006",
+		"January 2 2006",
+		"Jan 2, 2006",
+		"2 January 2006",
+		"02.01.2006",
+		"2.1.2006",
+	}
+
+	// particles are lowercase name prefixes that belong to a member's last name,
+	// such as in "Ludwig van Beethoven" or "Ursula von der Leyen"
+	particles = map[string]bool{
+		"al": true, "bin": true, "da": true, "das": true, "de": true, "del": true,
+		"della": true, "den": true, "der": true, "di": true, "do": true, "dos": true,
+		"du": true, "ibn": true, "la": true, "le": true, "st.": true, "te": true,
+		"ten": true, "ter": true, "van": true, "von": true, "y": true, "zu": true,
+	}
+
+	// texReplacer escapes characters with special meaning in TeX
+	texReplacer = strings.NewReplacer(
+		`\`, `\textbackslash{}`,
+		`&`, `\&`,
+		`%`, `\%`,
+		`$`, `\$`,
+		`#`, `\#`,
+		`_`, `\_`,
+		`{`, `\{`,
+		`}`, `\}`,
+		`~`, `\textasciitilde{}`,
+		`^`, `\textasciicircum{}`,
+	)
+)
+
+// LookupLocale returns the locale for a language tag such as "de", "de-DE", or
+// "de_DE.UTF-8", falling back to DefaultLocale for unknown or empty tags
+func LookupLocale(tag string) *Locale {
+	lang := strings.ToLower(tag)
+	if i := strings.IndexAny(lang, "-_."); i >= 0 {
+		lang = lang[:i]
+	}
+	if l, ok := Locales[lang]; ok {
+		return l
+	}
+	return Locales[DefaultLocale]
+}
+
+// FuncMap returns the functions available to sheet templates in addition to
+// sprig's functions, formatting with the given locale
+func FuncMap(locale string) template.FuncMap {
+	l := LookupLocale(locale)
+	return template.FuncMap{
+		"formatDue": func(due interface{}) string {
+			return l.formatDue(due)
+		},
+		"formatDate": func(layout string, date interface{}) string {
+			t, ok := toTime(date)
+			if !ok {
+				return fmt.Sprint(date)
+			}
+			return l.Format(t, layout)
+		},
+		"ordinal": func(n interface{}) (string, error) {
+			i, err := toInt(n)
+			if err != nil {
+				return "", err
+			}
+			return l.Ordinal(i), nil
+		},
+		"initials":  Initials,
+		"firstname": Firstname,
+		"lastname":  Lastname,
+		"texescape": TexEscape,
+		"padNumber": PadNumber,
+	}
+}
+
+// Format formats t like time.Format, but with the names of months and days of
+// the week in the locale's language
+func (l *Locale) Format(t time.Time, layout string) string {
+	var b strings.Builder
+	for layout != "" {
+		// find the next name token, longer tokens take precedence over their prefixes
+		next, token := -1, ""
+		for _, tok := range []string{"January", "Jan", "Monday", "Mon"} {
+			if i := strings.Index(layout, tok); i >= 0 && (next < 0 || i < next || (i == next && len(tok) > len(token))) {
+				next, token = i, tok
+			}
+		}
+		if next < 0 {
+			b.WriteString(t.Format(layout))
+			break
+		}
+		b.WriteString(t.Format(layout[:next]))
+		switch token {
+		case "January":
+			b.WriteString(l.Months[t.Month()-1])
+		case "Jan":
+			b.WriteString(l.ShortMonths[t.Month()-1])
+		case "Monday":
+			b.WriteString(l.Weekdays[t.Weekday()])
+		case "Mon":
+			b.WriteString(l.ShortWeekdays[t.Weekday()])
+		}
+		layout = layout[next+len(token):]
+	}
+	return b.String()
+}
+
+// formatDue formats a due date with the locale's date layout. Strings that are
+// no known date format are returned unchanged, as due dates may be free-form
+func (l *Locale) formatDue(due interface{}) string {
+	t, ok := toTime(due)
+	if !ok {
+		return fmt.Sprint(due)
+	}
+	return l.Format(t, l.DateLayout)
+}
+
+// toTime converts time.Time and strings in one of the dueLayouts to time.Time
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, false
+		}
+		return *t, !t.IsZero()
+	case string:
+		s := strings.TrimSpace(t)
+		for _, layout := range dueLayouts {
+			if parsed, err := time.Parse(layout, s); err == nil {
+				return parsed, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// toInt converts integers and numeric strings such as "05" to int
+func toInt(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int32:
+		return int(n), nil
+	case int64:
+		return int(n), nil
+	case uint:
+		return int(n), nil
+	case uint32:
+		return int(n), nil
+	case uint64:
+		return int(n), nil
+	case float64:
+		return int(n), nil
+	case string:
+		return strconv.Atoi(strings.TrimSpace(n))
+	}
+	return 0, fmt.Errorf("cannot convert %v of type %T to a number", v, v)
+}
+
+// splitName splits a full name into first and last name. The last name starts at
+// the first particle after the first word, e.g., "van der Berg", or is the last word
+func splitName(name string) (string, string) {
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return "", ""
+	}
+	last := len(words) - 1
+	for i := 1; i < len(words)-1; i++ {
+		if particles[strings.ToLower(words[i])] {
+			last = i
+			break
+		}
+	}
+	return strings.Join(words[:last], " "), strings.Join(words[last:], " ")
+}
+
+// Firstname returns the given names of a full name, e.g., "Johann Wolfgang" for
+// "Johann Wolfgang von Goethe"
+func Firstname(name string) string {
+	first, _ := splitName(name)
+	return first
+}
+
+// Lastname returns the last name of a full name including particles, e.g.,
+// "von Goethe" for "Johann Wolfgang von Goethe"
+func Lastname(name string) string {
+	_, last := splitName(name)
+	return last
+}
+
+// Initials abbreviates all parts of a name, keeping hyphens and the lowercase
+// of particles, e.g., "H.-P. v. B." for "Hans-Peter van Beethoven"
+func Initials(name string) string {
+	parts := []string{}
+	for _, word := range strings.Fields(name) {
+		if particles[strings.ToLower(word)] {
+			r, _ := utf8.DecodeRuneInString(word)
+			parts = append(parts, string(unicode.ToLower(r))+".")
+			continue
+		}
+		segments := []string{}
+		for _, s := range strings.Split(word, "-") {
+			r, _ := utf8.DecodeRuneInString(s)
+			if r == utf8.RuneError {
+				continue
+			}
+			segments = append(segments, string(r)+".")
+		}
+		parts = append(parts, strings.Join(segments, "-"))
+	}
+	return strings.Join(parts, " ")
+}
+
+// TexEscape escapes all characters with special meaning in TeX, such that
+// arbitrary strings can be used in the generated source
+func TexEscape(s interface{}) string {
+	return texReplacer.Replace(fmt.Sprint(s))
+}
+
+// PadNumber formats a number with leading zeros to the given width
+func PadNumber(width int, n interface{}) (string, error) {
+	i, err := toInt(n)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", width, i), nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		firstname string
+		lastname  string
+		initials  string
+	}{
+		{"Max Mustermann", "Max", "Mustermann", "M. M."},
+		{"Johann Wolfgang von Goethe", "Johann Wolfgang", "von Goethe", "J. W. v. G."},
+		{"Hans-Peter van der Berg", "Hans-Peter", "van der Berg", "H.-P. v. d. B."},
+		{"Cher", "", "Cher", "C."},
+		{"", "", "", ""},
+	}
+	for _, tt := range tests {
+		if f := Firstname(tt.name); f != tt.firstname {
+			t.Errorf("expected firstname %q of %q, found %q", tt.firstname, tt.name, f)
+		}
+		if l := Lastname(tt.name); l != tt.lastname {
+			t.Errorf("expected lastname %q of %q, found %q", tt.lastname, tt.name, l)
+		}
+		if i := Initials(tt.name); i != tt.initials {
+			t.Errorf("expected initials %q of %q, found %q", tt.initials, tt.name, i)
+		}
+	}
+}
+
+func TestLocales(t *testing.T) {
+	due := time.Date(2026, time.October, 17, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		locale  string
+		due     string
+		ordinal string
+	}{
+		{"", "October 17, 2026", "22nd"},
+		{"en", "October 17, 2026", "22nd"},
+		{"de_DE.UTF-8", "17. Oktober 2026", "22."},
+		{"fr", "17 octobre 2026", "22e"},
+		{"xx", "October 17, 2026", "22nd"},
+	}
+	for _, tt := range tests {
+		l := LookupLocale(tt.locale)
+		if s := l.formatDue(due); s != tt.due {
+			t.Errorf("expected %q in locale %q, found %q", tt.due, tt.locale, s)
+		}
+		if s := l.formatDue("2026-10-17"); s != tt.due {
+			t.Errorf("expected %q in locale %q for string input, found %q", tt.due, tt.locale, s)
+		}
+		if s := l.Ordinal(22); s != tt.ordinal {
+			t.Errorf("expected ordinal %q in locale %q, found %q", tt.ordinal, tt.locale, s)
+		}
+	}
+
+	if s := LookupLocale("en").formatDue("next week, maybe"); s != "next week, maybe" {
+		t.Errorf("expected free-form due date to be kept, found %q", s)
+	}
+	if s := LookupLocale("de").Format(due, "Monday, 2. Jan 15:04"); s != "Samstag, 17. Okt. 23:59" {
+		t.Errorf("expected localized names, found %q", s)
+	}
+	for n, expected := range map[int]string{1: "1st", 11: "11th", 13: "13th", 101: "101st", 112: "112th"} {
+		if s := LookupLocale("en").Ordinal(n); s != expected {
+			t.Errorf("expected %s, found %s", expected, s)
+		}
+	}
+}
+
+func TestTexEscape(t *testing.T) {
+	s := TexEscape(`R&D 100% $5 #1 a_b {x} ~ ^ \`)
+	expected := `R\&D 100\% \$5 \#1 a\_b \{x\} \textasciitilde{} \textasciicircum{} \textbackslash{}`
+	if s != expected {
+		t.Errorf("expected %q, found %q", expected, s)
+	}
+}
+
+func TestPadNumber(t *testing.T) {
+	for _, tt := range []struct {
+		n        interface{}
+		expected string
+	}{
+		{3, "03"},
+		{uint32(12), "12"},
+		{"7", "07"},
+		{123, "123"},
+	} {
+		s, err := PadNumber(2, tt.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != tt.expected {
+			t.Errorf("expected %s, found %s", tt.expected, s)
+		}
+	}
+	if _, err := PadNumber(2, "x"); err == nil {
+		t.Error("expected error for non-numeric input")
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultPackName is the name of the template pack used if present and no
+	// other pack is selected
+	DefaultPackName string = "default"
+)
+
+var (
+	// PacksDirectory is the directory relative to the repository's root that
+	// contains one directory per template pack
+	PacksDirectory string = filepath.Join(".assignments", "templates")
+
+	// ErrPackNotFound is returned when a selected template pack does not exist
+	ErrPackNotFound error = errors.New("template pack not found")
+)
+
+// Pack is a directory tree that is rendered as a whole into a new assignment's
+// directory. Both file contents and file names are templates executed with the
+// same bindings as the assignment's main source file. A path segment that renders
+// to the empty string is skipped, including anything below it, which allows for
+// conditional files. Files that are not valid UTF-8 are copied verbatim.
+type Pack struct {
+	// Name of the pack, i.e., the name of its directory in PacksDirectory
+	Name string
+	// Root is the absolute path to the pack's directory
+	Root string
+}
+
+// Packs lists the names of all template packs available in the repository
+func Packs(root string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(root, PacksDirectory))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	packs := []string{}
+	for _, e := range entries {
+		if e.IsDir() {
+			packs = append(packs, e.Name())
+		}
+	}
+	sort.Strings(packs)
+	return packs, nil
+}
+
+// LoadPack returns the template pack with the given name from the repository at
+// root. If the pack does not exist, LoadPack returns an error wrapping ErrPackNotFound
+func LoadPack(root string, name string) (*Pack, error) {
+	p := filepath.Join(root, PacksDirectory, name)
+	fi, err := os.Stat(p)
+	if err != nil || !fi.IsDir() {
+		available, _ := Packs(root)
+		return nil, fmt.Errorf("%w: %q, available packs are [%s]", ErrPackNotFound, name, strings.Join(available, ", "))
+	}
+	return &Pack{
+		Name: name,
+		Root: p,
+	}, nil
+}
+
+// Render executes the pack's tree with the given bindings into dest, creating
+// directories as necessary. It returns the paths of all files written, relative
+// to dest.
+func (p *Pack) Render(dest string, bindings *TemplateBinding) ([]string, error) {
+	written := []string{}
+
+	err := filepath.WalkDir(p.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == p.Root {
+			return nil
+		}
+		rel, err := filepath.Rel(p.Root, path)
+		if err != nil {
+			return err
+		}
+
+		target, err := renderPath(rel, bindings)
+		if err != nil {
+			return err
+		}
+		if target == "" {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dest, target), fi.Mode().Perm()|0700)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if utf8.Valid(content) {
+			out, err := renderSheetTemplate(rel, string(content), bindings)
+			if err != nil {
+				return err
+			}
+			content = out.Bytes()
+		}
+
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dest, target)), 0777); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dest, target), content, fi.Mode().Perm()); err != nil {
+			return err
+		}
+		written = append(written, target)
+		return nil
+	})
+
+	return written, err
+}
+
+// renderPath executes each segment of a relative path as a template. Returns the
+// empty string if any of the segments renders to the empty string
+func renderPath(rel string, bindings *TemplateBinding) (string, error) {
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i, segment := range segments {
+		out, err := renderSheetTemplate(rel, segment, bindings)
+		if err != nil {
+			return "", err
+		}
+		s := strings.TrimSpace(out.String())
+		if s == "" {
+			return "", nil
+		}
+		if strings.ContainsAny(s, `/\`) || s == "." || s == ".." {
+			return "", fmt.Errorf("path segment %q of %s renders to invalid file name %q", segment, rel, s)
+		}
+		segments[i] = s
+	}
+	return filepath.Join(segments...), nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func makePack(t *testing.T, root string, name string, files map[string]string) {
+	for p, content := range files {
+		f := filepath.Join(root, PacksDirectory, name, p)
+		if err := os.MkdirAll(filepath.Dir(f), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(f, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPacks(t *testing.T) {
+	root := t.TempDir()
+
+	packs, err := Packs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packs) != 0 {
+		t.Errorf("expected no packs, found %v", packs)
+	}
+
+	makePack(t, root, "weekly", map[string]string{"README.md": ""})
+	makePack(t, root, "project", map[string]string{"README.md": ""})
+
+	packs, err = Packs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(packs, ",") != "project,weekly" {
+		t.Errorf("expected packs [project weekly], found %v", packs)
+	}
+
+	_, err = LoadPack(root, "report")
+	if !errors.Is(err, ErrPackNotFound) {
+		t.Errorf("expected ErrPackNotFound, found %v", err)
+	}
+}
+
+func TestPackRender(t *testing.T) {
+	root := t.TempDir()
+	makePack(t, root, "weekly", map[string]string{
+		"README.md":                       "# {{ .Course }}, sheet {{ .Sheet }}\n",
+		"code/main.py":                    "# sheet {{ .Sheet }}\n",
+		"notes-{{ .Sheet }}.md":           "",
+		"{{ if .Group }}group{{ end }}/x": "skipped without a group",
+		"figure.pdf":                      "\xff\xfe{{ .Sheet }}",
+	})
+	pack, err := LoadPack(root, "weekly")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(root, "assignment-03")
+	files, err := pack.Render(dest, &TemplateBinding{
+		Course: "Linear Algebra I",
+		Sheet:  "03",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	expected := []string{"README.md", filepath.Join("code", "main.py"), "figure.pdf", "notes-03.md"}
+	if strings.Join(files, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected files %v, found %v", expected, files)
+	}
+
+	readme, err := os.ReadFile(filepath.Join(dest, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(readme) != "# Linear Algebra I, sheet 03\n" {
+		t.Errorf("expected rendered README, found %q", readme)
+	}
+
+	figure, err := os.ReadFile(filepath.Join(dest, "figure.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(figure) != "\xff\xfe{{ .Sheet }}" {
+		t.Errorf("expected binary file to be copied verbatim, found %q", figure)
+	}
+
+	t.Run("invalid file name", func(t *testing.T) {
+		makePack(t, root, "broken", map[string]string{
+			"{{ .Course }}.md": "",
+		})
+		pack, err := LoadPack(root, "broken")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = pack.Render(t.TempDir(), &TemplateBinding{Course: "a/b"})
+		if err == nil {
+			t.Error("expected error for file name containing a path separator")
+		}
+	})
+}
@@ -18,8 +18,10 @@ package template
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 	"text/template"
+	"time"
 
 	_ "embed"
 
@@ -34,48 +36,201 @@ var (
 		{{- range $_, $input := .Includes -}}
 		\input{ {{- $input -}} }
 		{{ end }}
-		\course{ {{- .Course -}} }
-		\group{ {{- .Group | default "" -}} }
-		\sheet{ {{- .Sheet | default "" -}} }
-		\due{ {{- .Due | default "" -}} }
+		\course{ {{- .Course | texescape -}} }
+		\group{ {{- .Group | default "" | texescape -}} }
+		\sheet{ {{- .Sheet | default "" | texescape -}} }
+		\due{ {{- .Due | default "" | texescape -}} }
 		{{- range $_, $member := .Members }}
-		{{- $firstname := ($member.Name | splitList " " | initial | join " ") | default "" }}
-		{{- $lastname := ($member.Name | splitList " " | last) | default "" }}
-		\member[{{- $member.ID -}}]{ {{- $member.Name -}} }
+		\member[{{- $member.ID | texescape -}}]{ {{- $member.Name | texescape -}} }
 		{{- end }}
 		
 		\begin{document}
 		\maketitle
 		\gradingtable
+		{{- range $_, $exercise := .Exercises }}
+		
+		{{ if $exercise.File -}}
+		\input{ {{- $exercise.File | trimSuffix ".tex" -}} }
+		{{- else -}}
+		{{ template "exercise" $exercise }}
+		{{- end }}
+		{{- else }}
 		
 		% Start the assignment here
+		{{- end }}
 		
 		\end{document}
 	`), "\n")
+
+	// DefaultExerciseTemplate renders a single exercise skeleton. It is associated with
+	// every sheet template as "exercise", such that custom templates can use it with
+	// {{ template "exercise" $exercise }}, and is used on its own for exercise-N.tex files
+	DefaultExerciseTemplate = strings.TrimSpace(dedent.Dedent(`
+		\exercise{{ if .Points }}[{{ .Points }}]{{ end }}{ {{- .Title | texescape -}} }
+		{{- range $_ := until .Subexercises }}
+		
+		\subexercise
+		{{- end }}
+	`))
 )
 
+// Exercise is the template representation of an exercise skeleton
+type Exercise struct {
+	// Number is the exercise's 1-based index in the assignment
+	Number int
+	// Title of the exercise, may be empty
+	Title string
+	// Points awarded for the exercise, omitted if zero
+	Points float64
+	// Subexercises is the number of subexercise skeletons to add
+	Subexercises int
+	// File is the exercise's source file relative to the assignment's directory,
+	// if the exercise is split from the main source file, and empty otherwise
+	File string
+}
+
+// SheetTemplateName is the name of the assignment's main source template, as
+// shown in errors. It matches the template's location in the configuration
+const SheetTemplateName string = "spec.template"
+
 type TemplateBinding struct {
-	ClassPath string
-	Course    string
-	Group     string
-	Sheet     string
-	Due       string
+	Course string
+	Group  string
+	Sheet  string
+	// Due is the formatted due date, or the empty string if there is none
+	Due string
+	// DueDate is the due date in the course's timezone, or nil if there is none
+	DueDate   *time.Time
 	Members   []config.GroupMember
 	Includes  []config.Include
+	Exercises []Exercise
+	// Data is the custom data from .spec.data, merged with the assignment's
+	// overrides from .status.assignments
+	Data map[string]interface{}
+	// Spec is the full configuration spec, for anything not covered by the
+	// fields above
+	Spec *config.ConfigurationSpec
+	// Assignment is the metadata of the assignment being rendered
+	Assignment Assignment
+}
+
+// Assignment is the template representation of an assignment's metadata
+type Assignment struct {
+	// Number is the assignment's number, zero for named assignments
+	Number uint32
+	// Suffix is the letter following the number, e.g., "a" for 05a, if any
+	Suffix string
+	// Name is the name of named assignments, e.g., "bonus"
+	Name string
+	// ID is the assignment's ID with leading zeros, as used in file names
+	ID string
+	// Directory is the assignment's directory relative to the repository's root
+	Directory string
+	// Title is the assignment's title from its configuration file, if any
+	Title string
+}
+
+// MakeExercises merges the exercise defaults from the configuration with the
+// number of exercises and their points from flags. A count of 0 falls back to
+// the number of points given, or otherwise the number of defaults. Points
+// override the defaults' points position by position. If split is set, each
+// exercise is assigned its own file exercise-N.tex.
+func MakeExercises(defaults []config.Exercise, count int, points []float64, split bool) ([]Exercise, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("number of exercises must not be negative, got %d", count)
+	}
+	if count == 0 {
+		count = len(points)
+	}
+	if count == 0 {
+		count = len(defaults)
+	}
+	if len(points) > count {
+		return nil, fmt.Errorf("got points for %d exercises, but only %d exercises", len(points), count)
+	}
+
+	exercises := make([]Exercise, 0, count)
+	for i := 0; i < count; i++ {
+		e := Exercise{
+			Number: i + 1,
+		}
+		if i < len(defaults) {
+			e.Title = defaults[i].Title
+			e.Points = defaults[i].Points
+			e.Subexercises = defaults[i].Subexercises
+		}
+		if i < len(points) {
+			e.Points = points[i]
+		}
+		if split {
+			e.File = ExerciseFilename(e.Number)
+		}
+		exercises = append(exercises, e)
+	}
+	return exercises, nil
+}
+
+// ExerciseFilename returns the name of the source file of a split exercise
+func ExerciseFilename(number int) string {
+	return fmt.Sprintf("exercise-%d.tex", number)
 }
 
+// GenerateAssignmentTemplate renders the assignment's main source file from tpl, or
+// DefaultSheetTemplate if tpl is empty. Errors from parsing and executing the
+// template are returned as *Error with the position in spec.template
 func GenerateAssignmentTemplate(tpl *string, bindings *TemplateBinding) (*bytes.Buffer, error) {
 	if tpl == nil || *tpl == "" {
 		tpl = &DefaultSheetTemplate
 	}
-	tmpl := template.Must(template.New("assignment").Funcs(sprig.TxtFuncMap()).Parse(*tpl))
+	return renderSheetTemplate(SheetTemplateName, *tpl, bindings)
+}
+
+// newSheetTemplate parses a template for a sheet's source with sprig's functions and
+// the functions from FuncMap for the locale, and associates the default exercise
+// template as "exercise", unless the template brings its own definition
+func newSheetTemplate(name string, text string, locale string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(sprig.TxtFuncMap()).Funcs(FuncMap(locale)).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl.Lookup("exercise") == nil {
+		if _, err := tmpl.New("exercise").Parse(DefaultExerciseTemplate); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+// GenerateExerciseTemplate renders a single exercise into its own source file
+func GenerateExerciseTemplate(exercise *Exercise) (*bytes.Buffer, error) {
+	tmpl, err := template.New("exercise").Funcs(sprig.TxtFuncMap()).Funcs(FuncMap(DefaultLocale)).Parse(DefaultExerciseTemplate)
+	if err != nil {
+		return nil, err
+	}
 
 	var output bytes.Buffer
 
-	err := tmpl.Execute(&output, bindings)
+	err = tmpl.Execute(&output, exercise)
 
 	if err != nil {
 		return nil, err
 	}
+	output.WriteString("\n")
+	return &output, nil
+}
+
+func renderSheetTemplate(name string, text string, bindings *TemplateBinding) (*bytes.Buffer, error) {
+	locale := ""
+	if bindings != nil && bindings.Spec != nil {
+		locale = bindings.Spec.Locale
+	}
+	tmpl, err := newSheetTemplate(name, text, locale)
+	if err != nil {
+		return nil, NewError(name, text, err)
+	}
+	var output bytes.Buffer
+	if err := tmpl.Execute(&output, bindings); err != nil {
+		return nil, NewError(name, text, err)
+	}
 	return &output, nil
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package template
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/lithammer/dedent"
@@ -25,11 +26,10 @@ import (
 
 func TestGenerateDefaultAssignmentTemplate(t *testing.T) {
 	bindings := TemplateBinding{
-		ClassPath: "../assignments",
-		Course:    "Example Course",
-		Group:     "Group Alpha",
-		Sheet:     "01",
-		Due:       "April 20th, 2021",
+		Course: "Example Course",
+		Group:  "Group Alpha",
+		Sheet:  "01",
+		Due:    "April 20th, 2021",
 		Includes: []config.Include{{
 			Path: "./math.tex",
 		}},
@@ -60,11 +60,10 @@ func TestGenerateDefaultAssignmentTemplate(t *testing.T) {
 
 func TestGenerateCustomAssignmentTemplate(t *testing.T) {
 	bindings := TemplateBinding{
-		ClassPath: "../assignments",
-		Course:    "Example Course",
-		Group:     "Group Alpha",
-		Sheet:     "01",
-		Due:       "April 20th, 2021",
+		Course: "Example Course",
+		Group:  "Group Alpha",
+		Sheet:  "01",
+		Due:    "April 20th, 2021",
 		Members: []config.GroupMember{
 			{
 				Name: "Max Mustermann",
@@ -105,3 +104,127 @@ func TestGenerateCustomAssignmentTemplate(t *testing.T) {
 		t.Fatal(`GenerateAssignmentTemplate() should NOT return an empty string on this binding`)
 	}
 }
+
+func TestGenerateAssignmentTemplateData(t *testing.T) {
+	spec := &config.ConfigurationSpec{
+		Course: "Example Course",
+		Data:   map[string]interface{}{"tutor": "Jane Doe"},
+	}
+	bindings := TemplateBinding{
+		Course: spec.Course,
+		Data:   config.MergeData(spec.Data, map[string]interface{}{"room": "AH 1"}),
+		Spec:   spec,
+		Assignment: Assignment{
+			Number:    3,
+			ID:        "03",
+			Directory: "assignment-03",
+		},
+	}
+
+	template := `{{ .Data.tutor }}, {{ .Data.room }}, {{ .Spec.Course }}, {{ .Assignment.Number }}, {{ .Assignment.Directory }}`
+	o, err := GenerateAssignmentTemplate(&template, &bindings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Jane Doe, AH 1, Example Course, 3, assignment-03"
+	if o.String() != expected {
+		t.Errorf("expected %q, found %q", expected, o.String())
+	}
+}
+
+func TestMakeExercises(t *testing.T) {
+	defaults := []config.Exercise{
+		{Title: "Linear Maps", Points: 5, Subexercises: 2},
+		{Title: "Eigenvalues", Points: 5},
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		e, err := MakeExercises(defaults, 0, nil, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(e) != 2 {
+			t.Fatalf("expected 2 exercises, found %d", len(e))
+		}
+		if e[0].Title != "Linear Maps" || e[0].Subexercises != 2 || e[0].File != "" {
+			t.Errorf("expected defaults to be applied, found %+v", e[0])
+		}
+	})
+	t.Run("count and points", func(t *testing.T) {
+		e, err := MakeExercises(defaults, 4, []float64{10, 10, 15}, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(e) != 4 {
+			t.Fatalf("expected 4 exercises, found %d", len(e))
+		}
+		if e[0].Points != 10 || e[2].Points != 15 || e[3].Points != 0 {
+			t.Errorf("expected points to override defaults, found %+v", e)
+		}
+		if e[1].Title != "Eigenvalues" {
+			t.Errorf("expected title %s, found %s", "Eigenvalues", e[1].Title)
+		}
+		if e[3].Number != 4 || e[3].File != "exercise-4.tex" {
+			t.Errorf("expected exercise 4 in exercise-4.tex, found %+v", e[3])
+		}
+	})
+	t.Run("points without count", func(t *testing.T) {
+		e, err := MakeExercises(nil, 0, []float64{10, 10, 15, 5}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(e) != 4 {
+			t.Fatalf("expected 4 exercises, found %d", len(e))
+		}
+	})
+	t.Run("too many points", func(t *testing.T) {
+		_, err := MakeExercises(nil, 2, []float64{10, 10, 15}, false)
+		if err == nil {
+			t.Error("expected error when passing more points than exercises")
+		}
+	})
+}
+
+func TestGenerateExercises(t *testing.T) {
+	exercises := []Exercise{
+		{Number: 1, Title: "Linear Maps", Points: 7.5, Subexercises: 1},
+		{Number: 2, File: "exercise-2.tex"},
+	}
+	o, err := GenerateAssignmentTemplate(nil, &TemplateBinding{
+		Course:    "Example Course",
+		Exercises: exercises,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := dedent.Dedent(`
+		\gradingtable
+
+		\exercise[7.5]{Linear Maps}
+
+		\subexercise
+
+		\input{exercise-2}
+
+		\end{document}
+	`)
+	if !strings.HasSuffix(o.String(), strings.TrimPrefix(expected, "\n")) {
+		t.Errorf("expected rendered template to end with\n%s\nfound\n%s", expected, o)
+	}
+
+	e, err := GenerateExerciseTemplate(&exercises[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.String() != "\\exercise{}\n" {
+		t.Errorf("expected bare exercise skeleton, found %q", e.String())
+	}
+
+	e, err = GenerateExerciseTemplate(&Exercise{Number: 3, Title: "Sets & Relations"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.String() != "\\exercise{Sets \\& Relations}\n" {
+		t.Errorf("expected escaped exercise title, found %q", e.String())
+	}
+}
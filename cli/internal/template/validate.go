@@ -0,0 +1,260 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/Masterminds/sprig/v3"
+)
+
+// Validate parses a template and statically checks all field references against
+// data, which is either a value of the struct the template is executed with, or a
+// map with the exact keys available to the template. It returns the parsing error
+// if the template does not parse, and otherwise one error per undefined field.
+//
+// The check is conservative: references whose type cannot be determined, e.g.,
+// fields of function results or of values inside of maps, are not reported.
+func Validate(name string, text string, data interface{}) []error {
+	tmpl, err := template.New(name).Funcs(sprig.TxtFuncMap()).Funcs(FuncMap(DefaultLocale)).Parse(text)
+	if err != nil {
+		return []error{NewError(name, text, err)}
+	}
+
+	v := &validator{
+		name: name,
+		text: text,
+		tree: tmpl.Tree,
+		root: reflect.ValueOf(data),
+		errs: []error{},
+	}
+	if tmpl.Tree == nil || tmpl.Tree.Root == nil {
+		return v.errs
+	}
+	v.walk(tmpl.Tree.Root, v.root.Type(), map[string]reflect.Type{"$": v.root.Type()})
+	return v.errs
+}
+
+type validator struct {
+	name string
+	text string
+	tree *parse.Tree
+	root reflect.Value
+	errs []error
+}
+
+// walk traverses the template's tree, tracking the type of dot and of all variables.
+// A nil type marks values of unknown type, for which nothing is checked
+func (v *validator) walk(node parse.Node, dot reflect.Type, vars map[string]reflect.Type) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			v.walk(c, dot, vars)
+		}
+	case *parse.ActionNode:
+		v.pipe(n.Pipe, dot, vars)
+	case *parse.IfNode:
+		v.pipe(n.Pipe, dot, vars)
+		v.walk(n.List, dot, copyVars(vars))
+		v.walk(n.ElseList, dot, copyVars(vars))
+	case *parse.WithNode:
+		t := v.pipe(n.Pipe, dot, vars)
+		v.walk(n.List, t, copyVars(vars))
+		v.walk(n.ElseList, dot, copyVars(vars))
+	case *parse.RangeNode:
+		inner := copyVars(vars)
+		t := v.pipeType(n.Pipe, dot, vars)
+		// range declares its variables itself, so only check the pipeline's arguments
+		v.args(n.Pipe, dot, vars)
+		var key, elem reflect.Type
+		if t != nil {
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array:
+				key, elem = reflect.TypeOf(0), t.Elem()
+			case reflect.Map:
+				key, elem = t.Key(), t.Elem()
+			}
+		}
+		switch len(n.Pipe.Decl) {
+		case 1:
+			inner[n.Pipe.Decl[0].Ident[0]] = elem
+		case 2:
+			inner[n.Pipe.Decl[0].Ident[0]] = key
+			inner[n.Pipe.Decl[1].Ident[0]] = elem
+		}
+		v.walk(n.List, elem, inner)
+		v.walk(n.ElseList, dot, copyVars(vars))
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			v.pipe(n.Pipe, dot, vars)
+		}
+	}
+}
+
+// pipe checks all commands of a pipeline, declares its variables, and returns the
+// type of the pipeline's result if it can be determined
+func (v *validator) pipe(p *parse.PipeNode, dot reflect.Type, vars map[string]reflect.Type) reflect.Type {
+	if p == nil {
+		return nil
+	}
+	v.args(p, dot, vars)
+	t := v.pipeType(p, dot, vars)
+	for _, d := range p.Decl {
+		if p.IsAssign {
+			// assignments keep the variable's declared type, but the value
+			// might as well be of a different type, so drop it
+			vars[d.Ident[0]] = nil
+			continue
+		}
+		vars[d.Ident[0]] = t
+	}
+	return t
+}
+
+// args checks the arguments of all commands of a pipeline
+func (v *validator) args(p *parse.PipeNode, dot reflect.Type, vars map[string]reflect.Type) {
+	for _, cmd := range p.Cmds {
+		for _, arg := range cmd.Args {
+			v.arg(arg, dot, vars)
+		}
+	}
+}
+
+// pipeType returns the type of a pipeline that consists of a single field reference
+func (v *validator) pipeType(p *parse.PipeNode, dot reflect.Type, vars map[string]reflect.Type) reflect.Type {
+	if p == nil || len(p.Cmds) != 1 || len(p.Cmds[0].Args) != 1 {
+		return nil
+	}
+	switch a := p.Cmds[0].Args[0].(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		t, _ := resolve(dot, a.Ident)
+		return t
+	case *parse.VariableNode:
+		t, ok := vars[a.Ident[0]]
+		if !ok {
+			return nil
+		}
+		t, _ = resolve(t, a.Ident[1:])
+		return t
+	}
+	return nil
+}
+
+func (v *validator) arg(node parse.Node, dot reflect.Type, vars map[string]reflect.Type) {
+	switch a := node.(type) {
+	case *parse.FieldNode:
+		v.check(a, dot, a.Ident, "")
+	case *parse.VariableNode:
+		t, ok := vars[a.Ident[0]]
+		if ok && len(a.Ident) > 1 {
+			v.check(a, t, a.Ident[1:], a.Ident[0])
+		}
+	case *parse.PipeNode:
+		v.pipe(a, dot, vars)
+	case *parse.ChainNode:
+		if p, ok := a.Node.(*parse.PipeNode); ok {
+			v.pipe(p, dot, vars)
+		}
+	}
+}
+
+// check reports an error if the chain of field names cannot be resolved on t
+func (v *validator) check(node parse.Node, t reflect.Type, idents []string, prefix string) {
+	if t == nil {
+		return
+	}
+	_, missing := resolve(t, idents)
+	if missing < 0 && t == v.root.Type() && t.Kind() == reflect.Map && len(idents) > 0 {
+		// the root map's keys are known, so check the first key against the actual data
+		if !v.root.MapIndex(reflect.ValueOf(idents[0])).IsValid() {
+			missing = 0
+		}
+	}
+	if missing < 0 {
+		return
+	}
+	location, _ := v.tree.ErrorContext(node)
+	field := prefix + "." + strings.Join(idents[:missing+1], ".")
+	v.errs = append(v.errs, NewError(v.name, v.text, fmt.Errorf("template: %s: undefined field %s", location, field)))
+}
+
+// resolve walks the field names on t. Returns the type of the last field, or nil if
+// it cannot be determined, and the index of the first field that does not exist,
+// or -1 if all fields exist or cannot be checked
+func resolve(t reflect.Type, idents []string) (reflect.Type, int) {
+	for i, ident := range idents {
+		if t == nil {
+			return nil, -1
+		}
+		if m, ok := t.MethodByName(ident); ok {
+			t = returnType(m.Type)
+			continue
+		}
+		if t.Kind() != reflect.Pointer {
+			if m, ok := reflect.PointerTo(t).MethodByName(ident); ok {
+				t = returnType(m.Type)
+				continue
+			}
+		}
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := t.FieldByName(ident)
+			if !ok || !f.IsExported() {
+				return nil, i
+			}
+			t = f.Type
+		case reflect.Map:
+			// keys of maps are only known at runtime
+			t = t.Elem()
+			if t.Kind() == reflect.Interface {
+				return nil, -1
+			}
+		case reflect.Interface:
+			return nil, -1
+		default:
+			return nil, i
+		}
+	}
+	return t, -1
+}
+
+func returnType(m reflect.Type) reflect.Type {
+	if m.NumOut() == 0 {
+		return nil
+	}
+	return m.Out(0)
+}
+
+func copyVars(vars map[string]reflect.Type) map[string]reflect.Type {
+	n := make(map[string]reflect.Type, len(vars))
+	for k, t := range vars {
+		n[k] = t
+	}
+	return n
+}
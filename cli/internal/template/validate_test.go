@@ -0,0 +1,106 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewError(t *testing.T) {
+	source := "\\course{ {{- .Course -}} }\n\\sheet{ {{- .Sheet }\n"
+	_, err := GenerateAssignmentTemplate(&source, &TemplateBinding{})
+	if err == nil {
+		t.Fatal("expected parsing error")
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *Error, found %T", err)
+	}
+	if e.Template != SheetTemplateName || e.Line != 2 {
+		t.Errorf("expected error at %s:2, found %s:%d", SheetTemplateName, e.Template, e.Line)
+	}
+	if e.Context != "\\sheet{ {{- .Sheet }" {
+		t.Errorf("expected source line as context, found %q", e.Context)
+	}
+
+	source = "\\course{ {{- .Course -}} }\n\\sheet{ {{- .Shet -}} }\n"
+	_, err = GenerateAssignmentTemplate(&source, &TemplateBinding{})
+	if !errors.As(err, &e) {
+		t.Fatalf("expected *Error, found %T", err)
+	}
+	if e.Line != 2 || e.Column != 13 {
+		t.Errorf("expected error at 2:13, found %d:%d", e.Line, e.Column)
+	}
+	if !strings.Contains(e.Error(), "^") {
+		t.Errorf("expected column marker in %q", e.Error())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		data     interface{}
+		expected []string
+	}{
+		{
+			name:     "default template",
+			template: DefaultSheetTemplate,
+			data:     &TemplateBinding{},
+			expected: []string{},
+		},
+		{
+			name:     "undefined field",
+			template: "{{ .Course }} {{ .Corse }}",
+			data:     &TemplateBinding{},
+			expected: []string{"1:18: undefined field .Corse"},
+		},
+		{
+			name:     "fields of range elements",
+			template: "{{ range $i, $m := .Members }}{{ $m.Name }}{{ .ID }}{{ $m.Mail }}{{ end }}",
+			data:     &TemplateBinding{},
+			expected: []string{"undefined field $m.Mail"},
+		},
+		{
+			name:     "root map keys",
+			template: "{{ ._id }}-{{ .group }}.{{ ._format }}",
+			data:     map[string]interface{}{"_id": "", "_format": ""},
+			expected: []string{"undefined field .group"},
+		},
+		{
+			name:     "syntax error",
+			template: "{{ .Course }",
+			data:     &TemplateBinding{},
+			expected: []string{"unexpected"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Validate("test", tt.template, tt.data)
+			if len(errs) != len(tt.expected) {
+				t.Fatalf("expected %d errors, found %v", len(tt.expected), errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tt.expected[i]) {
+					t.Errorf("expected error containing %q, found %q", tt.expected[i], err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tex
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Docstrip extracts all code lines from a documented LaTeX source (.dtx) that are
+// enabled for the given option, similar to what running the corresponding .ins
+// file through docstrip.tex does. This way we can install the class without
+// requiring a LaTeX distribution to be present.
+//
+// Only the subset of docstrip used by csassignments.dtx is supported: comment
+// lines are dropped, meta comments (%%) are kept, and guards may be blocks
+// (%<*option> ... %</option>) or single lines (%<option>code) with expressions
+// combining options by "!", "&", and "|" without parentheses.
+func Docstrip(source []byte, option string) ([]byte, error) {
+	var out bytes.Buffer
+	guards := []string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(source))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "%<") {
+			end := strings.Index(line, ">")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated guard %q", lineNo, line)
+			}
+			guard := line[2:end]
+			rest := line[end+1:]
+			switch {
+			case strings.HasPrefix(guard, "*"):
+				guards = append(guards, guard[1:])
+			case strings.HasPrefix(guard, "/"):
+				if len(guards) == 0 || guards[len(guards)-1] != guard[1:] {
+					return nil, fmt.Errorf("line %d: closing guard %q does not match any open guard", lineNo, guard[1:])
+				}
+				guards = guards[:len(guards)-1]
+			default:
+				if active(guards, option) && evaluateGuard(guard, option) {
+					out.WriteString(rest)
+					out.WriteByte('\n')
+				}
+			}
+			continue
+		}
+
+		if !active(guards, option) {
+			continue
+		}
+		if strings.HasPrefix(line, "%%") {
+			// meta comments are copied verbatim into the output
+			out.WriteString(line)
+			out.WriteByte('\n')
+			continue
+		}
+		if strings.HasPrefix(line, "%") {
+			continue
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(guards) != 0 {
+		return nil, fmt.Errorf("unclosed guard %q at end of file", guards[len(guards)-1])
+	}
+	return out.Bytes(), nil
+}
+
+// active returns true if all currently open guard blocks evaluate to true for the option
+func active(guards []string, option string) bool {
+	for _, g := range guards {
+		if !evaluateGuard(g, option) {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluateGuard evaluates a guard expression against a single option. "|" binds
+// weaker than "&", and "!" negates a single term.
+func evaluateGuard(expression string, option string) bool {
+	for _, disjunct := range strings.Split(expression, "|") {
+		matches := true
+		for _, term := range strings.Split(disjunct, "&") {
+			term = strings.TrimSpace(term)
+			negate := strings.HasPrefix(term, "!")
+			term = strings.TrimPrefix(term, "!")
+			if (term == option) == negate {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
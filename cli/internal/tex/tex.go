@@ -0,0 +1,209 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tex
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// ClassName is the name of the LaTeX class used by the default template
+	ClassName string = "csassignments"
+	// ClassFile is the filename of the generated LaTeX class
+	ClassFile string = ClassName + ".cls"
+	// SourceFile is the filename of the documented class source
+	SourceFile string = ClassName + ".dtx"
+	// InstallerFile is the filename of the docstrip installer
+	InstallerFile string = ClassName + ".ins"
+)
+
+var (
+	// The class sources are copied from ../tex/latex/csassignments by running
+	// `make tex`, as go:embed cannot reach outside of the module's directory
+
+	//go:embed csassignments/csassignments.dtx
+	source []byte
+	//go:embed csassignments/csassignments.ins
+	installer []byte
+
+	// versionPattern matches the version in \ProvidesClass{csassignments}[<date> <version> <description>]
+	versionPattern = regexp.MustCompile(`\\ProvidesClass\{` + ClassName + `\}\[\s*(\S+)\s+(v?[0-9][^\s\]]*)`)
+
+	// ErrNotInstalled is returned when no installed class could be found
+	ErrNotInstalled error = errors.New("csassignments class is not installed")
+	// ErrNoVersion is returned when a class file does not contain a version in its \ProvidesClass statement
+	ErrNoVersion error = errors.New("failed to find version in \\ProvidesClass statement")
+)
+
+// Source returns the embedded documented class source (.dtx)
+func Source() []byte {
+	return source
+}
+
+// Installer returns the embedded docstrip installer (.ins)
+func Installer() []byte {
+	return installer
+}
+
+// Class generates the class file (.cls) from the embedded class source
+func Class() ([]byte, error) {
+	code, err := Docstrip(source, "cls")
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%%%%\n%%%% This is file `%s', generated by assignmentctl\n%%%% from `%s' (with options: `cls').\n%%%%\n", ClassFile, SourceFile)
+	out.Write(code)
+	fmt.Fprintf(&out, "%%%%\n%%%% End of file `%s'.\n", ClassFile)
+	return out.Bytes(), nil
+}
+
+// Version returns the version of the embedded class, e.g., "v1.0.2"
+func Version() string {
+	v, err := VersionOf(source)
+	if err != nil {
+		// the embedded source is checked in tests, so this should never happen
+		return ""
+	}
+	return v
+}
+
+// VersionOf extracts the class version from the \ProvidesClass statement of
+// either a class file or the documented class source
+func VersionOf(src []byte) (string, error) {
+	m := versionPattern.FindSubmatch(src)
+	if m == nil {
+		return "", ErrNoVersion
+	}
+	return string(m[2]), nil
+}
+
+// Install writes the generated class file as well as the documented source and
+// the installer to dir, creating it if necessary. Existing files are replaced.
+// Returns the paths of all written files.
+func Install(dir string) ([]string, error) {
+	cls, err := Class()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{ClassFile, cls},
+		{SourceFile, source},
+		{InstallerFile, installer},
+	}
+
+	written := []string{}
+	for _, f := range files {
+		p := filepath.Join(dir, f.name)
+		if err := os.WriteFile(p, f.content, 0644); err != nil {
+			return written, err
+		}
+		written = append(written, p)
+	}
+	return written, nil
+}
+
+// TexmfHome returns the user's TEXMFHOME tree. It prefers the environment variable,
+// then asks kpsewhich, and falls back to ~/texmf, which is the default of TeX Live
+func TexmfHome() (string, error) {
+	if d := os.Getenv("TEXMFHOME"); d != "" {
+		return d, nil
+	}
+	if out, err := exec.Command("kpsewhich", "-var-value=TEXMFHOME").Output(); err == nil {
+		if d := strings.TrimSpace(string(out)); d != "" {
+			return d, nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "texmf"), nil
+}
+
+// TexmfHomeDirectory returns the directory inside TEXMFHOME the class is installed to
+func TexmfHomeDirectory() (string, error) {
+	d, err := TexmfHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "tex", "latex", ClassName), nil
+}
+
+// LocalClassPath returns the path of a class installed locally into the repository's root
+func LocalClassPath(root string) string {
+	return filepath.Join(root, ClassFile)
+}
+
+// LocallyInstalled returns true if the class is installed into the repository's root
+func LocallyInstalled(root string) bool {
+	return fileExists(LocalClassPath(root))
+}
+
+// Find looks for an installed class file, first in the repository's root, then
+// using kpsewhich, and finally inside TEXMFHOME. Returns ErrNotInstalled if
+// none of these yield a class file.
+func Find(root string) (string, error) {
+	if LocallyInstalled(root) {
+		return LocalClassPath(root), nil
+	}
+	if out, err := exec.Command("kpsewhich", ClassFile).Output(); err == nil {
+		if p := strings.TrimSpace(string(out)); p != "" {
+			return p, nil
+		}
+	}
+	if d, err := TexmfHomeDirectory(); err == nil {
+		if p := filepath.Join(d, ClassFile); fileExists(p) {
+			return p, nil
+		}
+	}
+	return "", ErrNotInstalled
+}
+
+// InstalledVersion returns the path and version of the installed class
+func InstalledVersion(root string) (string, string, error) {
+	p, err := Find(root)
+	if err != nil {
+		return "", "", err
+	}
+	src, err := os.ReadFile(p)
+	if err != nil {
+		return p, "", err
+	}
+	v, err := VersionOf(src)
+	return p, v, err
+}
+
+func fileExists(p string) bool {
+	fi, err := os.Stat(p)
+	return err == nil && !fi.IsDir()
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tex
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lithammer/dedent"
+)
+
+func TestEmbeddedSourcesAreUpToDate(t *testing.T) {
+	upstream := filepath.Join("..", "..", "..", "tex", "latex", ClassName)
+	for name, embedded := range map[string][]byte{
+		SourceFile:    Source(),
+		InstallerFile: Installer(),
+	} {
+		b, err := os.ReadFile(filepath.Join(upstream, name))
+		if err != nil {
+			t.Skipf("upstream class sources are not available, %v", err)
+		}
+		if !bytes.Equal(b, embedded) {
+			t.Errorf("embedded %s differs from %s, run make tex", name, upstream)
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	if v := Version(); v == "" {
+		t.Fatal("expected embedded class to have a version")
+	}
+	t.Run("class", func(t *testing.T) {
+		v, err := VersionOf([]byte(`\ProvidesClass{csassignments}[2022/07/28 v1.0.2 Assignments Class for CS]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != "v1.0.2" {
+			t.Errorf("expected %s, found %s", "v1.0.2", v)
+		}
+	})
+	t.Run("no version", func(t *testing.T) {
+		_, err := VersionOf([]byte(`\ProvidesClass{csassignments}`))
+		if err != ErrNoVersion {
+			t.Errorf("expected ErrNoVersion, found %v", err)
+		}
+	})
+}
+
+func TestDocstrip(t *testing.T) {
+	dtx := strings.TrimPrefix(dedent.Dedent(`
+		% \iffalse
+		%<*driver>
+		\documentclass{ltxdoc}
+		%</driver>
+		% \fi
+		%    \begin{macrocode}
+		\NeedsTeXFormat{LaTeX2e}
+		%%  meta comment
+		%<cls>\ProvidesClass{example}
+		%<!cls>\ProvidesPackage{example}
+		%<*cls|sty>
+		\newcommand{\foo}{bar}
+		%</cls|sty>
+		%    \end{macrocode}
+	`), "\n")
+	expected := strings.TrimPrefix(dedent.Dedent(`
+		\NeedsTeXFormat{LaTeX2e}
+		%%  meta comment
+		\ProvidesClass{example}
+		\newcommand{\foo}{bar}
+	`), "\n")
+
+	out, err := Docstrip([]byte(dtx), "cls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("expected\n%s\nfound\n%s", expected, out)
+	}
+
+	t.Run("unbalanced guards", func(t *testing.T) {
+		_, err := Docstrip([]byte("%<*cls>\n\\foo\n"), "cls")
+		if err == nil {
+			t.Error("expected error for unclosed guard")
+		}
+	})
+}
+
+func TestInstall(t *testing.T) {
+	dir := t.TempDir()
+	files, err := Install(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Errorf("expected 3 files to be written, found %d", len(files))
+	}
+	cls, err := os.ReadFile(filepath.Join(dir, ClassFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(cls), "ltxdoc") {
+		t.Error("generated class must not contain the documentation driver")
+	}
+	v, err := VersionOf(cls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != Version() {
+		t.Errorf("expected installed version %s, found %s", Version(), v)
+	}
+	p, _, err := InstalledVersion(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != LocalClassPath(dir) {
+		t.Errorf("expected local class at %s to take precedence, found %s", LocalClassPath(dir), p)
+	}
+}
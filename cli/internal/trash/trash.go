@@ -0,0 +1,446 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/deadline"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// Directory is the location of snapshots relative to the repository's root
+	Directory string = ".assignments/trash"
+	// DefaultRetention is the age after which snapshots are deleted
+	DefaultRetention string = "30d"
+	// NoRetention keeps snapshots forever
+	NoRetention string = "none"
+	// OperationRestore is the operation of snapshots of files replaced by restoring
+	// another snapshot
+	OperationRestore string = "restore"
+
+	// manifestFileName is the name of the file describing a snapshot
+	manifestFileName string = "snapshot.yaml"
+	// filesDirectory contains the snapshot's copies of files in their paths
+	// relative to the repository's root
+	filesDirectory string = "files"
+	// timestampLayout prefixes snapshot names, such that they sort by age
+	timestampLayout string = "20060102T150405Z"
+)
+
+var (
+	// ErrNotFound is returned for snapshots that do not exist
+	ErrNotFound error = errors.New("snapshot not found")
+)
+
+// Snapshot is a copy of the files and status of assignments, taken before they are
+// overwritten or deleted
+type Snapshot struct {
+	// Name is the snapshot's directory in the trash, e.g., 20261019T065612Z-rm-05
+	Name string `yaml:"-"`
+	// Operation is the command taking the snapshot, e.g., "rm"
+	Operation string `yaml:"operation"`
+	// Assignment is the ID of the affected assignment, if any
+	Assignment string `yaml:"assignment,omitempty"`
+	// Created is the time the snapshot was taken
+	Created time.Time `yaml:"created"`
+	// Paths are the copied files and directories relative to the repository's root
+	Paths []string `yaml:"paths,omitempty"`
+	// Status contains entries of .status.assignments removed or replaced by the
+	// operation
+	Status map[string]*config.AssignmentStatus `yaml:"status,omitempty"`
+	// Current is .status.assignment before the operation, if it was changed
+	Current *config.AssignmentID `yaml:"current,omitempty"`
+	// Tags maps deleted git tags to the commits they pointed at
+	Tags map[string]string `yaml:"tags,omitempty"`
+}
+
+// empty returns true if there is nothing to restore from the snapshot
+func (s *Snapshot) empty() bool {
+	return len(s.Paths) == 0 && len(s.Status) == 0 && len(s.Tags) == 0
+}
+
+// Store manages the snapshots of a repository
+type Store struct {
+	root string
+	// retention is the maximum age of snapshots, nil keeps them forever
+	retention *time.Duration
+	// keep is the maximum number of snapshots, zero keeps any number
+	keep int
+}
+
+// Open returns the store of the repository at root with the retention policy from
+// the configuration, which may be nil for the defaults
+func Open(root string, opts *config.TrashOptions) (*Store, error) {
+	retention := DefaultRetention
+	keep := 0
+	if opts != nil {
+		if opts.Retention != "" {
+			retention = opts.Retention
+		}
+		if opts.Keep < 0 {
+			return nil, fmt.Errorf("invalid .spec.trash.keep %d, must not be negative", opts.Keep)
+		}
+		keep = opts.Keep
+	}
+	s := &Store{root: root, keep: keep}
+	if strings.TrimSpace(retention) != NoRetention {
+		d, err := deadline.ParseDuration(retention)
+		if err != nil {
+			return nil, fmt.Errorf("invalid .spec.trash.retention, %w", err)
+		}
+		s.retention = &d
+	}
+	return s, nil
+}
+
+// Save copies the paths that exist, relative to the repository's root, into a new
+// snapshot described by snapshot, and prunes snapshots according to the retention
+// policy afterwards. Returns nil if there is nothing to keep
+func (s *Store) Save(snapshot *Snapshot, paths ...string) (*Snapshot, error) {
+	taken, err := s.Take(snapshot, paths...)
+	if err != nil || taken == nil {
+		return taken, err
+	}
+	if _, err := s.Prune(taken.Created); err != nil {
+		return taken, err
+	}
+	return taken, nil
+}
+
+// Take is Save without pruning
+func (s *Store) Take(snapshot *Snapshot, paths ...string) (*Snapshot, error) {
+	if snapshot.Created.IsZero() {
+		snapshot.Created = time.Now()
+	}
+	snapshot.Created = snapshot.Created.UTC()
+	snapshot.Paths = []string{}
+	for _, p := range paths {
+		rel, err := s.relative(p)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Lstat(filepath.Join(s.root, rel)); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		snapshot.Paths = append(snapshot.Paths, filepath.ToSlash(rel))
+	}
+	if snapshot.empty() {
+		return nil, nil
+	}
+
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+	name, err := s.name(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Name = name
+	dir := filepath.Join(s.root, Directory, name)
+	if err := os.Mkdir(dir, 0777); err != nil {
+		return nil, err
+	}
+	for _, p := range snapshot.Paths {
+		if err := copyTree(filepath.Join(s.root, p), filepath.Join(dir, filesDirectory, p)); err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, fmt.Errorf("failed to copy %s into snapshot, %w", p, err)
+		}
+	}
+	manifest, err := yaml.Marshal(snapshot)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, manifestFileName), manifest, 0644); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// List returns all snapshots, newest first
+func (s *Store) List() ([]*Snapshot, error) {
+	entries, err := os.ReadDir(filepath.Join(s.root, Directory))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []*Snapshot{}, nil
+		}
+		return nil, err
+	}
+	snapshots := []*Snapshot{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		snapshot, err := s.Get(e.Name())
+		if err != nil {
+			// directories without manifest are not snapshots
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].Created.Equal(snapshots[j].Created) {
+			return snapshots[i].Name > snapshots[j].Name
+		}
+		return snapshots[i].Created.After(snapshots[j].Created)
+	})
+	return snapshots, nil
+}
+
+// Get reads the snapshot with the given name
+func (s *Store) Get(name string) (*Snapshot, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	manifest, err := os.ReadFile(filepath.Join(s.root, Directory, name, manifestFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %q", ErrNotFound, name)
+		}
+		return nil, err
+	}
+	snapshot := &Snapshot{}
+	if err := yaml.Unmarshal(manifest, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s, %w", name, err)
+	}
+	snapshot.Name = name
+	return snapshot, nil
+}
+
+// Latest returns the newest snapshot, or ErrNotFound if there is none
+func (s *Store) Latest() (*Snapshot, error) {
+	snapshots, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("%w: the trash is empty", ErrNotFound)
+	}
+	return snapshots[0], nil
+}
+
+// Delete removes a snapshot from the store
+func (s *Store) Delete(name string) error {
+	if _, err := s.Get(name); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(s.root, Directory, name))
+}
+
+// Prune deletes all snapshots older than the retention period at now, and the
+// oldest snapshots exceeding the maximum number of snapshots. Returns the names
+// of the deleted snapshots
+func (s *Store) Prune(now time.Time) ([]string, error) {
+	snapshots, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	pruned := []string{}
+	for i, snapshot := range snapshots {
+		expired := s.retention != nil && now.Sub(snapshot.Created) > *s.retention
+		if !expired && (s.keep == 0 || i < s.keep) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(s.root, Directory, snapshot.Name)); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, snapshot.Name)
+	}
+	return pruned, nil
+}
+
+// Restore copies the snapshot's files back into the repository and its status
+// entries into status, which may be nil. Files and status entries that exist in
+// the meantime are saved to a new snapshot first, which is returned, such that
+// restoring can be undone as well. The restored snapshot is deleted afterwards.
+// Git tags are not restored, see Snapshot.Tags
+func (s *Store) Restore(snapshot *Snapshot, status *config.ConfigurationStatus) (*Snapshot, error) {
+	replaced := &Snapshot{
+		Operation:  OperationRestore,
+		Assignment: snapshot.Assignment,
+		Status:     map[string]*config.AssignmentStatus{},
+	}
+	for id := range snapshot.Status {
+		if a := status.Lookup(id); a != nil {
+			replaced.Status[id] = a.Clone()
+		}
+	}
+	if snapshot.Current != nil && status != nil {
+		current := status.Assignment
+		replaced.Current = &current
+	}
+	backup, err := s.Take(replaced, snapshot.Paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(s.root, Directory, snapshot.Name, filesDirectory)
+	for _, p := range snapshot.Paths {
+		target := filepath.Join(s.root, filepath.FromSlash(p))
+		if err := os.RemoveAll(target); err != nil {
+			return backup, err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+			return backup, err
+		}
+		if err := copyTree(filepath.Join(dir, filepath.FromSlash(p)), target); err != nil {
+			return backup, fmt.Errorf("failed to restore %s, %w", p, err)
+		}
+	}
+
+	if status != nil {
+		for id, a := range snapshot.Status {
+			*status.Upsert(id) = *a.Clone()
+		}
+		if snapshot.Current != nil {
+			status.Assignment = *snapshot.Current
+		}
+	}
+
+	if err := os.RemoveAll(filepath.Join(s.root, Directory, snapshot.Name)); err != nil {
+		return backup, err
+	}
+	return backup, nil
+}
+
+// init creates the trash directory, which ignores itself in git
+func (s *Store) init() error {
+	dir := filepath.Join(s.root, Directory)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	gitignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(gitignore); err == nil {
+		return nil
+	}
+	return os.WriteFile(gitignore, []byte("*\n"), 0644)
+}
+
+// name returns an unused name for the snapshot from its creation time, operation,
+// and assignment
+func (s *Store) name(snapshot *Snapshot) (string, error) {
+	base := snapshot.Created.Format(timestampLayout) + "-" + snapshot.Operation
+	if snapshot.Assignment != "" {
+		base += "-" + snapshot.Assignment
+	}
+	name := base
+	for i := 2; ; i++ {
+		_, err := os.Stat(filepath.Join(s.root, Directory, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			return name, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// relative returns path relative to the repository's root. Paths outside of the
+// repository and inside the trash are rejected
+func (s *Store) relative(path string) (string, error) {
+	rel := path
+	if filepath.IsAbs(path) {
+		r, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return "", err
+		}
+		rel = r
+	}
+	rel = filepath.Clean(rel)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not inside the repository", path)
+	}
+	if rel == filepath.FromSlash(Directory) || strings.HasPrefix(rel, filepath.FromSlash(Directory)+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is inside the trash", path)
+	}
+	return rel, nil
+}
+
+// copyTree copies the file, symlink, or directory at src to dst, keeping modes and
+// modification times, such that restored PDFs are not considered stale
+func copyTree(src string, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := os.Lstat(path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.IsDir():
+			if err := os.MkdirAll(target, info.Mode().Perm()|0700); err != nil {
+				return err
+			}
+		default:
+			if err := copyFile(path, target, info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+		return os.Chtimes(target, info.ModTime(), info.ModTime())
+	})
+}
+
+func copyFile(src string, dst string, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0777); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
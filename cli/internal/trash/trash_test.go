@@ -0,0 +1,154 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trash
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zoomoid/assignments/v1/internal/config"
+)
+
+func write(t *testing.T, path string, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestSaveThenRestore(t *testing.T) {
+	root := t.TempDir()
+	write(t, filepath.Join(root, "assignment-05", "assignment.tex"), "half-finished")
+	write(t, filepath.Join(root, "assignment-05", "code", "main.py"), "print()")
+
+	store, err := Open(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := &config.ConfigurationStatus{}
+	status.Upsert("05").Mark(config.StateInProgress, time.Now())
+
+	snapshot, err := store.Save(&Snapshot{
+		Operation:  "generate",
+		Assignment: "05",
+		Status:     map[string]*config.AssignmentStatus{"05": status.Lookup("05").Clone()},
+	}, "assignment-05", filepath.Join(root, "dist", "assignment-05.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Paths) != 1 || snapshot.Paths[0] != "assignment-05" {
+		t.Errorf("expected only existing paths in snapshot, found %v", snapshot.Paths)
+	}
+	if _, err := os.Stat(filepath.Join(root, Directory, ".gitignore")); err != nil {
+		t.Error("expected trash to ignore itself")
+	}
+
+	// the operation replaces the directory and the status
+	if err := os.RemoveAll(filepath.Join(root, "assignment-05")); err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(root, "assignment-05", "assignment.tex"), "fresh")
+	status.Upsert("05").Mark(config.StateGenerated, time.Now())
+
+	latest, err := store.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Name != snapshot.Name || latest.Status["05"].State != config.StateInProgress {
+		t.Errorf("expected latest snapshot %s with status, found %+v", snapshot.Name, latest)
+	}
+	backup, err := store.Restore(latest, status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := read(t, filepath.Join(root, "assignment-05", "assignment.tex")); s != "half-finished" {
+		t.Errorf("expected restored source, found %q", s)
+	}
+	if s := read(t, filepath.Join(root, "assignment-05", "code", "main.py")); s != "print()" {
+		t.Errorf("expected restored subdirectory, found %q", s)
+	}
+	if status.Lookup("05").State != config.StateInProgress {
+		t.Errorf("expected restored status, found %s", status.Lookup("05").State)
+	}
+	if _, err := store.Get(snapshot.Name); !errors.Is(err, ErrNotFound) {
+		t.Error("expected restored snapshot to be removed")
+	}
+
+	// restoring the backup redoes the operation
+	if backup == nil || backup.Operation != OperationRestore {
+		t.Fatalf("expected backup of replaced files, found %+v", backup)
+	}
+	if _, err := store.Restore(backup, status); err != nil {
+		t.Fatal(err)
+	}
+	if s := read(t, filepath.Join(root, "assignment-05", "assignment.tex")); s != "fresh" {
+		t.Errorf("expected redone source, found %q", s)
+	}
+	if status.Lookup("05").State != config.StateGenerated {
+		t.Errorf("expected redone status, found %s", status.Lookup("05").State)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	root := t.TempDir()
+	write(t, filepath.Join(root, "dist", "assignment-01.zip"), "zip")
+
+	store, err := Open(root, &config.TrashOptions{Retention: "7d", Keep: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	for _, age := range []time.Duration{10 * 24 * time.Hour, 3 * time.Hour, 2 * time.Hour, time.Hour} {
+		if _, err := store.Take(&Snapshot{Operation: "bundle", Created: now.Add(-age)}, filepath.Join("dist", "assignment-01.zip")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pruned, err := store.Prune(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 2 {
+		t.Errorf("expected expired and excess snapshots to be pruned, found %v", pruned)
+	}
+	snapshots, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || !snapshots[0].Created.Equal(now.Add(-time.Hour)) {
+		t.Errorf("expected the 2 newest snapshots to remain, found %d", len(snapshots))
+	}
+
+	if _, err := Open(root, &config.TrashOptions{Retention: "soon"}); err == nil {
+		t.Error("expected error for invalid retention")
+	}
+	if _, err := store.Save(&Snapshot{Operation: "clean"}, filepath.Join(root, "..", "outside")); err == nil {
+		t.Error("expected error for paths outside the repository")
+	}
+}
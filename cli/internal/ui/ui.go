@@ -0,0 +1,402 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/deadline"
+	"github.com/zoomoid/assignments/v1/internal/inventory"
+)
+
+// Actions are the operations the dashboard runs on behalf of the user. They are
+// implemented by the respective commands, so that the dashboard behaves exactly
+// like the CLI. Actions run in the background on a copy of the dashboard's
+// context, and must only change the configuration of the context passed to them
+type Actions struct {
+	// Generate generates the next assignment with the given due date, which may be
+	// nil, and returns the path of its main source file
+	Generate func(ctx *context.AppContext, due *time.Time) (string, error)
+	// Build builds the assignment, writing the output of the build recipe to out
+	Build func(ctx *context.AppContext, a *inventory.Assignment, out io.Writer) error
+	// Bundle bundles the assignment and returns the archive's name
+	Bundle func(ctx *context.AppContext, a *inventory.Assignment) (string, error)
+}
+
+type mode int
+
+const (
+	modeList mode = iota
+	modeDue
+	modeLog
+)
+
+type tickMsg time.Time
+
+type scannedMsg struct {
+	assignments []*inventory.Assignment
+	err         error
+}
+
+type doneMsg struct {
+	message string
+	err     error
+	// configuration is the configuration as changed by the action
+	configuration *config.Configuration
+}
+
+// Model is the dashboard's state. Actions run one at a time in the background on a
+// copy of the context. Their changes to the configuration are taken over and
+// written after each action, such that the configuration is only ever accessed
+// from the dashboard's Update
+type Model struct {
+	ctx     *context.AppContext
+	actions Actions
+	parser  *deadline.Parser
+
+	assignments []*inventory.Assignment
+	cursor      int
+	now         time.Time
+	width       int
+	height      int
+
+	mode  mode
+	input string
+
+	// busy describes the running action, if any
+	busy    string
+	message string
+	err     error
+
+	// logs holds the output of the last build of each assignment, keyed by ID
+	logs   map[string]*Buffer
+	events *Buffer
+}
+
+// New creates the dashboard's model. The context's configuration must be read before
+func New(ctx *context.AppContext, parser *deadline.Parser, actions Actions) *Model {
+	return &Model{
+		ctx:     ctx,
+		actions: actions,
+		parser:  parser,
+		now:     time.Now(),
+		logs:    map[string]*Buffer{},
+		events:  &Buffer{},
+	}
+}
+
+// Run shows the dashboard full-screen until the user quits. While it runs, log
+// messages are written to the dashboard's status line instead of stderr
+func Run(m *Model) error {
+	logger := log.Logger
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: m.events, NoColor: true, PartsExclude: []string{zerolog.TimestampFieldName}})
+	defer func() {
+		log.Logger = logger
+	}()
+
+	return tea.NewProgram(m, tea.WithAltScreen()).Start()
+}
+
+// Init implements tea.Model
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(m.scan(), tick())
+}
+
+// Update implements tea.Model
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+	case tickMsg:
+		m.now = time.Time(msg)
+		return m, tick()
+	case scannedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.assignments = msg.assignments
+		if m.cursor >= len(m.assignments) {
+			m.cursor = len(m.assignments) - 1
+		}
+		if m.cursor < 0 {
+			m.cursor = 0
+		}
+		return m, nil
+	case doneMsg:
+		m.busy = ""
+		m.message, m.err = msg.message, msg.err
+		if msg.configuration != nil {
+			m.ctx.Configuration = msg.configuration
+			if err := m.ctx.Write(); err != nil && m.err == nil {
+				m.err = fmt.Errorf("failed to write configuration, %w", err)
+			}
+		}
+		return m, m.scan()
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+		switch m.mode {
+		case modeDue:
+			return m.updateDue(msg)
+		case modeLog:
+			return m.updateLog(msg)
+		default:
+			return m.updateList(msg)
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc":
+		if m.busy != "" {
+			m.message = fmt.Sprintf("%s, wait for it to finish or press ctrl+c to abort", m.busy)
+			return m, nil
+		}
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.assignments)-1 {
+			m.cursor++
+		}
+	case "r":
+		if m.busy != "" {
+			m.message = fmt.Sprintf("%s, wait for it to finish before refreshing", m.busy)
+			return m, nil
+		}
+		m.err = nil
+		return m, m.scan()
+	case "l":
+		if a := m.selected(); a != nil {
+			m.mode = modeLog
+		}
+	case "n":
+		if m.busy == "" {
+			m.mode = modeDue
+			m.input = ""
+			// pre-fill due dates imported from a calendar or recorded previously
+			if status := m.ctx.Configuration.Status.Lookup(m.ctx.Naming().ID(m.next())); status != nil && status.Due != nil {
+				m.input = status.Due.In(m.parser.Location).Format("2006-01-02 15:04")
+			}
+		}
+	case "b":
+		if a := m.selected(); a != nil && m.busy == "" {
+			if a.Directory == "" {
+				m.err = fmt.Errorf("assignment %s has no source directory", a.ID)
+				return m, nil
+			}
+			return m, m.build(a)
+		}
+	case "z":
+		if a := m.selected(); a != nil && m.busy == "" {
+			if a.PDF == "" {
+				m.err = fmt.Errorf("assignment %s is not built yet", a.ID)
+				return m, nil
+			}
+			return m, m.bundle(a)
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) updateDue(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.mode = modeList
+	case tea.KeyEnter:
+		var due *time.Time
+		if strings.TrimSpace(m.input) != "" {
+			m.parser.Now = time.Now()
+			t, err := m.parser.Parse(m.input)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			due = &t
+		}
+		m.mode = modeList
+		return m, m.generate(due)
+	case tea.KeyBackspace:
+		if r := []rune(m.input); len(r) > 0 {
+			m.input = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.input += string(msg.Runes)
+	}
+	return m, nil
+}
+
+func (m *Model) updateLog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc", "l":
+		m.mode = modeList
+	}
+	return m, nil
+}
+
+// selected returns the assignment under the cursor, or nil if there are none
+func (m *Model) selected() *inventory.Assignment {
+	if m.cursor < 0 || m.cursor >= len(m.assignments) {
+		return nil
+	}
+	return m.assignments[m.cursor]
+}
+
+// next returns the ID of the assignment generated next, or the zero ID if the
+// current assignment is a named one, in which case generating fails
+func (m *Model) next() config.AssignmentID {
+	next, _ := m.ctx.Configuration.Status.Assignment.Next()
+	return next
+}
+
+// scan scans the repository in the background on a copy of the context
+func (m *Model) scan() tea.Cmd {
+	ctx := m.fork()
+	return func() tea.Msg {
+		assignments, err := inventory.Scan(ctx)
+		return scannedMsg{assignments: assignments, err: err}
+	}
+}
+
+func (m *Model) generate(due *time.Time) tea.Cmd {
+	m.busy = fmt.Sprintf("Generating assignment %s", m.ctx.Naming().ID(m.next()))
+	m.err = nil
+	return m.run(func(ctx *context.AppContext) (string, error) {
+		file, err := m.actions.Generate(ctx, due)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Generated assignment at %s", file), nil
+	})
+}
+
+func (m *Model) build(a *inventory.Assignment) tea.Cmd {
+	m.busy = fmt.Sprintf("Building assignment %s", a.ID)
+	m.err = nil
+	out := &Buffer{}
+	m.logs[a.ID] = out
+	return m.run(func(ctx *context.AppContext) (string, error) {
+		if err := m.actions.Build(ctx, a, out); err != nil {
+			return "", fmt.Errorf("failed to build assignment %s, press l for the build log, %w", a.ID, err)
+		}
+		return fmt.Sprintf("Built assignment %s", a.ID), nil
+	})
+}
+
+func (m *Model) bundle(a *inventory.Assignment) tea.Cmd {
+	m.busy = fmt.Sprintf("Bundling assignment %s", a.ID)
+	m.err = nil
+	return m.run(func(ctx *context.AppContext) (string, error) {
+		archive, err := m.actions.Bundle(ctx, a)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Bundled assignment %s to %s in ./dist/", a.ID, archive), nil
+	})
+}
+
+// run runs an action in the background on a copy of the context. The copy's
+// configuration, in which actions record due dates and lifecycle transitions, is
+// taken over when the action is done
+func (m *Model) run(action func(ctx *context.AppContext) (string, error)) tea.Cmd {
+	ctx := m.fork()
+	return func() tea.Msg {
+		message, err := action(ctx)
+		return doneMsg{message: message, err: err, configuration: ctx.Configuration}
+	}
+}
+
+// fork copies the context for use in the background
+func (m *Model) fork() *context.AppContext {
+	ctx := m.ctx.Clone()
+	ctx.Verbose = m.ctx.Verbose
+	return ctx
+}
+
+// buildLog returns the output of the assignment's last build in this session, or
+// otherwise the LaTeX log in its directory, if it was kept
+func (m *Model) buildLog(a *inventory.Assignment) string {
+	if b, ok := m.logs[a.ID]; ok {
+		return b.String()
+	}
+	if a.Directory == "" {
+		return ""
+	}
+	b, err := os.ReadFile(filepath.Join(m.ctx.Root, a.Directory, "assignment.log"))
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// Buffer is a bytes.Buffer safe for concurrent use, such that the dashboard can show
+// the output of a build while it is still running
+type Buffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write implements io.Writer
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// String returns the buffer's contents
+func (b *Buffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// Tail returns at most the last n lines of s
+func Tail(s string, n int) []string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return []string{}
+	}
+	if n >= 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
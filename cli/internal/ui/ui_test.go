@@ -0,0 +1,148 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zoomoid/assignments/v1/internal/config"
+	"github.com/zoomoid/assignments/v1/internal/context"
+	"github.com/zoomoid/assignments/v1/internal/deadline"
+	"github.com/zoomoid/assignments/v1/internal/inventory"
+)
+
+func keys(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// send updates the model with msg and runs all resulting commands synchronously,
+// except for ticks
+func send(m *Model, msg tea.Msg) {
+	_, cmd := m.Update(msg)
+	for cmd != nil {
+		next := cmd()
+		if _, ok := next.(tickMsg); ok {
+			return
+		}
+		_, cmd = m.Update(next)
+	}
+}
+
+func TestModel(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"assignment-01", "assignment-02"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := &context.AppContext{
+		Root:          root,
+		Cwd:           root,
+		Configuration: config.Minimal(),
+	}
+	ctx.Configuration.Status.Assignment = config.NumberedID(2)
+
+	generated := []*time.Time{}
+	built := []string{}
+	actions := Actions{
+		Generate: func(ctx *context.AppContext, due *time.Time) (string, error) {
+			generated = append(generated, due)
+			ctx.Configuration.Status.Assignment = config.NumberedID(3)
+			return "assignment-03/assignment.tex", nil
+		},
+		Build: func(ctx *context.AppContext, a *inventory.Assignment, out io.Writer) error {
+			built = append(built, a.ID)
+			fmt.Fprintln(out, "Latexmk: All targets are up-to-date")
+			return nil
+		},
+		Bundle: func(ctx *context.AppContext, a *inventory.Assignment) (string, error) {
+			return "", fmt.Errorf("not implemented")
+		},
+	}
+	parser, err := deadline.NewParser("UTC", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(ctx, parser, actions)
+	send(m, m.scan()())
+
+	if len(m.assignments) != 2 {
+		t.Fatalf("expected 2 assignments, found %d", len(m.assignments))
+	}
+
+	send(m, keys("j"))
+	send(m, keys("j"))
+	if a := m.selected(); a == nil || a.ID != "02" {
+		t.Fatalf("expected cursor to stop at assignment 02, found %+v", a)
+	}
+
+	send(m, keys("b"))
+	if len(built) != 1 || built[0] != "02" {
+		t.Errorf("expected assignment 02 to be built, found %v", built)
+	}
+	if m.busy != "" || m.err != nil {
+		t.Errorf("expected build to finish without error, found %q, %v", m.busy, m.err)
+	}
+	send(m, keys("l"))
+	if m.mode != modeLog || !strings.Contains(m.View(), "All targets are up-to-date") {
+		t.Errorf("expected build log to be shown, found\n%s", m.View())
+	}
+	send(m, tea.KeyMsg{Type: tea.KeyEsc})
+
+	send(m, keys("n"))
+	if m.mode != modeDue {
+		t.Fatal("expected prompt for due date")
+	}
+	send(m, keys("2026-10-23"))
+	send(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(generated) != 1 || generated[0] == nil {
+		t.Fatalf("expected assignment to be generated with due date, found %v", generated)
+	}
+	if expected := time.Date(2026, time.October, 23, 23, 59, 0, 0, time.UTC); !generated[0].Equal(expected) {
+		t.Errorf("expected due date %v, found %v", expected, generated[0])
+	}
+	if current := m.ctx.Configuration.Status.Assignment; current != config.NumberedID(3) {
+		t.Errorf("expected the action's configuration to be taken over, found current assignment %s", current)
+	}
+
+	m.busy = "Building assignment 02"
+	if _, cmd := m.Update(keys("r")); cmd != nil || !strings.Contains(m.message, "wait") {
+		t.Errorf("expected refreshing to be refused while an action runs, found %q", m.message)
+	}
+	m.busy = ""
+
+	send(m, keys("z"))
+	if m.err == nil {
+		t.Error("expected error for bundling an assignment without PDF")
+	}
+}
+
+func TestTail(t *testing.T) {
+	if lines := Tail("", 3); len(lines) != 0 {
+		t.Errorf("expected no lines, found %v", lines)
+	}
+	if lines := Tail("a\nb\nc\nd\n", 2); strings.Join(lines, ",") != "c,d" {
+		t.Errorf("expected last 2 lines, found %v", lines)
+	}
+}
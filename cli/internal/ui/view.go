@@ -0,0 +1,216 @@
+/*
+Copyright 2022 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zoomoid/assignments/v1/internal/deadline"
+	"github.com/zoomoid/assignments/v1/internal/inventory"
+)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Padding(0, 1)
+	headerStyle   = lipgloss.NewStyle().Bold(true).Faint(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	helpStyle     = lipgloss.NewStyle().Faint(true)
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	successStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	warningStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+
+	columns = []string{"ASSIGNMENT", "STATE", "DUE", "BUILD", "BUNDLE", "RELEASE"}
+
+	// dueSoon is the time before a due date from which it is highlighted
+	dueSoon = 48 * time.Hour
+)
+
+// View implements tea.Model
+func (m *Model) View() string {
+	b := &strings.Builder{}
+	title := m.ctx.Configuration.Spec.Course
+	if title == "" {
+		title = "assignmentctl"
+	}
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n\n")
+
+	switch m.mode {
+	case modeLog:
+		m.viewLog(b)
+	default:
+		m.viewTable(b)
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.viewStatus())
+	b.WriteString("\n")
+	b.WriteString(m.viewHelp())
+	return b.String()
+}
+
+func (m *Model) viewTable(b *strings.Builder) {
+	if len(m.assignments) == 0 {
+		b.WriteString(helpStyle.Render("No assignments yet, press n to generate the first one"))
+		b.WriteString("\n")
+		return
+	}
+
+	rows := make([][]string, len(m.assignments))
+	widths := make([]int, len(columns))
+	for i, c := range columns {
+		widths[i] = len(c)
+	}
+	for i, a := range m.assignments {
+		rows[i] = m.row(a)
+		for j, cell := range rows[i] {
+			if w := lipgloss.Width(cell); w > widths[j] {
+				widths[j] = w
+			}
+		}
+	}
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = lipgloss.NewStyle().Width(widths[i] + 3).Render(c)
+	}
+	b.WriteString(headerStyle.Render(strings.Join(header, "")))
+	b.WriteString("\n")
+
+	for i, a := range m.assignments {
+		cells := make([]string, len(columns))
+		for j, cell := range rows[i] {
+			style := lipgloss.NewStyle().Width(widths[j] + 3)
+			if i != m.cursor {
+				style = style.Inherit(m.cellStyle(a, j))
+			}
+			cells[j] = style.Render(cell)
+		}
+		line := strings.Join(cells, "")
+		if i == m.cursor {
+			line = selectedStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
+
+// row formats the columns of an assignment's row
+func (m *Model) row(a *inventory.Assignment) []string {
+	state := "-"
+	if a.State != "" {
+		state = string(a.State)
+	}
+	due := "-"
+	if a.Due != nil {
+		due = fmt.Sprintf("%s (%s)", a.Due.In(m.parser.Location).Format("Mon 2006-01-02 15:04"), deadline.Humanize(*a.Due, m.now))
+	}
+	bundled := "-"
+	if a.Bundled() {
+		names := []string{}
+		for _, archive := range a.Archives {
+			names = append(names, strings.TrimPrefix(archive, "dist/"))
+		}
+		bundled = strings.Join(names, ", ")
+	}
+	release := "-"
+	if a.Released() {
+		release = a.Tag
+	}
+	return []string{a.ID, state, due, string(a.Build), bundled, release}
+}
+
+// cellStyle highlights due dates that passed or are close, and builds that are
+// missing or stale
+func (m *Model) cellStyle(a *inventory.Assignment, column int) lipgloss.Style {
+	switch columns[column] {
+	case "DUE":
+		if a.Due == nil || a.State.Manual() {
+			break
+		}
+		if left := a.Due.Sub(m.now); left < 0 {
+			return errorStyle
+		} else if left < dueSoon {
+			return warningStyle
+		}
+	case "BUILD":
+		switch a.Build {
+		case inventory.BuildStatusFresh:
+			return successStyle
+		case inventory.BuildStatusStale:
+			return warningStyle
+		}
+	}
+	return lipgloss.NewStyle()
+}
+
+func (m *Model) viewLog(b *strings.Builder) {
+	a := m.selected()
+	if a == nil {
+		return
+	}
+	b.WriteString(headerStyle.Render(fmt.Sprintf("Build log of assignment %s", a.ID)))
+	b.WriteString("\n")
+
+	// leave room for the title, the header, the status line, and the help
+	n := m.height - 7
+	if n < 1 {
+		n = 20
+	}
+	lines := Tail(m.buildLog(a), n)
+	if len(lines) == 0 {
+		b.WriteString(helpStyle.Render("No build log yet, press b to build the assignment"))
+		b.WriteString("\n")
+		return
+	}
+	for _, line := range lines {
+		if r := []rune(line); m.width > 0 && len(r) > m.width {
+			line = string(r[:m.width])
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
+
+func (m *Model) viewStatus() string {
+	switch {
+	case m.mode == modeDue:
+		return fmt.Sprintf("Due date of assignment %s (empty for none): %s█", m.ctx.Naming().ID(m.next()), m.input)
+	case m.busy != "":
+		return warningStyle.Render(m.busy + strings.Repeat(".", m.now.Second()%3+1))
+	case m.err != nil:
+		return errorStyle.Render(m.err.Error())
+	case m.message != "":
+		return successStyle.Render(m.message)
+	}
+	if events := Tail(m.events.String(), 1); len(events) > 0 {
+		return helpStyle.Render(events[0])
+	}
+	return ""
+}
+
+func (m *Model) viewHelp() string {
+	switch m.mode {
+	case modeDue:
+		return helpStyle.Render("enter generate • esc cancel")
+	case modeLog:
+		return helpStyle.Render("l/esc back")
+	}
+	return helpStyle.Render("↑/↓ select • n new sheet • b build • z bundle • l build log • r refresh • q quit")
+}